@@ -0,0 +1,1417 @@
+// Copyright (c) 2025. Marlin Kuhn
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: gopdfattach/v1/gopdfattach.proto
+
+package gopdfattachv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FileType int32
+
+const (
+	FileType_FILE_TYPE_UNSPECIFIED FileType = 0
+	FileType_FILE_TYPE_FACTURX     FileType = 1
+	FileType_FILE_TYPE_ZUGFERD     FileType = 2
+)
+
+// Enum value maps for FileType.
+var (
+	FileType_name = map[int32]string{
+		0: "FILE_TYPE_UNSPECIFIED",
+		1: "FILE_TYPE_FACTURX",
+		2: "FILE_TYPE_ZUGFERD",
+	}
+	FileType_value = map[string]int32{
+		"FILE_TYPE_UNSPECIFIED": 0,
+		"FILE_TYPE_FACTURX":     1,
+		"FILE_TYPE_ZUGFERD":     2,
+	}
+)
+
+func (x FileType) Enum() *FileType {
+	p := new(FileType)
+	*p = x
+	return p
+}
+
+func (x FileType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileType) Descriptor() protoreflect.EnumDescriptor {
+	return file_gopdfattach_v1_gopdfattach_proto_enumTypes[0].Descriptor()
+}
+
+func (FileType) Type() protoreflect.EnumType {
+	return &file_gopdfattach_v1_gopdfattach_proto_enumTypes[0]
+}
+
+func (x FileType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileType.Descriptor instead.
+func (FileType) EnumDescriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{0}
+}
+
+// AttachConfig mirrors gopdfattach.AttachConfig. Empty fields are replaced by defaults.
+type AttachConfig struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DocumentType     string                 `protobuf:"bytes,1,opt,name=document_type,json=documentType,proto3" json:"document_type,omitempty"`
+	FileName         string                 `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Version          string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	ConformanceLevel string                 `protobuf:"bytes,4,opt,name=conformance_level,json=conformanceLevel,proto3" json:"conformance_level,omitempty"`
+	Creator          string                 `protobuf:"bytes,5,opt,name=creator,proto3" json:"creator,omitempty"`
+	AfRelationship   string                 `protobuf:"bytes,6,opt,name=af_relationship,json=afRelationship,proto3" json:"af_relationship,omitempty"`
+	Incremental      bool                   `protobuf:"varint,7,opt,name=incremental,proto3" json:"incremental,omitempty"`
+	UserPassword     string                 `protobuf:"bytes,8,opt,name=user_password,json=userPassword,proto3" json:"user_password,omitempty"`
+	OwnerPassword    string                 `protobuf:"bytes,9,opt,name=owner_password,json=ownerPassword,proto3" json:"owner_password,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AttachConfig) Reset() {
+	*x = AttachConfig{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachConfig) ProtoMessage() {}
+
+func (x *AttachConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachConfig.ProtoReflect.Descriptor instead.
+func (*AttachConfig) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AttachConfig) GetDocumentType() string {
+	if x != nil {
+		return x.DocumentType
+	}
+	return ""
+}
+
+func (x *AttachConfig) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *AttachConfig) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *AttachConfig) GetConformanceLevel() string {
+	if x != nil {
+		return x.ConformanceLevel
+	}
+	return ""
+}
+
+func (x *AttachConfig) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *AttachConfig) GetAfRelationship() string {
+	if x != nil {
+		return x.AfRelationship
+	}
+	return ""
+}
+
+func (x *AttachConfig) GetIncremental() bool {
+	if x != nil {
+		return x.Incremental
+	}
+	return false
+}
+
+func (x *AttachConfig) GetUserPassword() string {
+	if x != nil {
+		return x.UserPassword
+	}
+	return ""
+}
+
+func (x *AttachConfig) GetOwnerPassword() string {
+	if x != nil {
+		return x.OwnerPassword
+	}
+	return ""
+}
+
+type AttachRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileType      FileType               `protobuf:"varint,1,opt,name=file_type,json=fileType,proto3,enum=gopdfattach.v1.FileType" json:"file_type,omitempty"`
+	Pdf           []byte                 `protobuf:"bytes,2,opt,name=pdf,proto3" json:"pdf,omitempty"`
+	Xml           []byte                 `protobuf:"bytes,3,opt,name=xml,proto3" json:"xml,omitempty"`
+	Config        *AttachConfig          `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachRequest) Reset() {
+	*x = AttachRequest{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachRequest) ProtoMessage() {}
+
+func (x *AttachRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachRequest.ProtoReflect.Descriptor instead.
+func (*AttachRequest) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AttachRequest) GetFileType() FileType {
+	if x != nil {
+		return x.FileType
+	}
+	return FileType_FILE_TYPE_UNSPECIFIED
+}
+
+func (x *AttachRequest) GetPdf() []byte {
+	if x != nil {
+		return x.Pdf
+	}
+	return nil
+}
+
+func (x *AttachRequest) GetXml() []byte {
+	if x != nil {
+		return x.Xml
+	}
+	return nil
+}
+
+func (x *AttachRequest) GetConfig() *AttachConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type AttachMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileType      FileType               `protobuf:"varint,1,opt,name=file_type,json=fileType,proto3,enum=gopdfattach.v1.FileType" json:"file_type,omitempty"`
+	Config        *AttachConfig          `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachMetadata) Reset() {
+	*x = AttachMetadata{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachMetadata) ProtoMessage() {}
+
+func (x *AttachMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachMetadata.ProtoReflect.Descriptor instead.
+func (*AttachMetadata) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AttachMetadata) GetFileType() FileType {
+	if x != nil {
+		return x.FileType
+	}
+	return FileType_FILE_TYPE_UNSPECIFIED
+}
+
+func (x *AttachMetadata) GetConfig() *AttachConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type AttachStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*AttachStreamRequest_Metadata
+	//	*AttachStreamRequest_PdfChunk
+	//	*AttachStreamRequest_XmlChunk
+	Payload       isAttachStreamRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachStreamRequest) Reset() {
+	*x = AttachStreamRequest{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachStreamRequest) ProtoMessage() {}
+
+func (x *AttachStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachStreamRequest.ProtoReflect.Descriptor instead.
+func (*AttachStreamRequest) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AttachStreamRequest) GetPayload() isAttachStreamRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *AttachStreamRequest) GetMetadata() *AttachMetadata {
+	if x != nil {
+		if x, ok := x.Payload.(*AttachStreamRequest_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *AttachStreamRequest) GetPdfChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*AttachStreamRequest_PdfChunk); ok {
+			return x.PdfChunk
+		}
+	}
+	return nil
+}
+
+func (x *AttachStreamRequest) GetXmlChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*AttachStreamRequest_XmlChunk); ok {
+			return x.XmlChunk
+		}
+	}
+	return nil
+}
+
+type isAttachStreamRequest_Payload interface {
+	isAttachStreamRequest_Payload()
+}
+
+type AttachStreamRequest_Metadata struct {
+	Metadata *AttachMetadata `protobuf:"bytes,1,opt,name=metadata,proto3,oneof"`
+}
+
+type AttachStreamRequest_PdfChunk struct {
+	PdfChunk []byte `protobuf:"bytes,2,opt,name=pdf_chunk,json=pdfChunk,proto3,oneof"`
+}
+
+type AttachStreamRequest_XmlChunk struct {
+	XmlChunk []byte `protobuf:"bytes,3,opt,name=xml_chunk,json=xmlChunk,proto3,oneof"`
+}
+
+func (*AttachStreamRequest_Metadata) isAttachStreamRequest_Payload() {}
+
+func (*AttachStreamRequest_PdfChunk) isAttachStreamRequest_Payload() {}
+
+func (*AttachStreamRequest_XmlChunk) isAttachStreamRequest_Payload() {}
+
+type AttachResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pdf           []byte                 `protobuf:"bytes,1,opt,name=pdf,proto3" json:"pdf,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachResponse) Reset() {
+	*x = AttachResponse{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachResponse) ProtoMessage() {}
+
+func (x *AttachResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachResponse.ProtoReflect.Descriptor instead.
+func (*AttachResponse) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AttachResponse) GetPdf() []byte {
+	if x != nil {
+		return x.Pdf
+	}
+	return nil
+}
+
+type AttachStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pdf           []byte                 `protobuf:"bytes,1,opt,name=pdf,proto3" json:"pdf,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachStreamResponse) Reset() {
+	*x = AttachStreamResponse{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachStreamResponse) ProtoMessage() {}
+
+func (x *AttachStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachStreamResponse.ProtoReflect.Descriptor instead.
+func (*AttachStreamResponse) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AttachStreamResponse) GetPdf() []byte {
+	if x != nil {
+		return x.Pdf
+	}
+	return nil
+}
+
+type ExtractRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pdf           []byte                 `protobuf:"bytes,1,opt,name=pdf,proto3" json:"pdf,omitempty"`
+	UserPassword  string                 `protobuf:"bytes,2,opt,name=user_password,json=userPassword,proto3" json:"user_password,omitempty"`
+	OwnerPassword string                 `protobuf:"bytes,3,opt,name=owner_password,json=ownerPassword,proto3" json:"owner_password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractRequest) Reset() {
+	*x = ExtractRequest{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractRequest) ProtoMessage() {}
+
+func (x *ExtractRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractRequest.ProtoReflect.Descriptor instead.
+func (*ExtractRequest) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExtractRequest) GetPdf() []byte {
+	if x != nil {
+		return x.Pdf
+	}
+	return nil
+}
+
+func (x *ExtractRequest) GetUserPassword() string {
+	if x != nil {
+		return x.UserPassword
+	}
+	return ""
+}
+
+func (x *ExtractRequest) GetOwnerPassword() string {
+	if x != nil {
+		return x.OwnerPassword
+	}
+	return ""
+}
+
+type ExtractMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserPassword  string                 `protobuf:"bytes,1,opt,name=user_password,json=userPassword,proto3" json:"user_password,omitempty"`
+	OwnerPassword string                 `protobuf:"bytes,2,opt,name=owner_password,json=ownerPassword,proto3" json:"owner_password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractMetadata) Reset() {
+	*x = ExtractMetadata{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractMetadata) ProtoMessage() {}
+
+func (x *ExtractMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractMetadata.ProtoReflect.Descriptor instead.
+func (*ExtractMetadata) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExtractMetadata) GetUserPassword() string {
+	if x != nil {
+		return x.UserPassword
+	}
+	return ""
+}
+
+func (x *ExtractMetadata) GetOwnerPassword() string {
+	if x != nil {
+		return x.OwnerPassword
+	}
+	return ""
+}
+
+type ExtractStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ExtractStreamRequest_Metadata
+	//	*ExtractStreamRequest_PdfChunk
+	Payload       isExtractStreamRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractStreamRequest) Reset() {
+	*x = ExtractStreamRequest{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractStreamRequest) ProtoMessage() {}
+
+func (x *ExtractStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractStreamRequest.ProtoReflect.Descriptor instead.
+func (*ExtractStreamRequest) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExtractStreamRequest) GetPayload() isExtractStreamRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ExtractStreamRequest) GetMetadata() *ExtractMetadata {
+	if x != nil {
+		if x, ok := x.Payload.(*ExtractStreamRequest_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *ExtractStreamRequest) GetPdfChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*ExtractStreamRequest_PdfChunk); ok {
+			return x.PdfChunk
+		}
+	}
+	return nil
+}
+
+type isExtractStreamRequest_Payload interface {
+	isExtractStreamRequest_Payload()
+}
+
+type ExtractStreamRequest_Metadata struct {
+	Metadata *ExtractMetadata `protobuf:"bytes,1,opt,name=metadata,proto3,oneof"`
+}
+
+type ExtractStreamRequest_PdfChunk struct {
+	PdfChunk []byte `protobuf:"bytes,2,opt,name=pdf_chunk,json=pdfChunk,proto3,oneof"`
+}
+
+func (*ExtractStreamRequest_Metadata) isExtractStreamRequest_Payload() {}
+
+func (*ExtractStreamRequest_PdfChunk) isExtractStreamRequest_Payload() {}
+
+// XMLInfo mirrors gopdfattach.XMLInfo.
+type XMLInfo struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FileType         FileType               `protobuf:"varint,1,opt,name=file_type,json=fileType,proto3,enum=gopdfattach.v1.FileType" json:"file_type,omitempty"`
+	DocumentType     string                 `protobuf:"bytes,2,opt,name=document_type,json=documentType,proto3" json:"document_type,omitempty"`
+	FileName         string                 `protobuf:"bytes,3,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Version          string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	ConformanceLevel string                 `protobuf:"bytes,5,opt,name=conformance_level,json=conformanceLevel,proto3" json:"conformance_level,omitempty"`
+	Signatures       []*SignatureInfo       `protobuf:"bytes,6,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	AttachmentSigned bool                   `protobuf:"varint,7,opt,name=attachment_signed,json=attachmentSigned,proto3" json:"attachment_signed,omitempty"`
+	// Where the XMP metadata contradicts the embedded XML or its file specification.
+	Warnings      []string `protobuf:"bytes,8,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *XMLInfo) Reset() {
+	*x = XMLInfo{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *XMLInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*XMLInfo) ProtoMessage() {}
+
+func (x *XMLInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use XMLInfo.ProtoReflect.Descriptor instead.
+func (*XMLInfo) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *XMLInfo) GetFileType() FileType {
+	if x != nil {
+		return x.FileType
+	}
+	return FileType_FILE_TYPE_UNSPECIFIED
+}
+
+func (x *XMLInfo) GetDocumentType() string {
+	if x != nil {
+		return x.DocumentType
+	}
+	return ""
+}
+
+func (x *XMLInfo) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *XMLInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *XMLInfo) GetConformanceLevel() string {
+	if x != nil {
+		return x.ConformanceLevel
+	}
+	return ""
+}
+
+func (x *XMLInfo) GetSignatures() []*SignatureInfo {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+func (x *XMLInfo) GetAttachmentSigned() bool {
+	if x != nil {
+		return x.AttachmentSigned
+	}
+	return false
+}
+
+func (x *XMLInfo) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// SignatureInfo mirrors gopdfattach.SignatureInfo.
+type SignatureInfo struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	FieldName           string                 `protobuf:"bytes,1,opt,name=field_name,json=fieldName,proto3" json:"field_name,omitempty"`
+	SubFilter           string                 `protobuf:"bytes,2,opt,name=sub_filter,json=subFilter,proto3" json:"sub_filter,omitempty"`
+	Name                string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Reason              string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Location            string                 `protobuf:"bytes,5,opt,name=location,proto3" json:"location,omitempty"`
+	SigningTime         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=signing_time,json=signingTime,proto3" json:"signing_time,omitempty"`
+	Certificate         []byte                 `protobuf:"bytes,7,opt,name=certificate,proto3" json:"certificate,omitempty"` // DER encoded signer certificate
+	Timestamped         bool                   `protobuf:"varint,8,opt,name=timestamped,proto3" json:"timestamped,omitempty"`
+	Valid               bool                   `protobuf:"varint,9,opt,name=valid,proto3" json:"valid,omitempty"`
+	Error               string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	CoversWholeDocument bool                   `protobuf:"varint,11,opt,name=covers_whole_document,json=coversWholeDocument,proto3" json:"covers_whole_document,omitempty"`
+	CoversAttachment    bool                   `protobuf:"varint,12,opt,name=covers_attachment,json=coversAttachment,proto3" json:"covers_attachment,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SignatureInfo) Reset() {
+	*x = SignatureInfo{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignatureInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignatureInfo) ProtoMessage() {}
+
+func (x *SignatureInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignatureInfo.ProtoReflect.Descriptor instead.
+func (*SignatureInfo) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SignatureInfo) GetFieldName() string {
+	if x != nil {
+		return x.FieldName
+	}
+	return ""
+}
+
+func (x *SignatureInfo) GetSubFilter() string {
+	if x != nil {
+		return x.SubFilter
+	}
+	return ""
+}
+
+func (x *SignatureInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SignatureInfo) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SignatureInfo) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *SignatureInfo) GetSigningTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SigningTime
+	}
+	return nil
+}
+
+func (x *SignatureInfo) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *SignatureInfo) GetTimestamped() bool {
+	if x != nil {
+		return x.Timestamped
+	}
+	return false
+}
+
+func (x *SignatureInfo) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *SignatureInfo) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SignatureInfo) GetCoversWholeDocument() bool {
+	if x != nil {
+		return x.CoversWholeDocument
+	}
+	return false
+}
+
+func (x *SignatureInfo) GetCoversAttachment() bool {
+	if x != nil {
+		return x.CoversAttachment
+	}
+	return false
+}
+
+type ExtractResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Xml           []byte                 `protobuf:"bytes,1,opt,name=xml,proto3" json:"xml,omitempty"`
+	Info          *XMLInfo               `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractResponse) Reset() {
+	*x = ExtractResponse{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractResponse) ProtoMessage() {}
+
+func (x *ExtractResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractResponse.ProtoReflect.Descriptor instead.
+func (*ExtractResponse) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ExtractResponse) GetXml() []byte {
+	if x != nil {
+		return x.Xml
+	}
+	return nil
+}
+
+func (x *ExtractResponse) GetInfo() *XMLInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+type ExtractStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Xml           []byte                 `protobuf:"bytes,1,opt,name=xml,proto3" json:"xml,omitempty"`
+	Info          *XMLInfo               `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractStreamResponse) Reset() {
+	*x = ExtractStreamResponse{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractStreamResponse) ProtoMessage() {}
+
+func (x *ExtractStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractStreamResponse.ProtoReflect.Descriptor instead.
+func (*ExtractStreamResponse) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ExtractStreamResponse) GetXml() []byte {
+	if x != nil {
+		return x.Xml
+	}
+	return nil
+}
+
+func (x *ExtractStreamResponse) GetInfo() *XMLInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+type ValidateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileType      FileType               `protobuf:"varint,1,opt,name=file_type,json=fileType,proto3,enum=gopdfattach.v1.FileType" json:"file_type,omitempty"`
+	Config        *AttachConfig          `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateRequest) Reset() {
+	*x = ValidateRequest{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequest) ProtoMessage() {}
+
+func (x *ValidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ValidateRequest) GetFileType() FileType {
+	if x != nil {
+		return x.FileType
+	}
+	return FileType_FILE_TYPE_UNSPECIFIED
+}
+
+func (x *ValidateRequest) GetConfig() *AttachConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type ValidateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateResponse) Reset() {
+	*x = ValidateResponse{}
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateResponse) ProtoMessage() {}
+
+func (x *ValidateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gopdfattach_v1_gopdfattach_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateResponse.ProtoReflect.Descriptor instead.
+func (*ValidateResponse) Descriptor() ([]byte, []int) {
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ValidateResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_gopdfattach_v1_gopdfattach_proto protoreflect.FileDescriptor
+
+var file_gopdfattach_v1_gopdfattach_proto_rawDesc = string([]byte{
+	0x0a, 0x20, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2f, 0x76, 0x31,
+	0x2f, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e,
+	0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xc8, 0x02, 0x0a, 0x0c, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74,
+	0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x6f, 0x63,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c,
+	0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69,
+	0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x2b, 0x0a, 0x11, 0x63, 0x6f, 0x6e, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x6e, 0x63, 0x65, 0x5f,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x63, 0x6f, 0x6e,
+	0x66, 0x6f, 0x72, 0x6d, 0x61, 0x6e, 0x63, 0x65, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x27, 0x0a, 0x0f, 0x61, 0x66, 0x5f, 0x72, 0x65,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x61, 0x66, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70,
+	0x12, 0x20, 0x0a, 0x0b, 0x69, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x61, 0x6c, 0x12, 0x23, 0x0a, 0x0d, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x75, 0x73, 0x65, 0x72, 0x50,
+	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x6f, 0x77, 0x6e, 0x65, 0x72,
+	0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0xa0,
+	0x01, 0x0a, 0x0d, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x35, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63,
+	0x68, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x66,
+	0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x64, 0x66, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x70, 0x64, 0x66, 0x12, 0x10, 0x0a, 0x03, 0x78, 0x6d, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x78, 0x6d, 0x6c, 0x12, 0x34, 0x0a, 0x06, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f,
+	0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74,
+	0x61, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x22, 0x7d, 0x0a, 0x0e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x35, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74,
+	0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65,
+	0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x34, 0x0a, 0x06, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x70,
+	0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74, 0x61,
+	0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x22, 0x9c, 0x01, 0x0a, 0x13, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3c, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x70,
+	0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74, 0x61,
+	0x63, 0x68, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x08, 0x6d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a, 0x09, 0x70, 0x64, 0x66, 0x5f, 0x63, 0x68,
+	0x75, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x08, 0x70, 0x64, 0x66,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x1d, 0x0a, 0x09, 0x78, 0x6d, 0x6c, 0x5f, 0x63, 0x68, 0x75,
+	0x6e, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x08, 0x78, 0x6d, 0x6c, 0x43,
+	0x68, 0x75, 0x6e, 0x6b, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22,
+	0x22, 0x0a, 0x0e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x64, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03,
+	0x70, 0x64, 0x66, 0x22, 0x28, 0x0a, 0x14, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70,
+	0x64, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x70, 0x64, 0x66, 0x22, 0x6e, 0x0a,
+	0x0e, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x70, 0x64, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x70, 0x64,
+	0x66, 0x12, 0x23, 0x0a, 0x0d, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f,
+	0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x75, 0x73, 0x65, 0x72, 0x50, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x5f,
+	0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0x5d, 0x0a,
+	0x0f, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x12, 0x23, 0x0a, 0x0d, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x75, 0x73, 0x65, 0x72, 0x50, 0x61, 0x73,
+	0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x5f, 0x70,
+	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6f,
+	0x77, 0x6e, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0x7f, 0x0a, 0x14,
+	0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x3d, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74,
+	0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x4d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a, 0x09, 0x70, 0x64, 0x66, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x08, 0x70, 0x64, 0x66, 0x43, 0x68, 0x75,
+	0x6e, 0x6b, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0xd1, 0x02,
+	0x0a, 0x07, 0x58, 0x4d, 0x4c, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x35, 0x0a, 0x09, 0x66, 0x69, 0x6c,
+	0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67,
+	0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69,
+	0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x23, 0x0a, 0x0d, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e,
+	0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x11,
+	0x63, 0x6f, 0x6e, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6c, 0x65, 0x76, 0x65,
+	0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x63, 0x6f, 0x6e, 0x66, 0x6f, 0x72, 0x6d,
+	0x61, 0x6e, 0x63, 0x65, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x3d, 0x0a, 0x0a, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0a, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x61, 0x74, 0x74, 0x61,
+	0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x10, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x53,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67,
+	0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67,
+	0x73, 0x22, 0xa5, 0x03, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x75, 0x62, 0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x75, 0x62, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1a, 0x0a,
+	0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3d, 0x0a, 0x0c, 0x73, 0x69, 0x67,
+	0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x73, 0x69, 0x67,
+	0x6e, 0x69, 0x6e, 0x67, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x65, 0x72, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x63,
+	0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0b, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x32, 0x0a, 0x15, 0x63, 0x6f, 0x76, 0x65,
+	0x72, 0x73, 0x5f, 0x77, 0x68, 0x6f, 0x6c, 0x65, 0x5f, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e,
+	0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x73, 0x57,
+	0x68, 0x6f, 0x6c, 0x65, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2b, 0x0a, 0x11,
+	0x63, 0x6f, 0x76, 0x65, 0x72, 0x73, 0x5f, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e,
+	0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x73, 0x41,
+	0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x50, 0x0a, 0x0f, 0x45, 0x78, 0x74,
+	0x72, 0x61, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x78, 0x6d, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x78, 0x6d, 0x6c, 0x12, 0x2b,
+	0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67,
+	0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x58, 0x4d,
+	0x4c, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x22, 0x56, 0x0a, 0x15, 0x45,
+	0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x78, 0x6d, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x03, 0x78, 0x6d, 0x6c, 0x12, 0x2b, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61,
+	0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x58, 0x4d, 0x4c, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69,
+	0x6e, 0x66, 0x6f, 0x22, 0x7e, 0x0a, 0x0f, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x35, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x70, 0x64,
+	0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x34, 0x0a,
+	0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e,
+	0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x74, 0x74, 0x61, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x22, 0x3e, 0x0a, 0x10, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x14, 0x0a,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x2a, 0x53, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x19, 0x0a, 0x15, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x46, 0x49,
+	0x4c, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x46, 0x41, 0x43, 0x54, 0x55, 0x52, 0x58, 0x10,
+	0x01, 0x12, 0x15, 0x0a, 0x11, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x5a,
+	0x55, 0x47, 0x46, 0x45, 0x52, 0x44, 0x10, 0x02, 0x32, 0xb5, 0x03, 0x0a, 0x12, 0x47, 0x6f, 0x70,
+	0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x47, 0x0a, 0x06, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x70, 0x64,
+	0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66,
+	0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5b, 0x0a, 0x0c, 0x41, 0x74, 0x74, 0x61,
+	0x63, 0x68, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x23, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66,
+	0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e,
+	0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x74, 0x74, 0x61, 0x63, 0x68, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x4a, 0x0a, 0x07, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74,
+	0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1f, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x5e, 0x0a, 0x0d, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x12, 0x24, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66,
+	0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28,
+	0x01, 0x12, 0x4d, 0x0a, 0x08, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x12, 0x1f, 0x2e,
+	0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x56,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20,
+	0x2e, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e,
+	0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x42, 0x44, 0x5a, 0x42, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4d,
+	0x61, 0x72, 0x6c, 0x69, 0x6e, 0x4b, 0x75, 0x68, 0x6e, 0x2f, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61,
+	0x74, 0x74, 0x61, 0x63, 0x68, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61,
+	0x74, 0x74, 0x61, 0x63, 0x68, 0x2f, 0x76, 0x31, 0x3b, 0x67, 0x6f, 0x70, 0x64, 0x66, 0x61, 0x74,
+	0x74, 0x61, 0x63, 0x68, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_gopdfattach_v1_gopdfattach_proto_rawDescOnce sync.Once
+	file_gopdfattach_v1_gopdfattach_proto_rawDescData []byte
+)
+
+func file_gopdfattach_v1_gopdfattach_proto_rawDescGZIP() []byte {
+	file_gopdfattach_v1_gopdfattach_proto_rawDescOnce.Do(func() {
+		file_gopdfattach_v1_gopdfattach_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gopdfattach_v1_gopdfattach_proto_rawDesc), len(file_gopdfattach_v1_gopdfattach_proto_rawDesc)))
+	})
+	return file_gopdfattach_v1_gopdfattach_proto_rawDescData
+}
+
+var file_gopdfattach_v1_gopdfattach_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_gopdfattach_v1_gopdfattach_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_gopdfattach_v1_gopdfattach_proto_goTypes = []any{
+	(FileType)(0),                 // 0: gopdfattach.v1.FileType
+	(*AttachConfig)(nil),          // 1: gopdfattach.v1.AttachConfig
+	(*AttachRequest)(nil),         // 2: gopdfattach.v1.AttachRequest
+	(*AttachMetadata)(nil),        // 3: gopdfattach.v1.AttachMetadata
+	(*AttachStreamRequest)(nil),   // 4: gopdfattach.v1.AttachStreamRequest
+	(*AttachResponse)(nil),        // 5: gopdfattach.v1.AttachResponse
+	(*AttachStreamResponse)(nil),  // 6: gopdfattach.v1.AttachStreamResponse
+	(*ExtractRequest)(nil),        // 7: gopdfattach.v1.ExtractRequest
+	(*ExtractMetadata)(nil),       // 8: gopdfattach.v1.ExtractMetadata
+	(*ExtractStreamRequest)(nil),  // 9: gopdfattach.v1.ExtractStreamRequest
+	(*XMLInfo)(nil),               // 10: gopdfattach.v1.XMLInfo
+	(*SignatureInfo)(nil),         // 11: gopdfattach.v1.SignatureInfo
+	(*ExtractResponse)(nil),       // 12: gopdfattach.v1.ExtractResponse
+	(*ExtractStreamResponse)(nil), // 13: gopdfattach.v1.ExtractStreamResponse
+	(*ValidateRequest)(nil),       // 14: gopdfattach.v1.ValidateRequest
+	(*ValidateResponse)(nil),      // 15: gopdfattach.v1.ValidateResponse
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+}
+var file_gopdfattach_v1_gopdfattach_proto_depIdxs = []int32{
+	0,  // 0: gopdfattach.v1.AttachRequest.file_type:type_name -> gopdfattach.v1.FileType
+	1,  // 1: gopdfattach.v1.AttachRequest.config:type_name -> gopdfattach.v1.AttachConfig
+	0,  // 2: gopdfattach.v1.AttachMetadata.file_type:type_name -> gopdfattach.v1.FileType
+	1,  // 3: gopdfattach.v1.AttachMetadata.config:type_name -> gopdfattach.v1.AttachConfig
+	3,  // 4: gopdfattach.v1.AttachStreamRequest.metadata:type_name -> gopdfattach.v1.AttachMetadata
+	8,  // 5: gopdfattach.v1.ExtractStreamRequest.metadata:type_name -> gopdfattach.v1.ExtractMetadata
+	0,  // 6: gopdfattach.v1.XMLInfo.file_type:type_name -> gopdfattach.v1.FileType
+	11, // 7: gopdfattach.v1.XMLInfo.signatures:type_name -> gopdfattach.v1.SignatureInfo
+	16, // 8: gopdfattach.v1.SignatureInfo.signing_time:type_name -> google.protobuf.Timestamp
+	10, // 9: gopdfattach.v1.ExtractResponse.info:type_name -> gopdfattach.v1.XMLInfo
+	10, // 10: gopdfattach.v1.ExtractStreamResponse.info:type_name -> gopdfattach.v1.XMLInfo
+	0,  // 11: gopdfattach.v1.ValidateRequest.file_type:type_name -> gopdfattach.v1.FileType
+	1,  // 12: gopdfattach.v1.ValidateRequest.config:type_name -> gopdfattach.v1.AttachConfig
+	2,  // 13: gopdfattach.v1.GopdfattachService.Attach:input_type -> gopdfattach.v1.AttachRequest
+	4,  // 14: gopdfattach.v1.GopdfattachService.AttachStream:input_type -> gopdfattach.v1.AttachStreamRequest
+	7,  // 15: gopdfattach.v1.GopdfattachService.Extract:input_type -> gopdfattach.v1.ExtractRequest
+	9,  // 16: gopdfattach.v1.GopdfattachService.ExtractStream:input_type -> gopdfattach.v1.ExtractStreamRequest
+	14, // 17: gopdfattach.v1.GopdfattachService.Validate:input_type -> gopdfattach.v1.ValidateRequest
+	5,  // 18: gopdfattach.v1.GopdfattachService.Attach:output_type -> gopdfattach.v1.AttachResponse
+	6,  // 19: gopdfattach.v1.GopdfattachService.AttachStream:output_type -> gopdfattach.v1.AttachStreamResponse
+	12, // 20: gopdfattach.v1.GopdfattachService.Extract:output_type -> gopdfattach.v1.ExtractResponse
+	13, // 21: gopdfattach.v1.GopdfattachService.ExtractStream:output_type -> gopdfattach.v1.ExtractStreamResponse
+	15, // 22: gopdfattach.v1.GopdfattachService.Validate:output_type -> gopdfattach.v1.ValidateResponse
+	18, // [18:23] is the sub-list for method output_type
+	13, // [13:18] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_gopdfattach_v1_gopdfattach_proto_init() }
+func file_gopdfattach_v1_gopdfattach_proto_init() {
+	if File_gopdfattach_v1_gopdfattach_proto != nil {
+		return
+	}
+	file_gopdfattach_v1_gopdfattach_proto_msgTypes[3].OneofWrappers = []any{
+		(*AttachStreamRequest_Metadata)(nil),
+		(*AttachStreamRequest_PdfChunk)(nil),
+		(*AttachStreamRequest_XmlChunk)(nil),
+	}
+	file_gopdfattach_v1_gopdfattach_proto_msgTypes[8].OneofWrappers = []any{
+		(*ExtractStreamRequest_Metadata)(nil),
+		(*ExtractStreamRequest_PdfChunk)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gopdfattach_v1_gopdfattach_proto_rawDesc), len(file_gopdfattach_v1_gopdfattach_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gopdfattach_v1_gopdfattach_proto_goTypes,
+		DependencyIndexes: file_gopdfattach_v1_gopdfattach_proto_depIdxs,
+		EnumInfos:         file_gopdfattach_v1_gopdfattach_proto_enumTypes,
+		MessageInfos:      file_gopdfattach_v1_gopdfattach_proto_msgTypes,
+	}.Build()
+	File_gopdfattach_v1_gopdfattach_proto = out.File
+	file_gopdfattach_v1_gopdfattach_proto_goTypes = nil
+	file_gopdfattach_v1_gopdfattach_proto_depIdxs = nil
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2025. Marlin Kuhn
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: gopdfattach/v1/gopdfattach.proto
+
+package gopdfattachv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GopdfattachService_Attach_FullMethodName        = "/gopdfattach.v1.GopdfattachService/Attach"
+	GopdfattachService_AttachStream_FullMethodName  = "/gopdfattach.v1.GopdfattachService/AttachStream"
+	GopdfattachService_Extract_FullMethodName       = "/gopdfattach.v1.GopdfattachService/Extract"
+	GopdfattachService_ExtractStream_FullMethodName = "/gopdfattach.v1.GopdfattachService/ExtractStream"
+	GopdfattachService_Validate_FullMethodName      = "/gopdfattach.v1.GopdfattachService/Validate"
+)
+
+// GopdfattachServiceClient is the client API for GopdfattachService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GopdfattachService attaches Factur-X / ZUGFeRD XML to PDFs and extracts it again.
+type GopdfattachServiceClient interface {
+	// Attach attaches XML to a PDF and returns the PDF/A-3 hybrid.
+	Attach(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (*AttachResponse, error)
+	// AttachStream is like Attach for PDFs that exceed the message size limit. The first message
+	// must carry the metadata, the following ones carry chunks of the PDF and XML.
+	AttachStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[AttachStreamRequest, AttachStreamResponse], error)
+	// Extract extracts the XML and its metadata from a hybrid PDF.
+	Extract(ctx context.Context, in *ExtractRequest, opts ...grpc.CallOption) (*ExtractResponse, error)
+	// ExtractStream is like Extract for PDFs that exceed the message size limit. The first message
+	// may carry the metadata, all messages may carry chunks of the PDF.
+	ExtractStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ExtractStreamRequest, ExtractStreamResponse], error)
+	// Validate checks an attach config against the values allowed by the file type.
+	Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
+}
+
+type gopdfattachServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGopdfattachServiceClient(cc grpc.ClientConnInterface) GopdfattachServiceClient {
+	return &gopdfattachServiceClient{cc}
+}
+
+func (c *gopdfattachServiceClient) Attach(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (*AttachResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachResponse)
+	err := c.cc.Invoke(ctx, GopdfattachService_Attach_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gopdfattachServiceClient) AttachStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[AttachStreamRequest, AttachStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GopdfattachService_ServiceDesc.Streams[0], GopdfattachService_AttachStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AttachStreamRequest, AttachStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GopdfattachService_AttachStreamClient = grpc.ClientStreamingClient[AttachStreamRequest, AttachStreamResponse]
+
+func (c *gopdfattachServiceClient) Extract(ctx context.Context, in *ExtractRequest, opts ...grpc.CallOption) (*ExtractResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExtractResponse)
+	err := c.cc.Invoke(ctx, GopdfattachService_Extract_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gopdfattachServiceClient) ExtractStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ExtractStreamRequest, ExtractStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GopdfattachService_ServiceDesc.Streams[1], GopdfattachService_ExtractStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExtractStreamRequest, ExtractStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GopdfattachService_ExtractStreamClient = grpc.ClientStreamingClient[ExtractStreamRequest, ExtractStreamResponse]
+
+func (c *gopdfattachServiceClient) Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, GopdfattachService_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GopdfattachServiceServer is the server API for GopdfattachService service.
+// All implementations must embed UnimplementedGopdfattachServiceServer
+// for forward compatibility.
+//
+// GopdfattachService attaches Factur-X / ZUGFeRD XML to PDFs and extracts it again.
+type GopdfattachServiceServer interface {
+	// Attach attaches XML to a PDF and returns the PDF/A-3 hybrid.
+	Attach(context.Context, *AttachRequest) (*AttachResponse, error)
+	// AttachStream is like Attach for PDFs that exceed the message size limit. The first message
+	// must carry the metadata, the following ones carry chunks of the PDF and XML.
+	AttachStream(grpc.ClientStreamingServer[AttachStreamRequest, AttachStreamResponse]) error
+	// Extract extracts the XML and its metadata from a hybrid PDF.
+	Extract(context.Context, *ExtractRequest) (*ExtractResponse, error)
+	// ExtractStream is like Extract for PDFs that exceed the message size limit. The first message
+	// may carry the metadata, all messages may carry chunks of the PDF.
+	ExtractStream(grpc.ClientStreamingServer[ExtractStreamRequest, ExtractStreamResponse]) error
+	// Validate checks an attach config against the values allowed by the file type.
+	Validate(context.Context, *ValidateRequest) (*ValidateResponse, error)
+	mustEmbedUnimplementedGopdfattachServiceServer()
+}
+
+// UnimplementedGopdfattachServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGopdfattachServiceServer struct{}
+
+func (UnimplementedGopdfattachServiceServer) Attach(context.Context, *AttachRequest) (*AttachResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Attach not implemented")
+}
+func (UnimplementedGopdfattachServiceServer) AttachStream(grpc.ClientStreamingServer[AttachStreamRequest, AttachStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method AttachStream not implemented")
+}
+func (UnimplementedGopdfattachServiceServer) Extract(context.Context, *ExtractRequest) (*ExtractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Extract not implemented")
+}
+func (UnimplementedGopdfattachServiceServer) ExtractStream(grpc.ClientStreamingServer[ExtractStreamRequest, ExtractStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExtractStream not implemented")
+}
+func (UnimplementedGopdfattachServiceServer) Validate(context.Context, *ValidateRequest) (*ValidateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedGopdfattachServiceServer) mustEmbedUnimplementedGopdfattachServiceServer() {}
+func (UnimplementedGopdfattachServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeGopdfattachServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GopdfattachServiceServer will
+// result in compilation errors.
+type UnsafeGopdfattachServiceServer interface {
+	mustEmbedUnimplementedGopdfattachServiceServer()
+}
+
+func RegisterGopdfattachServiceServer(s grpc.ServiceRegistrar, srv GopdfattachServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGopdfattachServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GopdfattachService_ServiceDesc, srv)
+}
+
+func _GopdfattachService_Attach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GopdfattachServiceServer).Attach(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GopdfattachService_Attach_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GopdfattachServiceServer).Attach(ctx, req.(*AttachRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GopdfattachService_AttachStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GopdfattachServiceServer).AttachStream(&grpc.GenericServerStream[AttachStreamRequest, AttachStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GopdfattachService_AttachStreamServer = grpc.ClientStreamingServer[AttachStreamRequest, AttachStreamResponse]
+
+func _GopdfattachService_Extract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GopdfattachServiceServer).Extract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GopdfattachService_Extract_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GopdfattachServiceServer).Extract(ctx, req.(*ExtractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GopdfattachService_ExtractStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GopdfattachServiceServer).ExtractStream(&grpc.GenericServerStream[ExtractStreamRequest, ExtractStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GopdfattachService_ExtractStreamServer = grpc.ClientStreamingServer[ExtractStreamRequest, ExtractStreamResponse]
+
+func _GopdfattachService_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GopdfattachServiceServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GopdfattachService_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GopdfattachServiceServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GopdfattachService_ServiceDesc is the grpc.ServiceDesc for GopdfattachService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GopdfattachService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gopdfattach.v1.GopdfattachService",
+	HandlerType: (*GopdfattachServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Attach",
+			Handler:    _GopdfattachService_Attach_Handler,
+		},
+		{
+			MethodName: "Extract",
+			Handler:    _GopdfattachService_Extract_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _GopdfattachService_Validate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AttachStream",
+			Handler:       _GopdfattachService_AttachStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExtractStream",
+			Handler:       _GopdfattachService_ExtractStream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gopdfattach/v1/gopdfattach.proto",
+}
@@ -1,9 +1,18 @@
 package gopdfattach
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/MarlinKuhn/gopdfattach/internal/attach"
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
 )
 
 // AF represents the relationship between the embedded file and the PDF document
@@ -27,13 +36,165 @@ const (
 	AFSupplement AF = "Supplement"
 )
 
+var afRelationships = []AF{AFAlternative, AFData, AFSource, AFSupplement}
+
+// DocumentType constants define the values allowed for fx:DocumentType and zf:DocumentType, the kind
+// of business document carried by the embedded XML. They are untyped, so they can be assigned to
+// AttachConfig.DocumentType as well as compared with XMLInfo.DocumentType. The fields deliberately
+// stay string for compatibility with existing callers; Validate rejects unknown values instead.
+const (
+	DocumentTypeInvoice       = "INVOICE"
+	DocumentTypeOrder         = "ORDER"
+	DocumentTypeOrderResponse = "ORDER_RESPONSE"
+	DocumentTypeOrderChange   = "ORDER_CHANGE"
+)
+
+var documentTypes = []string{
+	DocumentTypeInvoice,
+	DocumentTypeOrder,
+	DocumentTypeOrderResponse,
+	DocumentTypeOrderChange,
+}
+
+// Conformance level constants define the profiles of the embedded XML known to Factur-X and
+// ZUGFeRD. Like the document types they are untyped string constants.
+const (
+	ConformanceMinimum   = "MINIMUM"
+	ConformanceBasicWL   = "BASIC WL"
+	ConformanceBasic     = "BASIC"
+	ConformanceEN16931   = "EN 16931"
+	ConformanceExtended  = "EXTENDED"
+	ConformanceXRechnung = "XRECHNUNG"
+)
+
+var (
+	// facturXConformanceLevels lists the profiles allowed in the fx namespace.
+	facturXConformanceLevels = []string{
+		ConformanceMinimum,
+		ConformanceBasicWL,
+		ConformanceBasic,
+		ConformanceEN16931,
+		ConformanceExtended,
+		ConformanceXRechnung,
+	}
+
+	// zugferdConformanceLevels lists the profiles allowed in the ZUGFeRD 2.0 zf namespace,
+	// which predates the XRECHNUNG profile.
+	zugferdConformanceLevels = []string{
+		ConformanceMinimum,
+		ConformanceBasicWL,
+		ConformanceBasic,
+		ConformanceEN16931,
+		ConformanceExtended,
+	}
+
+	facturXVersions = []string{"1.0"}
+	zugferdVersions = []string{"1.0", "2.0", "2p0"}
+
+	// xRechnungVersion matches XRechnung releases such as "2.1" or "3.0.2", which are
+	// written as the version of the XRECHNUNG profile.
+	xRechnungVersion = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+)
+
+// ErrInvalidConfig is returned when an AttachConfig contains values that are not allowed
+// by the selected standard.
+var ErrInvalidConfig = errors.New("invalid attach config")
+
 type AttachConfig struct {
-	DocumentType     string // defaults to "INVOICE"
+	DocumentType     string // defaults to "INVOICE", see DocumentTypeInvoice and the other constants
 	FileName         string // defaults to "factur-x.xml"
 	Version          string // defaults to "1.0" if factur-x, "2p0" if zugferd
-	ConformanceLevel string // defaults to "EN 16931"
+	ConformanceLevel string // defaults to "EN 16931", see ConformanceEN16931 and the other constants
 	Creator          string // defaults to "gopdfattach"
-	AFRelationship   AF     // defaults to AFAlternative (spec-compliant for Factur-X/ZUGFeRD)
+	AFRelationship   AF     // defaults to AFData for MINIMUM and BASIC WL, AFAlternative otherwise
+
+	// Incremental appends the attachment, /AF entry, name tree and metadata changes as a PDF incremental
+	// update instead of rewriting the file. The original bytes stay untouched, so existing signatures
+	// remain valid and the change can be audited by diffing.
+	Incremental bool
+
+	// UserPassword and OwnerPassword open encrypted PDFs. Since PDF/A-3 forbids encryption, an
+	// encrypted PDF is only accepted if one of them is set and is then written decrypted.
+	UserPassword  string
+	OwnerPassword string
+
+	// Limits bounds the resources spent on the PDF and XML, see DefaultLimits.
+	Limits Limits
+
+	// Logger receives a debug event with duration and sizes for each step (read, validate xref,
+	// xmp merge, attach, write). Nil disables logging.
+	Logger *slog.Logger
+}
+
+// Validate checks the config against the values allowed for fileType, which is either
+// FileTypeFacturX or FileTypeZugferd. Empty fields are valid since they are replaced by defaults.
+// A nil config is valid.
+func (a *AttachConfig) Validate(fileType string) error {
+	var (
+		levels   []string
+		versions []string
+	)
+
+	switch fileType {
+	case FileTypeFacturX:
+		levels, versions = facturXConformanceLevels, facturXVersions
+	case FileTypeZugferd:
+		levels, versions = zugferdConformanceLevels, zugferdVersions
+	default:
+		return fmt.Errorf("%w: unknown file type %q", ErrInvalidConfig, fileType)
+	}
+
+	if a == nil {
+		return nil
+	}
+
+	if a.DocumentType != "" && !slices.Contains(documentTypes, a.DocumentType) {
+		return fmt.Errorf("%w: document type %q is not one of %s",
+			ErrInvalidConfig, a.DocumentType, joinQuoted(documentTypes))
+	}
+
+	if a.ConformanceLevel != "" && !slices.Contains(levels, a.ConformanceLevel) {
+		return fmt.Errorf("%w: conformance level %q is not allowed for %s, use one of %s",
+			ErrInvalidConfig, a.ConformanceLevel, fileType, joinQuoted(levels))
+	}
+
+	if a.Version != "" {
+		if a.ConformanceLevel == ConformanceXRechnung {
+			if !xRechnungVersion.MatchString(a.Version) {
+				return fmt.Errorf("%w: version %q is not a valid XRechnung version",
+					ErrInvalidConfig, a.Version)
+			}
+		} else if !slices.Contains(versions, a.Version) {
+			return fmt.Errorf("%w: version %q is not allowed for %s, use one of %s",
+				ErrInvalidConfig, a.Version, fileType, joinQuoted(versions))
+		}
+	}
+
+	if a.AFRelationship != "" && !slices.Contains(afRelationships, a.AFRelationship) {
+		return fmt.Errorf("%w: AF relationship %q is not one of %s",
+			ErrInvalidConfig, a.AFRelationship, joinQuoted(afRelationships))
+	}
+
+	if a.AFRelationship != "" {
+		level := a.ConformanceLevel
+		if level == "" {
+			level = ConformanceEN16931
+		}
+		if allowed := extract.AFRelationships(level); !slices.Contains(allowed, string(a.AFRelationship)) {
+			return fmt.Errorf("%w: AF relationship %q is not allowed for %s, use one of %s",
+				ErrInvalidConfig, a.AFRelationship, level, joinQuoted(allowed))
+		}
+	}
+
+	return nil
+}
+
+func joinQuoted[T ~string](values []T) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
 }
 
 func (a *AttachConfig) toConfig() attach.Config {
@@ -48,19 +209,50 @@ func (a *AttachConfig) toConfig() attach.Config {
 		ConformanceLevel: a.ConformanceLevel,
 		Creator:          a.Creator,
 		AFRelationship:   string(a.AFRelationship),
+		Incremental:      a.Incremental,
+		UserPassword:     a.UserPassword,
+		OwnerPassword:    a.OwnerPassword,
+		Limits:           a.Limits.toLimits(),
+		Logger:           a.Logger,
 	}
 }
 
 // AttachZUGFeRD attaches a ZUGFeRD XML file to a PDF document and converts it to a PDF/A-3 document.
+// The config is validated with Validate before anything is read.
+// An attachment of the same file name, like the invoice of a PDF that already is a hybrid, is replaced.
 func AttachZUGFeRD(zugFeRDXml io.Reader, pdf io.ReadSeeker, config *AttachConfig) ([]byte, error) {
+	return AttachZUGFeRDContext(context.Background(), zugFeRDXml, pdf, config)
+}
+
+// AttachZUGFeRDContext is like AttachZUGFeRD but stops once ctx is cancelled.
+func AttachZUGFeRDContext(ctx context.Context, zugFeRDXml io.Reader, pdf io.ReadSeeker, config *AttachConfig) (_ []byte, err error) {
+	defer recovery.Recover("attach", &err)
+
+	if err := config.Validate(FileTypeZugferd); err != nil {
+		return nil, err
+	}
+
 	c := config.toConfig()
 	c.XmlType = attach.TypeZugferd
-	return attach.Attach(zugFeRDXml, pdf, c)
+	return attach.Attach(ctx, zugFeRDXml, pdf, c)
 }
 
 // AttachFacturX attaches a Factur-X XML file to a PDF document and converts it to a PDF/A-3 document.
+// The config is validated with Validate before anything is read.
+// An attachment of the same file name, like the invoice of a PDF that already is a hybrid, is replaced.
 func AttachFacturX(factorXXml io.Reader, pdf io.ReadSeeker, config *AttachConfig) ([]byte, error) {
+	return AttachFacturXContext(context.Background(), factorXXml, pdf, config)
+}
+
+// AttachFacturXContext is like AttachFacturX but stops once ctx is cancelled.
+func AttachFacturXContext(ctx context.Context, factorXXml io.Reader, pdf io.ReadSeeker, config *AttachConfig) (_ []byte, err error) {
+	defer recovery.Recover("attach", &err)
+
+	if err := config.Validate(FileTypeFacturX); err != nil {
+		return nil, err
+	}
+
 	c := config.toConfig()
 	c.XmlType = attach.TypeFacturX
-	return attach.Attach(factorXXml, pdf, c)
+	return attach.Attach(ctx, factorXXml, pdf, c)
 }
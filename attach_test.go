@@ -9,7 +9,11 @@ import (
 	"os"
 	"testing"
 
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAttach_WithValidZugFeRD(t *testing.T) {
@@ -127,7 +131,7 @@ func TestAttach_WithCustomAFRelationship(t *testing.T) {
 		FileName:         "factur-x.xml",
 		Version:          "1.0",
 		ConformanceLevel: "EN 16931",
-		AFRelationship:   AFData, // Custom value
+		AFRelationship:   AFSource, // Custom value
 	}
 
 	pdfData, err := AttachFacturX(xmlFile, pdfFile, config)
@@ -165,3 +169,191 @@ func TestAttach_WithAlternativeAFRelationship(t *testing.T) {
 	assert.NotNil(t, xml)
 	assert.Equal(t, FileTypeZugferd, infos.FileType)
 }
+
+func TestAttachConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType string
+		config   *AttachConfig
+		wantErr  bool
+	}{
+		{name: "nil config", fileType: FileTypeFacturX, config: nil},
+		{name: "empty config", fileType: FileTypeZugferd, config: &AttachConfig{}},
+		{
+			name:     "valid factur-x",
+			fileType: FileTypeFacturX,
+			config: &AttachConfig{
+				DocumentType:     DocumentTypeInvoice,
+				Version:          "1.0",
+				ConformanceLevel: ConformanceEN16931,
+				AFRelationship:   AFAlternative,
+			},
+		},
+		{
+			name:     "valid xrechnung",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{Version: "2.1", ConformanceLevel: ConformanceXRechnung},
+		},
+		{
+			name:     "valid zugferd",
+			fileType: FileTypeZugferd,
+			config:   &AttachConfig{DocumentType: DocumentTypeOrder, Version: "2p0", ConformanceLevel: ConformanceBasicWL},
+		},
+		{
+			name:     "conformance level typo",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{ConformanceLevel: "EN16931"},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown document type",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{DocumentType: "RECEIPT"},
+			wantErr:  true,
+		},
+		{
+			name:     "zugferd version for factur-x",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{Version: "2p0"},
+			wantErr:  true,
+		},
+		{
+			name:     "xrechnung with zugferd 2.0",
+			fileType: FileTypeZugferd,
+			config:   &AttachConfig{ConformanceLevel: ConformanceXRechnung},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid xrechnung version",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{Version: "latest", ConformanceLevel: ConformanceXRechnung},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown AF relationship",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{AFRelationship: "Other"},
+			wantErr:  true,
+		},
+		{
+			name:     "data for basic wl",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{ConformanceLevel: ConformanceBasicWL, AFRelationship: AFData},
+		},
+		{
+			name:     "alternative for minimum",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{ConformanceLevel: ConformanceMinimum, AFRelationship: AFAlternative},
+			wantErr:  true,
+		},
+		{
+			name:     "data for default level",
+			fileType: FileTypeZugferd,
+			config:   &AttachConfig{AFRelationship: AFData},
+			wantErr:  true,
+		},
+		{
+			name:     "supplement for basic",
+			fileType: FileTypeFacturX,
+			config:   &AttachConfig{ConformanceLevel: ConformanceBasic, AFRelationship: AFSupplement},
+			wantErr:  true,
+		},
+		{name: "unknown file type", fileType: "UBL", config: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate(tt.fileType)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidConfig)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAttach_WithInvalidConfig(t *testing.T) {
+	pdfFile, _ := os.Open("testdata/invoice.pdf")
+	defer pdfFile.Close()
+	xmlFile, _ := os.Open("testdata/factur-x.xml")
+	defer xmlFile.Close()
+
+	pdfData, err := AttachFacturX(xmlFile, pdfFile, &AttachConfig{ConformanceLevel: "EN16931"})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Nil(t, pdfData)
+}
+
+func TestAttach_Incremental(t *testing.T) {
+	original, err := os.ReadFile("testdata/invoice.pdf")
+	assert.NoError(t, err)
+	xmlFile, _ := os.Open("testdata/factur-x.xml")
+	defer xmlFile.Close()
+
+	pdfData, err := AttachFacturX(xmlFile, bytes.NewReader(original), &AttachConfig{Incremental: true})
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdfData, original), "original bytes must be kept")
+	assert.Greater(t, len(pdfData), len(original))
+
+	xml, infos, err := Extract(bytes.NewReader(pdfData))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, xml)
+	assert.Equal(t, FileTypeFacturX, infos.FileType)
+	assert.Equal(t, "factur-x.xml", infos.FileName)
+}
+
+func TestAttach_IncrementalOnFacturX(t *testing.T) {
+	original, err := os.ReadFile("testdata/EN16931/EN16931_Einfach.pdf")
+	assert.NoError(t, err)
+	xmlFile, _ := os.Open("testdata/factur-x.xml")
+	defer xmlFile.Close()
+
+	config := &AttachConfig{FileName: "factur-x-corrected.xml", Incremental: true}
+	pdfData, err := AttachFacturX(xmlFile, bytes.NewReader(original), config)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdfData, original), "original bytes must be kept")
+
+	xml, infos, err := Extract(bytes.NewReader(pdfData))
+	assert.NoError(t, err)
+	assert.Equal(t, "factur-x-corrected.xml", infos.FileName)
+
+	expected, _ := os.ReadFile("testdata/factur-x.xml")
+	assert.Equal(t, expected, xml)
+}
+
+func TestAttach_ReplacesDirectFileSpec(t *testing.T) {
+	ctx, err := api.ReadContext(bytes.NewReader(attachedTestPDF(t)), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	require.NoError(t, api.ValidateContext(ctx))
+
+	// /AF holds a direct copy of the file specification instead of a reference to it.
+	catalog, err := ctx.Catalog()
+	require.NoError(t, err)
+	af, err := ctx.DereferenceArray(catalog["AF"])
+	require.NoError(t, err)
+	require.Len(t, af, 1)
+	spec, err := ctx.DereferenceDict(af[0])
+	require.NoError(t, err)
+	catalog["AF"] = types.Array{spec.Clone()}
+
+	var direct bytes.Buffer
+	require.NoError(t, api.WriteContext(ctx, &direct))
+
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+	pdfData, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(direct.Bytes()), nil)
+	require.NoError(t, err)
+
+	report, err := Inspect(bytes.NewReader(pdfData), nil)
+	require.NoError(t, err)
+	require.Len(t, report.EmbeddedFiles, 1)
+	assert.Equal(t, "factur-x.xml", report.EmbeddedFiles[0].FileName)
+
+	ctx, err = api.ReadContext(bytes.NewReader(pdfData), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	catalog, err = ctx.Catalog()
+	require.NoError(t, err)
+	af, err = ctx.DereferenceArray(catalog["AF"])
+	require.NoError(t, err)
+	assert.Len(t, af, 1)
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package batch attaches XML to many PDFs concurrently.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach"
+)
+
+// Job describes one PDF and the XML to attach to it.
+type Job struct {
+	ID       string                    // identifies the job in its Result
+	FileType string                    // gopdfattach.FileTypeFacturX (default) or gopdfattach.FileTypeZugferd
+	Config   *gopdfattach.AttachConfig // passed to AttachFacturX or AttachZUGFeRD
+
+	// Open returns the PDF and XML. It is called by the worker processing the job, so at most
+	// Options.Workers files are open at the same time. Both are closed after attaching.
+	Open func() (pdf io.ReadSeekCloser, xml io.ReadCloser, err error)
+
+	// Output receives the hybrid PDF. If nil, the PDF is returned in Result.PDF instead.
+	Output func(pdf []byte) error
+}
+
+// Result is the outcome of a Job.
+type Result struct {
+	Job      Job
+	PDF      []byte // the hybrid PDF if Job.Output is nil
+	Err      error
+	Duration time.Duration
+}
+
+// Options configures Run.
+type Options struct {
+	Workers int // number of jobs processed concurrently, defaults to runtime.GOMAXPROCS(0)
+}
+
+func (o *Options) setDefaults() {
+	if o.Workers <= 0 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+}
+
+// Run processes jobs until the channel is closed and sends one Result per job. The returned
+// channel is closed once all jobs are done. The caller must receive all results.
+//
+// After ctx is cancelled the remaining jobs are not processed anymore but still drained from jobs
+// and reported with ctx.Err(), so the producer of jobs should stop on ctx as well.
+func Run(ctx context.Context, jobs <-chan Job, options *Options) <-chan Result {
+	var o Options
+	if options != nil {
+		o = *options
+	}
+	o.setDefaults()
+
+	results := make(chan Result, o.Workers)
+
+	var wg sync.WaitGroup
+	for range o.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results <- process(ctx, job)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+func process(ctx context.Context, job Job) Result {
+	result := Result{Job: job}
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	start := time.Now()
+	pdf, err := attach(ctx, job)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if job.Output == nil {
+		result.PDF = pdf
+		return result
+	}
+
+	if err := job.Output(pdf); err != nil {
+		result.Err = fmt.Errorf("could not write output: %w", err)
+	}
+	return result
+}
+
+func attach(ctx context.Context, job Job) ([]byte, error) {
+	if job.Open == nil {
+		return nil, fmt.Errorf("missing Open")
+	}
+
+	pdf, xml, err := job.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer pdf.Close()
+	defer xml.Close()
+
+	switch job.FileType {
+	case "", gopdfattach.FileTypeFacturX:
+		return gopdfattach.AttachFacturXContext(ctx, xml, pdf, job.Config)
+	case gopdfattach.FileTypeZugferd:
+		return gopdfattach.AttachZUGFeRDContext(ctx, xml, pdf, job.Config)
+	default:
+		return nil, fmt.Errorf("unknown file type %q", job.FileType)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package batch
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error { return nil }
+
+func memoryJob(t *testing.T, id string, pdfPath string) Job {
+	t.Helper()
+
+	pdf, err := os.ReadFile(pdfPath)
+	require.NoError(t, err)
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	return Job{
+		ID: id,
+		Open: func() (io.ReadSeekCloser, io.ReadCloser, error) {
+			return nopCloser{bytes.NewReader(pdf)}, io.NopCloser(bytes.NewReader(xml)), nil
+		},
+	}
+}
+
+func collect(results <-chan Result) map[string]Result {
+	out := map[string]Result{}
+	for result := range results {
+		out[result.Job.ID] = result
+	}
+	return out
+}
+
+func TestRun(t *testing.T) {
+	jobs := make(chan Job)
+	go func() {
+		defer close(jobs)
+		jobs <- memoryJob(t, "ok-1", "../testdata/invoice.pdf")
+		jobs <- memoryJob(t, "ok-2", "../testdata/invoice.pdf")
+		jobs <- memoryJob(t, "broken", "../testdata/factur-x.xml")
+
+		job := memoryJob(t, "invalid-config", "../testdata/invoice.pdf")
+		job.Config = &gopdfattach.AttachConfig{ConformanceLevel: "EN16931"}
+		jobs <- job
+	}()
+
+	results := collect(Run(context.Background(), jobs, &Options{Workers: 2}))
+	require.Len(t, results, 4)
+
+	assert.NoError(t, results["ok-1"].Err)
+	assert.NotEmpty(t, results["ok-1"].PDF)
+	assert.NoError(t, results["ok-2"].Err)
+	assert.Error(t, results["broken"].Err)
+	assert.ErrorIs(t, results["invalid-config"].Err, gopdfattach.ErrInvalidConfig)
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	jobs := make(chan Job, 3)
+	for _, id := range []string{"a", "b", "c"} {
+		jobs <- memoryJob(t, id, "../testdata/invoice.pdf")
+	}
+	close(jobs)
+
+	results := collect(Run(ctx, jobs, nil))
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+		assert.Nil(t, result.PDF)
+	}
+}
+
+func TestPairFiles(t *testing.T) {
+	pdfDir, xmlDir, outDir := t.TempDir(), t.TempDir(), t.TempDir()
+
+	invoice, err := os.ReadFile("../testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(pdfDir, "RE-1001.pdf"), invoice, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(pdfDir, "RE-1002.PDF"), invoice, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(pdfDir, "RE-1003.pdf"), invoice, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(xmlDir, "RE-1001.xml"), xml, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(xmlDir, "RE-1002.xml"), xml, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(xmlDir, "RE-1004.xml"), xml, 0o644))
+
+	pairs, unmatched, err := PairFiles(pdfDir, xmlDir)
+	require.NoError(t, err)
+	require.Len(t, pairs, 2)
+	assert.Equal(t, "RE-1001", pairs[0].Name)
+	assert.Equal(t, "RE-1002", pairs[1].Name)
+	assert.Equal(t, []string{filepath.Join(pdfDir, "RE-1003.pdf"), filepath.Join(xmlDir, "RE-1004.xml")}, unmatched)
+
+	jobs := make(chan Job, len(pairs))
+	for _, pair := range pairs {
+		jobs <- pair.Job(outDir)
+	}
+	close(jobs)
+
+	for result := range Run(context.Background(), jobs, nil) {
+		assert.NoError(t, result.Err)
+		assert.Nil(t, result.PDF)
+	}
+
+	out, err := os.Open(filepath.Join(outDir, "RE-1002.PDF"))
+	require.NoError(t, err)
+	defer out.Close()
+
+	extracted, _, err := gopdfattach.Extract(out)
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted)
+}
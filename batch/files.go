@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package batch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Pair is a PDF and an XML file with the same basename.
+type Pair struct {
+	Name string // basename without extension
+	PDF  string
+	XML  string
+}
+
+// PairFiles pairs the *.pdf files of pdfDir with the *.xml files of xmlDir by basename,
+// e.g. pdfs/RE-1001.pdf with xmls/RE-1001.xml. Extensions are matched case-insensitively.
+// Files without a partner are returned in unmatched. Pairs are sorted by name.
+func PairFiles(pdfDir, xmlDir string) (pairs []Pair, unmatched []string, err error) {
+	pdfs, err := filesByName(pdfDir, ".pdf")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	xmls, err := filesByName(xmlDir, ".xml")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for name, pdf := range pdfs {
+		xml, ok := xmls[name]
+		if !ok {
+			unmatched = append(unmatched, pdf)
+			continue
+		}
+		pairs = append(pairs, Pair{Name: name, PDF: pdf, XML: xml})
+	}
+
+	for name, xml := range xmls {
+		if _, ok := pdfs[name]; !ok {
+			unmatched = append(unmatched, xml)
+		}
+	}
+
+	slices.SortFunc(pairs, func(a, b Pair) int { return strings.Compare(a.Name, b.Name) })
+	slices.Sort(unmatched)
+
+	return pairs, unmatched, nil
+}
+
+func filesByName(dir, ext string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory: %w", err)
+	}
+
+	files := map[string]string{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.EqualFold(filepath.Ext(entry.Name()), ext) {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		files[name] = filepath.Join(dir, entry.Name())
+	}
+
+	return files, nil
+}
+
+// Job returns a job that reads the pair's files and writes the hybrid PDF to outDir under the PDF's file name.
+func (p Pair) Job(outDir string) Job {
+	return Job{
+		ID: p.Name,
+		Open: func() (io.ReadSeekCloser, io.ReadCloser, error) {
+			pdf, err := os.Open(p.PDF)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			xml, err := os.Open(p.XML)
+			if err != nil {
+				pdf.Close()
+				return nil, nil, err
+			}
+
+			return pdf, xml, nil
+		},
+		Output: func(pdf []byte) error {
+			return writeFile(filepath.Join(outDir, filepath.Base(p.PDF)), pdf)
+		},
+	}
+}
+
+// writeFile writes data to a temporary file that is renamed to path, so a cancelled batch
+// never leaves truncated PDFs behind.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
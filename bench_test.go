@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/stretchr/testify/require"
+)
+
+// Run go test -run NONE -bench . -benchmem -memprofile mem.out and inspect mem.out with
+// go tool pprof -sample_index=alloc_space to see where the memory goes.
+
+// scannedPDF writes a PDF of the given number of pages, each showing a grayscale image of
+// pixels bytes like a scanned page. The image data is random, so it does not compress.
+func scannedPDF(pages, pixels int) []byte {
+	r := rand.New(rand.NewPCG(uint64(pages), uint64(pixels)))
+	width := 1000
+	height := pixels / width
+
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+	object := func(body string, stream []byte) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\n", len(offsets), body)
+		if stream != nil {
+			buf.WriteString("stream\n")
+			buf.Write(stream)
+			buf.WriteString("\nendstream\n")
+		}
+		buf.WriteString("endobj\n")
+	}
+
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and the page tree, each page i takes the objects
+	// 3+3i (page), 4+3i (content) and 5+3i (image).
+	object("<< /Type /Catalog /Pages 2 0 R >>", nil)
+	kids := new(bytes.Buffer)
+	for i := range pages {
+		fmt.Fprintf(kids, "%d 0 R ", 3+3*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, pages), nil)
+
+	content := []byte("q 595 0 0 842 0 0 cm /Im0 Do Q")
+	image := make([]byte, width*height)
+	for i := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents %d 0 R /Resources << /XObject << /Im0 %d 0 R >> >> >>",
+			4+3*i, 5+3*i), nil)
+		object(fmt.Sprintf("<< /Length %d >>", len(content)), content)
+		for j := range image {
+			image[j] = byte(r.Uint32())
+		}
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Length %d >>",
+			width, height, len(image)), image)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+type benchmarkPDF struct {
+	name string
+	data []byte
+}
+
+// benchmarkPDFs returns a synthetic 200 page scan and the largest sample of the testdata corpus.
+func benchmarkPDFs(b *testing.B) []benchmarkPDF {
+	b.Helper()
+
+	sample, err := os.ReadFile("testdata/XRECHNUNG/XRECHNUNG_Elektron.pdf")
+	require.NoError(b, err)
+
+	return []benchmarkPDF{
+		{name: "scan-200-pages", data: scannedPDF(200, 100_000)},
+		{name: "XRECHNUNG_Elektron", data: sample},
+	}
+}
+
+func BenchmarkAttach(b *testing.B) {
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(b, err)
+
+	samples, err := filepath.Glob("testdata/*/*.pdf")
+	require.NoError(b, err)
+
+	var corpus [][]byte
+	var size int64
+	for _, sample := range append(samples, "testdata/invoice.pdf") {
+		data, err := os.ReadFile(sample)
+		require.NoError(b, err)
+		corpus = append(corpus, data)
+		size += int64(len(data))
+	}
+
+	b.Run("corpus", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(size)
+		for range b.N {
+			for _, pdf := range corpus {
+				_, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), nil)
+				require.NoError(b, err)
+			}
+		}
+	})
+
+	for _, pdf := range benchmarkPDFs(b) {
+		for _, incremental := range []bool{false, true} {
+			name := pdf.name
+			if incremental {
+				name += "-incremental"
+			}
+
+			b.Run(name, func(b *testing.B) {
+				config := &AttachConfig{Incremental: incremental}
+				b.ReportAllocs()
+				b.SetBytes(int64(len(pdf.data)))
+				for range b.N {
+					_, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf.data), config)
+					require.NoError(b, err)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkExtract compares the lazy read of FromReader with reading the whole PDF.
+func BenchmarkExtract(b *testing.B) {
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(b, err)
+
+	for _, pdf := range benchmarkPDFs(b) {
+		hybrid, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf.data), &AttachConfig{Incremental: true})
+		require.NoError(b, err)
+
+		for _, full := range []bool{false, true} {
+			name := pdf.name
+			if full {
+				name += "-full"
+			}
+
+			b.Run(name, func(b *testing.B) {
+				config := extract.Config{FullRead: full}
+				b.ReportAllocs()
+				b.SetBytes(int64(len(hybrid)))
+				for range b.N {
+					_, err := extract.FromReader(context.Background(), bytes.NewReader(hybrid), config)
+					require.NoError(b, err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,493 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package cii
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Type codes of the documents CreditNote and Correction create.
+const (
+	TypeCreditNote = "381"
+	TypeCorrection = "384"
+)
+
+// ErrInvalidCorrection is wrapped by the errors of ValidateCorrection.
+var ErrInvalidCorrection = errors.New("invalid credit note or correction")
+
+// CorrectionConfig describes a credit note or corrected invoice of an original invoice.
+type CorrectionConfig struct {
+	ID        string    // BT-1 of the new document, required
+	IssueDate time.Time // BT-2, defaults to today
+	Note      string    // BT-22, e.g. the reason of the correction
+
+	// Lines selects and adjusts the lines of the original. Nil credits or keeps all lines in full.
+	Lines []LineAdjustment
+}
+
+// LineAdjustment refers to a line of the original invoice by its ID. In a credit note Quantity and
+// NetPrice are what is credited, in a correction they are the corrected values; empty values
+// keep those of the original line.
+type LineAdjustment struct {
+	LineID   string
+	Quantity Decimal
+	NetPrice Decimal
+}
+
+// CreditNote returns a credit note (381) for original that references it as preceding invoice.
+// Amounts stay positive as in the original, the type code turns the direction. Without
+// adjustments all lines, allowances and charges are credited and the totals are kept; with
+// adjustments only the given lines are credited, document level allowances and charges are
+// dropped and the VAT breakdown and totals are recalculated.
+func CreditNote(original *Invoice, config *CorrectionConfig) (*Invoice, error) {
+	inv, err := derive(original, config, TypeCreditNote)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Lines == nil {
+		inv.Lines = append([]Line(nil), original.Lines...)
+		inv.AllowanceCharge = append([]AllowanceCharge(nil), original.AllowanceCharge...)
+		inv.VAT = append([]VATBreakdown(nil), original.VAT...)
+		inv.Totals = original.Totals
+		inv.Totals.Prepaid = ""
+		inv.Totals.DuePayable = inv.Totals.GrandTotal
+		return inv, nil
+	}
+
+	for _, adjustment := range config.Lines {
+		line, err := findLine(original, adjustment.LineID)
+		if err != nil {
+			return nil, err
+		}
+
+		if adjustment.Quantity != "" {
+			if exceeds(adjustment.Quantity, line.Quantity) {
+				return nil, fmt.Errorf("cannot credit quantity %s of line %s, only %s was invoiced",
+					adjustment.Quantity, line.ID, line.Quantity)
+			}
+			line.Quantity = adjustment.Quantity
+		}
+		if adjustment.NetPrice != "" {
+			if exceeds(adjustment.NetPrice, line.NetPrice) {
+				return nil, fmt.Errorf("cannot credit price %s of line %s, only %s was invoiced",
+					adjustment.NetPrice, line.ID, line.NetPrice)
+			}
+			line.NetPrice = adjustment.NetPrice
+		}
+		if adjustment.Quantity != "" || adjustment.NetPrice != "" {
+			line.Total = ""
+		}
+		inv.Lines = append(inv.Lines, line)
+	}
+
+	inv.VAT = original.VAT
+	if err = inv.Recalculate(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// Correction returns a corrected invoice (384) for original that references it as preceding
+// invoice and contains the difference of each adjusted line to the original, so a reduced
+// quantity or price becomes a negative line and a line corrected to quantity 0 is reversed. A
+// line whose quantity and price both change is reversed and added again with the corrected
+// values. The VAT breakdown and totals are recalculated from the lines.
+func Correction(original *Invoice, config *CorrectionConfig) (*Invoice, error) {
+	inv, err := derive(original, config, TypeCorrection)
+	if err != nil {
+		return nil, err
+	}
+	inv.PaymentMeans = original.PaymentMeans
+
+	if len(config.Lines) == 0 {
+		return nil, fmt.Errorf("a correction needs at least one line adjustment")
+	}
+
+	for _, adjustment := range config.Lines {
+		line, err := findLine(original, adjustment.LineID)
+		if err != nil {
+			return nil, err
+		}
+		line.Total = ""
+
+		quantity, price := adjustment.Quantity, adjustment.NetPrice
+		if quantity == "" {
+			quantity = line.Quantity
+		}
+		if price == "" {
+			price = line.NetPrice
+		}
+
+		sameQuantity, err := equalDecimals(quantity, line.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		samePrice, err := equalDecimals(price, line.NetPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case sameQuantity && samePrice:
+			continue
+		case samePrice:
+			line.Quantity, err = subtract(quantity, line.Quantity)
+			inv.Lines = append(inv.Lines, line)
+		case sameQuantity:
+			// Net prices must not be negative (BR-27), a lower price negates the quantity.
+			line.NetPrice, err = subtract(price, line.NetPrice)
+			if strings.HasPrefix(string(line.NetPrice), "-") {
+				line.NetPrice, line.Quantity = negate(line.NetPrice), negate(line.Quantity)
+			}
+			inv.Lines = append(inv.Lines, line)
+		default:
+			corrected := line
+			corrected.ID = line.ID + ".1"
+			corrected.Quantity, corrected.NetPrice = quantity, price
+			line.Quantity = negate(line.Quantity)
+			inv.Lines = append(inv.Lines, line, corrected)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(inv.Lines) == 0 {
+		return nil, fmt.Errorf("the adjustments do not change invoice %s", original.ID)
+	}
+
+	inv.VAT = original.VAT
+	if err = inv.Recalculate(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// derive copies the parties and references of original into a new document of typeCode.
+func derive(original *Invoice, config *CorrectionConfig, typeCode string) (*Invoice, error) {
+	if config == nil || strings.TrimSpace(config.ID) == "" {
+		return nil, fmt.Errorf("missing document ID")
+	}
+	if original.ID == "" {
+		return nil, fmt.Errorf("the original invoice has no ID to refer to")
+	}
+
+	inv := &Invoice{
+		GuidelineID:    original.GuidelineID,
+		ID:             strings.TrimSpace(config.ID),
+		TypeCode:       typeCode,
+		IssueDate:      config.IssueDate,
+		BuyerReference: original.BuyerReference,
+		OrderReference: original.OrderReference,
+		Seller:         original.Seller,
+		Buyer:          original.Buyer,
+		DeliveryDate:   original.DeliveryDate,
+		Currency:       original.Currency,
+		Preceding:      []Reference{{ID: original.ID, IssueDate: original.IssueDate}},
+	}
+	if inv.IssueDate.IsZero() {
+		now := time.Now()
+		inv.IssueDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if config.Note != "" {
+		inv.Notes = []string{config.Note}
+	}
+
+	if config.Lines != nil && len(original.Lines) == 0 {
+		return nil, fmt.Errorf("invoice %s has no lines to adjust", original.ID)
+	}
+	return inv, nil
+}
+
+func findLine(inv *Invoice, id string) (Line, error) {
+	for _, l := range inv.Lines {
+		if l.ID == id {
+			return l, nil
+		}
+	}
+	return Line{}, fmt.Errorf("invoice %s has no line %q", inv.ID, id)
+}
+
+// Recalculate sets the totals of lines without one (BT-131) to quantity × net price / base
+// quantity and recalculates the VAT breakdown (BG-23) and document totals (BG-22) from the lines,
+// allowances and charges. Exemption reasons of the previous breakdown are kept. Amounts are
+// rounded to two decimals.
+func (inv *Invoice) Recalculate() error {
+	type vatKey struct {
+		category string
+		rate     string
+	}
+	var (
+		keys      []vatKey
+		basis     = make(map[vatKey]*big.Rat)
+		rates     = make(map[vatKey]Decimal)
+		lineTotal = new(big.Rat)
+	)
+	add := func(category string, rate Decimal, amount *big.Rat) error {
+		r, ok := rate.Rat()
+		if !ok {
+			return fmt.Errorf("invalid VAT rate %q", rate)
+		}
+		key := vatKey{category, r.RatString()}
+		if basis[key] == nil {
+			keys = append(keys, key)
+			basis[key] = new(big.Rat)
+			rates[key] = rate
+		}
+		basis[key].Add(basis[key], amount)
+		return nil
+	}
+
+	for i := range inv.Lines {
+		l := &inv.Lines[i]
+		if l.Total == "" {
+			total, err := product(l.Quantity, l.NetPrice, l.BaseQty)
+			if err != nil {
+				return fmt.Errorf("line %s: %w", l.ID, err)
+			}
+			l.Total = amount(total)
+		}
+
+		total, ok := l.Total.Rat()
+		if !ok {
+			return fmt.Errorf("line %s: invalid total %q", l.ID, l.Total)
+		}
+		lineTotal.Add(lineTotal, total)
+		if err := add(l.VATCategory, l.VATRate, total); err != nil {
+			return fmt.Errorf("line %s: %w", l.ID, err)
+		}
+	}
+
+	allowances, charges := new(big.Rat), new(big.Rat)
+	for _, ac := range inv.AllowanceCharge {
+		value, ok := ac.Amount.Rat()
+		if !ok {
+			return fmt.Errorf("invalid allowance or charge amount %q", ac.Amount)
+		}
+		if ac.Charge {
+			charges.Add(charges, value)
+		} else {
+			allowances.Add(allowances, value)
+			value = new(big.Rat).Neg(value)
+		}
+		if err := add(ac.VATCategory, ac.VATRate, value); err != nil {
+			return err
+		}
+	}
+
+	reasons := make(map[string]string)
+	for _, v := range inv.VAT {
+		if r, ok := v.Rate.Rat(); ok {
+			reasons[v.Category+" "+r.RatString()] = v.ExemptionReason
+		}
+	}
+
+	inv.VAT = nil
+	taxTotal := new(big.Rat)
+	for _, key := range keys {
+		rate, _ := rates[key].Rat()
+		tax := new(big.Rat).Mul(basis[key], rate)
+		tax.Quo(tax, big.NewRat(100, 1))
+		tax, _ = amount(tax).Rat()
+		taxTotal.Add(taxTotal, tax)
+
+		inv.VAT = append(inv.VAT, VATBreakdown{
+			Category:        key.category,
+			Rate:            rates[key],
+			BasisAmount:     amount(basis[key]),
+			TaxAmount:       amount(tax),
+			ExemptionReason: reasons[key.category+" "+key.rate],
+		})
+	}
+
+	taxBasis := new(big.Rat).Sub(lineTotal, allowances)
+	taxBasis.Add(taxBasis, charges)
+	grandTotal := new(big.Rat).Add(taxBasis, taxTotal)
+	prepaid, ok := inv.Totals.Prepaid.Rat()
+	if !ok {
+		return fmt.Errorf("invalid prepaid amount %q", inv.Totals.Prepaid)
+	}
+
+	inv.Totals = Totals{
+		LineTotal:      amount(lineTotal),
+		AllowanceTotal: amount(allowances),
+		ChargeTotal:    amount(charges),
+		TaxBasisTotal:  amount(taxBasis),
+		TaxTotal:       amount(taxTotal),
+		GrandTotal:     amount(grandTotal),
+		Prepaid:        inv.Totals.Prepaid,
+		DuePayable:     amount(new(big.Rat).Sub(grandTotal, prepaid)),
+	}
+	return nil
+}
+
+// ValidateCorrection checks the reference rules of a credit note (381) or corrected invoice (384):
+// it refers to at least one preceding invoice (BG-3) by ID, neither itself nor one issued after
+// it, and the amounts of a credit note are not negative. If original is not nil, inv must also
+// refer to it, with its issue date if one is given, and share its currency, seller and buyer; a
+// credit note must not exceed the original grand total. All violations are returned together,
+// each wrapping ErrInvalidCorrection.
+func ValidateCorrection(inv, original *Invoice) error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidCorrection}, args...)...))
+	}
+
+	if inv.TypeCode != TypeCreditNote && inv.TypeCode != TypeCorrection {
+		fail("type code %q is neither a credit note (%s) nor a correction (%s)", inv.TypeCode, TypeCreditNote, TypeCorrection)
+	}
+
+	if len(inv.Preceding) == 0 {
+		fail("no preceding invoice is referenced (BG-3)")
+	}
+	for _, ref := range inv.Preceding {
+		switch {
+		case ref.ID == "":
+			fail("a preceding invoice reference has no ID (BT-25)")
+		case ref.ID == inv.ID:
+			fail("document %s refers to itself as preceding invoice", inv.ID)
+		}
+		if !ref.IssueDate.IsZero() && !inv.IssueDate.IsZero() && ref.IssueDate.After(inv.IssueDate) {
+			fail("preceding invoice %s is issued on %s, after the document on %s",
+				ref.ID, ref.IssueDate.Format(time.DateOnly), inv.IssueDate.Format(time.DateOnly))
+		}
+	}
+
+	grandTotal, ok := inv.Totals.GrandTotal.Rat()
+	if inv.TypeCode == TypeCreditNote && ok && grandTotal.Sign() < 0 {
+		fail("the grand total %s of a credit note must not be negative, the type code already reverses it", inv.Totals.GrandTotal)
+	}
+
+	if original == nil {
+		return errors.Join(errs...)
+	}
+
+	var ref *Reference
+	for i := range inv.Preceding {
+		if inv.Preceding[i].ID == original.ID {
+			ref = &inv.Preceding[i]
+			break
+		}
+	}
+	switch {
+	case ref == nil:
+		fail("invoice %s is not referenced as preceding invoice", original.ID)
+	case !ref.IssueDate.IsZero() && !original.IssueDate.IsZero() && !ref.IssueDate.Equal(original.IssueDate):
+		fail("preceding invoice %s is referenced with issue date %s instead of %s",
+			original.ID, ref.IssueDate.Format(time.DateOnly), original.IssueDate.Format(time.DateOnly))
+	}
+
+	if inv.Currency != original.Currency {
+		fail("currency %s differs from %s of invoice %s", inv.Currency, original.Currency, original.ID)
+	}
+	if !sameParty(inv.Seller, original.Seller) {
+		fail("seller %q differs from %q of invoice %s", inv.Seller.Name, original.Seller.Name, original.ID)
+	}
+	if !sameParty(inv.Buyer, original.Buyer) {
+		fail("buyer %q differs from %q of invoice %s", inv.Buyer.Name, original.Buyer.Name, original.ID)
+	}
+
+	if limit, ok := original.Totals.GrandTotal.Rat(); ok && inv.TypeCode == TypeCreditNote && grandTotal != nil &&
+		grandTotal.Cmp(limit) > 0 {
+		fail("the credit note total %s exceeds the grand total %s of invoice %s",
+			inv.Totals.GrandTotal, original.Totals.GrandTotal, original.ID)
+	}
+
+	return errors.Join(errs...)
+}
+
+// sameParty compares the VAT IDs of both parties if known and their names otherwise.
+func sameParty(a, b Party) bool {
+	if a.VATID != "" && b.VATID != "" {
+		return strings.EqualFold(a.VATID, b.VATID)
+	}
+	return strings.EqualFold(strings.TrimSpace(a.Name), strings.TrimSpace(b.Name))
+}
+
+// product returns quantity × price / base, an empty base counting as 1.
+func product(quantity, price, base Decimal) (*big.Rat, error) {
+	q, ok := quantity.Rat()
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", quantity)
+	}
+	p, ok := price.Rat()
+	if !ok {
+		return nil, fmt.Errorf("invalid net price %q", price)
+	}
+	out := new(big.Rat).Mul(q, p)
+
+	if base != "" {
+		b, ok := base.Rat()
+		if !ok || b.Sign() == 0 {
+			return nil, fmt.Errorf("invalid base quantity %q", base)
+		}
+		out.Quo(out, b)
+	}
+	return out, nil
+}
+
+// amount formats r with two decimals, halves rounded away from zero.
+func amount(r *big.Rat) Decimal {
+	return Decimal(r.FloatString(2))
+}
+
+// exceeds reports whether the absolute value of a exceeds that of b.
+func exceeds(a, b Decimal) bool {
+	x, ok := a.Rat()
+	y, ok2 := b.Rat()
+	return ok && ok2 && new(big.Rat).Abs(x).Cmp(new(big.Rat).Abs(y)) > 0
+}
+
+func equalDecimals(a, b Decimal) (bool, error) {
+	x, ok := a.Rat()
+	if !ok {
+		return false, fmt.Errorf("invalid number %q", a)
+	}
+	y, ok := b.Rat()
+	if !ok {
+		return false, fmt.Errorf("invalid number %q", b)
+	}
+	return x.Cmp(y) == 0, nil
+}
+
+// subtract returns a - b with as many decimals as the more precise of both.
+func subtract(a, b Decimal) (Decimal, error) {
+	x, ok := a.Rat()
+	if !ok {
+		return "", fmt.Errorf("invalid number %q", a)
+	}
+	y, ok := b.Rat()
+	if !ok {
+		return "", fmt.Errorf("invalid number %q", b)
+	}
+	return Decimal(new(big.Rat).Sub(x, y).FloatString(max(a.scale(), b.scale()))), nil
+}
+
+// negate flips the sign of d and keeps its notation.
+func negate(d Decimal) Decimal {
+	s := strings.TrimSpace(string(d))
+	switch {
+	case s == "" || d.IsZero():
+		return Decimal(s)
+	case strings.HasPrefix(s, "-"):
+		return Decimal(s[1:])
+	default:
+		return Decimal("-" + strings.TrimPrefix(s, "+"))
+	}
+}
+
+// scale is the number of decimals of d.
+func (d Decimal) scale() int {
+	s := string(d)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(strings.TrimSpace(s[i+1:]))
+	}
+	return 0
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package cii_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func original() *cii.Invoice {
+	return &cii.Invoice{
+		ID:        "R-1",
+		TypeCode:  "380",
+		IssueDate: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		Seller:    cii.Party{Name: "Lieferant GmbH", VATID: "DE123456789"},
+		Buyer:     cii.Party{Name: "Kunde AG"},
+		Currency:  "EUR",
+		PaymentMeans: []cii.PaymentMeans{
+			{TypeCode: "58", IBAN: "DE02120300000000202051"},
+		},
+		Lines: []cii.Line{
+			{ID: "1", Name: "Apfel", Quantity: "20.0000", NetPrice: "9.90", Total: "198.00", VATCategory: "S", VATRate: "19.00"},
+			{ID: "2", Name: "Birne", Quantity: "50", NetPrice: "5.50", Total: "275.00", VATCategory: "S", VATRate: "7"},
+		},
+		AllowanceCharge: []cii.AllowanceCharge{{Amount: "10.00", VATCategory: "S", VATRate: "19"}},
+		VAT: []cii.VATBreakdown{
+			{Category: "S", Rate: "19.00", BasisAmount: "188.00", TaxAmount: "35.72"},
+			{Category: "S", Rate: "7", BasisAmount: "275.00", TaxAmount: "19.25"},
+		},
+		Totals: cii.Totals{
+			LineTotal: "473.00", AllowanceTotal: "10.00", TaxBasisTotal: "463.00", TaxTotal: "54.97",
+			GrandTotal: "517.97", Prepaid: "100.00", DuePayable: "417.97",
+		},
+	}
+}
+
+func TestCreditNote_Full(t *testing.T) {
+	inv, err := cii.CreditNote(original(), &cii.CorrectionConfig{ID: "G-1", Note: "Storno"})
+	require.NoError(t, err)
+
+	assert.Equal(t, cii.TypeCreditNote, inv.TypeCode)
+	assert.Equal(t, []cii.Reference{{ID: "R-1", IssueDate: original().IssueDate}}, inv.Preceding)
+	assert.Equal(t, []string{"Storno"}, inv.Notes)
+	assert.False(t, inv.IssueDate.IsZero())
+	assert.Equal(t, original().Lines, inv.Lines)
+	assert.Equal(t, original().AllowanceCharge, inv.AllowanceCharge)
+	assert.Empty(t, inv.PaymentMeans)
+
+	// The whole amount is credited regardless of what was prepaid.
+	assert.Equal(t, cii.Decimal("517.97"), inv.Totals.GrandTotal)
+	assert.Equal(t, cii.Decimal(""), inv.Totals.Prepaid)
+	assert.Equal(t, cii.Decimal("517.97"), inv.Totals.DuePayable)
+
+	assert.NoError(t, cii.ValidateCorrection(inv, original()))
+}
+
+func TestCreditNote_Lines(t *testing.T) {
+	inv, err := cii.CreditNote(original(), &cii.CorrectionConfig{
+		ID:    "G-1",
+		Lines: []cii.LineAdjustment{{LineID: "2", Quantity: "10"}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, inv.Lines, 1)
+	assert.Equal(t, cii.Decimal("10"), inv.Lines[0].Quantity)
+	assert.Equal(t, cii.Decimal("55.00"), inv.Lines[0].Total)
+	assert.Empty(t, inv.AllowanceCharge)
+	assert.Equal(t, []cii.VATBreakdown{{Category: "S", Rate: "7", BasisAmount: "55.00", TaxAmount: "3.85"}}, inv.VAT)
+	assert.Equal(t, cii.Totals{
+		LineTotal: "55.00", AllowanceTotal: "0.00", ChargeTotal: "0.00", TaxBasisTotal: "55.00",
+		TaxTotal: "3.85", GrandTotal: "58.85", DuePayable: "58.85",
+	}, inv.Totals)
+	assert.NoError(t, cii.ValidateCorrection(inv, original()))
+
+	_, err = cii.CreditNote(original(), &cii.CorrectionConfig{ID: "G-1", Lines: []cii.LineAdjustment{{LineID: "2", Quantity: "51"}}})
+	assert.ErrorContains(t, err, "only 50 was invoiced")
+
+	_, err = cii.CreditNote(original(), &cii.CorrectionConfig{ID: "G-1", Lines: []cii.LineAdjustment{{LineID: "3"}}})
+	assert.ErrorContains(t, err, `no line "3"`)
+
+	_, err = cii.CreditNote(original(), &cii.CorrectionConfig{})
+	assert.ErrorContains(t, err, "missing document ID")
+}
+
+func TestCorrection(t *testing.T) {
+	inv, err := cii.Correction(original(), &cii.CorrectionConfig{
+		ID:        "K-1",
+		IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		Lines: []cii.LineAdjustment{
+			{LineID: "1", Quantity: "15"},
+			{LineID: "2", NetPrice: "5.00"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, cii.TypeCorrection, inv.TypeCode)
+	assert.Equal(t, original().PaymentMeans, inv.PaymentMeans)
+	require.Len(t, inv.Lines, 2)
+
+	// A lower price keeps the net price positive and negates the quantity instead.
+	assert.Equal(t, cii.Decimal("-5.0000"), inv.Lines[0].Quantity)
+	assert.Equal(t, cii.Decimal("-49.50"), inv.Lines[0].Total)
+	assert.Equal(t, cii.Decimal("-50"), inv.Lines[1].Quantity)
+	assert.Equal(t, cii.Decimal("0.50"), inv.Lines[1].NetPrice)
+	assert.Equal(t, cii.Decimal("-25.00"), inv.Lines[1].Total)
+
+	assert.Equal(t, []cii.VATBreakdown{
+		{Category: "S", Rate: "19.00", BasisAmount: "-49.50", TaxAmount: "-9.41"},
+		{Category: "S", Rate: "7", BasisAmount: "-25.00", TaxAmount: "-1.75"},
+	}, inv.VAT)
+	assert.Equal(t, cii.Decimal("-85.66"), inv.Totals.GrandTotal)
+	assert.NoError(t, cii.ValidateCorrection(inv, original()))
+}
+
+func TestCorrection_QuantityAndPrice(t *testing.T) {
+	inv, err := cii.Correction(original(), &cii.CorrectionConfig{
+		ID:    "K-1",
+		Lines: []cii.LineAdjustment{{LineID: "1", Quantity: "10", NetPrice: "12.00"}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, inv.Lines, 2)
+	assert.Equal(t, "1", inv.Lines[0].ID)
+	assert.Equal(t, cii.Decimal("-198.00"), inv.Lines[0].Total)
+	assert.Equal(t, "1.1", inv.Lines[1].ID)
+	assert.Equal(t, cii.Decimal("120.00"), inv.Lines[1].Total)
+	assert.Equal(t, cii.Decimal("-78.00"), inv.Totals.LineTotal)
+
+	_, err = cii.Correction(original(), &cii.CorrectionConfig{ID: "K-1", Lines: []cii.LineAdjustment{{LineID: "1", Quantity: "20"}}})
+	assert.ErrorContains(t, err, "do not change")
+
+	_, err = cii.Correction(original(), &cii.CorrectionConfig{ID: "K-1"})
+	assert.ErrorContains(t, err, "at least one line")
+}
+
+func TestValidateCorrection(t *testing.T) {
+	inv, err := cii.CreditNote(original(), &cii.CorrectionConfig{ID: "G-1", IssueDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(inv *cii.Invoice)
+		errors []string
+	}{
+		{"type code", func(inv *cii.Invoice) { inv.TypeCode = "380" }, []string{`type code "380"`}},
+		{"no reference", func(inv *cii.Invoice) { inv.Preceding = nil }, []string{"no preceding invoice", "R-1 is not referenced"}},
+		{"self reference", func(inv *cii.Invoice) { inv.Preceding = append(inv.Preceding, cii.Reference{ID: "G-1"}) }, []string{"refers to itself"}},
+		{"issued later", func(inv *cii.Invoice) { inv.IssueDate = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC) }, []string{"after the document"}},
+		{"wrong date", func(inv *cii.Invoice) { inv.Preceding[0].IssueDate = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC) }, []string{"instead of 2024-03-05"}},
+		{"negative", func(inv *cii.Invoice) { inv.Totals.GrandTotal = "-1.00" }, []string{"must not be negative"}},
+		{"exceeds", func(inv *cii.Invoice) { inv.Totals.GrandTotal = "600.00" }, []string{"exceeds the grand total"}},
+		{"parties", func(inv *cii.Invoice) {
+			inv.Currency = "USD"
+			inv.Seller.VATID = "DE999999999"
+			inv.Buyer.Name = "Andere AG"
+		}, []string{"currency USD", "seller", "buyer"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := *inv
+			modified.Preceding = append([]cii.Reference(nil), inv.Preceding...)
+			tt.modify(&modified)
+
+			err := cii.ValidateCorrection(&modified, original())
+			require.ErrorIs(t, err, cii.ErrInvalidCorrection)
+			for _, message := range tt.errors {
+				assert.ErrorContains(t, err, message)
+			}
+		})
+	}
+
+	// Without the original only the reference rules apply.
+	modified := *inv
+	modified.Currency = "USD"
+	assert.NoError(t, cii.ValidateCorrection(&modified, nil))
+}
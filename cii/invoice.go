@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package cii parses the UN/CEFACT Cross Industry Invoice (CII) XML of Factur-X, ZUGFeRD and
+// XRechnung invoices into a flat model of the EN 16931 business terms, writes the model back as
+// XML and derives credit notes and corrected invoices from it.
+package cii
+
+import (
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Decimal is a number as written in the XML, so amounts keep their precision.
+type Decimal string
+
+// Rat returns d as an exact rational number. An empty Decimal is zero.
+func (d Decimal) Rat() (*big.Rat, bool) {
+	if d == "" {
+		return new(big.Rat), true
+	}
+	return new(big.Rat).SetString(strings.TrimSpace(string(d)))
+}
+
+// IsZero reports whether d is empty or equals zero.
+func (d Decimal) IsZero() bool {
+	r, ok := d.Rat()
+	return ok && r.Sign() == 0
+}
+
+// Invoice holds the business terms of a CII invoice, credit note or correction.
+type Invoice struct {
+	GuidelineID     string    // BT-24
+	ID              string    // BT-1
+	TypeCode        string    // BT-3, UNTDID 1001, e.g. 380 invoice or 381 credit note
+	IssueDate       time.Time // BT-2
+	Notes           []string  // BT-22
+	BuyerReference  string    // BT-10
+	OrderReference  string    // BT-13
+	Seller          Party
+	Buyer           Party
+	DeliveryDate    time.Time // BT-72
+	Currency        string    // BT-5
+	PaymentRef      string    // BT-83
+	PaymentMeans    []PaymentMeans
+	PaymentTerms    string    // BT-20
+	DueDate         time.Time // BT-9
+	Preceding       []Reference
+	Lines           []Line
+	AllowanceCharge []AllowanceCharge
+	VAT             []VATBreakdown
+	Totals          Totals
+}
+
+// Party is the seller or buyer.
+type Party struct {
+	ID         string // BT-29 / BT-46
+	Name       string // BT-27 / BT-44
+	VATID      string // BT-31 / BT-48
+	TaxID      string // BT-32
+	Address    Address
+	Contact    string
+	Phone      string
+	Email      string
+	ElectronID string // BT-34 / BT-49
+	// ElectronScheme is the scheme of ElectronID, e.g. "EM" for an e-mail address or "9930" for a
+	// German VAT ID (BT-34-1 / BT-49-1).
+	ElectronScheme string
+}
+
+// Address is a postal address.
+type Address struct {
+	Lines    []string
+	PostCode string
+	City     string
+	Country  string // ISO 3166-1 alpha-2
+}
+
+// PaymentMeans is a way to pay the invoice.
+type PaymentMeans struct {
+	TypeCode    string // BT-81, UNTDID 4461, e.g. 58 SEPA credit transfer
+	Information string // BT-82
+	IBAN        string // BT-84
+	AccountName string // BT-85
+	BIC         string // BT-86
+}
+
+// Reference is a preceding invoice (BG-3).
+type Reference struct {
+	ID        string    // BT-25
+	IssueDate time.Time // BT-26
+}
+
+// Line is an invoice line (BG-25).
+type Line struct {
+	ID          string // BT-126
+	Name        string // BT-153
+	Description string // BT-154
+	SellerID    string // BT-155
+	Quantity    Decimal
+	UnitCode    string // BT-130, UN/ECE Recommendation 20
+	NetPrice    Decimal
+	BaseQty     Decimal // BT-149, the quantity the net price refers to
+	Total       Decimal // BT-131
+	VATCategory string  // BT-151
+	VATRate     Decimal // BT-152
+}
+
+// AllowanceCharge is a document level allowance (BG-20) or charge (BG-21).
+type AllowanceCharge struct {
+	Charge      bool
+	Amount      Decimal
+	Reason      string
+	VATCategory string
+	VATRate     Decimal
+}
+
+// VATBreakdown is the VAT of one category and rate (BG-23).
+type VATBreakdown struct {
+	Category        string  // BT-118
+	Rate            Decimal // BT-119
+	BasisAmount     Decimal // BT-116
+	TaxAmount       Decimal // BT-117
+	ExemptionReason string  // BT-120
+}
+
+// Totals are the document totals (BG-22).
+type Totals struct {
+	LineTotal      Decimal // BT-106
+	AllowanceTotal Decimal // BT-107
+	ChargeTotal    Decimal // BT-108
+	TaxBasisTotal  Decimal // BT-109
+	TaxTotal       Decimal // BT-110, in the invoice currency
+	GrandTotal     Decimal // BT-112
+	Prepaid        Decimal // BT-113
+	DuePayable     Decimal // BT-115
+}
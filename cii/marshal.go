@@ -0,0 +1,428 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package cii
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Marshal writes inv as CII XML in the element order of the D16B schema. Only the business terms
+// of the model are written, so Parse(Marshal(inv)) returns inv, but terms Parse does not read,
+// like gross prices or note subject codes, are lost when re-writing a parsed invoice.
+func Marshal(inv *Invoice) ([]byte, error) {
+	doc := xmlDocument{
+		RSM: rsmNamespace,
+		RAM: "urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100",
+		QDT: "urn:un:unece:uncefact:data:standard:QualifiedDataType:100",
+		UDT: "urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100",
+	}
+	doc.Context.GuidelineID = inv.GuidelineID
+	doc.Document = xmlExchangedDocument{
+		ID:        inv.ID,
+		TypeCode:  inv.TypeCode,
+		IssueDate: date(inv.IssueDate),
+	}
+	for _, note := range inv.Notes {
+		doc.Document.Notes = append(doc.Document.Notes, xmlNote{Content: note})
+	}
+
+	t := &doc.Transaction
+	for _, l := range inv.Lines {
+		t.Lines = append(t.Lines, xmlLineItem{
+			ID: l.ID,
+			Product: xmlProduct{
+				SellerID:    l.SellerID,
+				Name:        l.Name,
+				Description: l.Description,
+			},
+			Price:    price(l),
+			Quantity: quantity(l.Quantity, l.UnitCode),
+			Settlement: xmlLineSettlement{
+				Tax:   tax(l.VATCategory, l.VATRate),
+				Total: l.Total,
+			},
+		})
+	}
+
+	t.Agreement = xmlAgreement{
+		BuyerReference: inv.BuyerReference,
+		Seller:         tradeParty(inv.Seller),
+		Buyer:          tradeParty(inv.Buyer),
+	}
+	if inv.OrderReference != "" {
+		t.Agreement.OrderReference = &xmlReference{ID: inv.OrderReference}
+	}
+
+	if !inv.DeliveryDate.IsZero() {
+		t.Delivery.Date = &xmlOccurrence{Date: date(inv.DeliveryDate)}
+	}
+
+	s := &t.Settlement
+	s.PaymentReference = inv.PaymentRef
+	s.Currency = inv.Currency
+
+	for _, m := range inv.PaymentMeans {
+		means := xmlPaymentMeans{TypeCode: m.TypeCode, Information: m.Information}
+		if m.IBAN != "" || m.AccountName != "" {
+			means.Account = &xmlAccount{IBAN: m.IBAN, AccountName: m.AccountName}
+		}
+		if m.BIC != "" {
+			means.Institution = &xmlInstitution{BIC: m.BIC}
+		}
+		s.PaymentMeans = append(s.PaymentMeans, means)
+	}
+
+	for _, v := range inv.VAT {
+		s.Taxes = append(s.Taxes, xmlHeaderTax{
+			TaxAmount:       v.TaxAmount,
+			TypeCode:        "VAT",
+			ExemptionReason: v.ExemptionReason,
+			BasisAmount:     v.BasisAmount,
+			Category:        v.Category,
+			Rate:            v.Rate,
+		})
+	}
+
+	for _, ac := range inv.AllowanceCharge {
+		s.AllowanceCharges = append(s.AllowanceCharges, xmlAllowanceCharge{
+			Charge: ac.Charge,
+			Amount: ac.Amount,
+			Reason: ac.Reason,
+			Tax:    tax(ac.VATCategory, ac.VATRate),
+		})
+	}
+
+	if inv.PaymentTerms != "" || !inv.DueDate.IsZero() {
+		s.PaymentTerms = &xmlPaymentTerms{Description: inv.PaymentTerms}
+		if !inv.DueDate.IsZero() {
+			s.PaymentTerms.DueDate = &xmlDateTime{DateTime: date(inv.DueDate)}
+		}
+	}
+
+	sum := inv.Totals
+	s.Summation = xmlSummation{
+		LineTotal:      sum.LineTotal,
+		ChargeTotal:    sum.ChargeTotal,
+		AllowanceTotal: sum.AllowanceTotal,
+		TaxBasisTotal:  sum.TaxBasisTotal,
+		GrandTotal:     sum.GrandTotal,
+		Prepaid:        sum.Prepaid,
+		DuePayable:     sum.DuePayable,
+	}
+	if sum.TaxTotal != "" {
+		s.Summation.TaxTotal = &xmlAmount{Currency: inv.Currency, Amount: sum.TaxTotal}
+	}
+
+	for _, p := range inv.Preceding {
+		ref := xmlPrecedingReference{ID: p.ID}
+		if !p.IssueDate.IsZero() {
+			ref.IssueDate = &xmlFormattedDate{DateTime: date(p.IssueDate)}
+		}
+		s.Preceding = append(s.Preceding, ref)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not write XML: %w", err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func tradeParty(p Party) xmlTradeParty {
+	out := xmlTradeParty{ID: p.ID, Name: p.Name}
+
+	if p.Contact != "" || p.Phone != "" || p.Email != "" {
+		out.Contact = &xmlContact{Name: p.Contact}
+		if p.Phone != "" {
+			out.Contact.Phone = &xmlPhone{Number: p.Phone}
+		}
+		if p.Email != "" {
+			out.Contact.Email = &xmlURI{ID: p.Email}
+		}
+	}
+
+	a := p.Address
+	if len(a.Lines) > 0 || a.PostCode != "" || a.City != "" || a.Country != "" {
+		out.Address = &xmlAddress{PostCode: a.PostCode, City: a.City, Country: a.Country}
+		lines := a.Lines
+		if len(lines) > 3 {
+			// The schema has three address lines, the rest goes into the last one.
+			lines = append(lines[:2:2], strings.Join(lines[2:], ", "))
+		}
+		for i, dst := range []*string{&out.Address.LineOne, &out.Address.LineTwo, &out.Address.LineThree} {
+			if i < len(lines) {
+				*dst = lines[i]
+			}
+		}
+	}
+
+	if p.ElectronID != "" {
+		out.ElectronAddress = &xmlURI{ID: p.ElectronID, Scheme: p.ElectronScheme}
+	}
+
+	for _, r := range []struct{ scheme, id string }{{"FC", p.TaxID}, {"VA", p.VATID}} {
+		if r.id != "" {
+			out.TaxRegistrations = append(out.TaxRegistrations, xmlTaxRegistration{ID: xmlSchemeID{Scheme: r.scheme, Value: r.id}})
+		}
+	}
+
+	return out
+}
+
+func tax(category string, rate Decimal) *xmlTax {
+	if category == "" && rate == "" {
+		return nil
+	}
+	return &xmlTax{TypeCode: "VAT", Category: category, Rate: rate}
+}
+
+func price(l Line) *xmlPrice {
+	if l.NetPrice == "" && l.BaseQty == "" {
+		return nil
+	}
+	return &xmlPrice{Amount: l.NetPrice, Quantity: quantity(l.BaseQty, l.UnitCode)}
+}
+
+func quantity(value Decimal, unitCode string) *xmlQuantity {
+	if value == "" {
+		return nil
+	}
+	return &xmlQuantity{UnitCode: unitCode, Value: value}
+}
+
+func date(t time.Time) *xmlDate {
+	if t.IsZero() {
+		return nil
+	}
+	return &xmlDate{Format: "102", Value: t.Format("20060102")}
+}
+
+// The element names carry their prefix, encoding/xml would otherwise declare a namespace on
+// every element.
+
+type xmlDocument struct {
+	XMLName xml.Name `xml:"rsm:CrossIndustryInvoice"`
+	RSM     string   `xml:"xmlns:rsm,attr"`
+	RAM     string   `xml:"xmlns:ram,attr"`
+	QDT     string   `xml:"xmlns:qdt,attr"`
+	UDT     string   `xml:"xmlns:udt,attr"`
+	Context struct {
+		GuidelineID string `xml:"ram:GuidelineSpecifiedDocumentContextParameter>ram:ID"`
+	} `xml:"rsm:ExchangedDocumentContext"`
+	Document    xmlExchangedDocument `xml:"rsm:ExchangedDocument"`
+	Transaction xmlTransaction       `xml:"rsm:SupplyChainTradeTransaction"`
+}
+
+type xmlExchangedDocument struct {
+	ID        string    `xml:"ram:ID"`
+	TypeCode  string    `xml:"ram:TypeCode"`
+	IssueDate *xmlDate  `xml:"ram:IssueDateTime>udt:DateTimeString"`
+	Notes     []xmlNote `xml:"ram:IncludedNote"`
+}
+
+type xmlNote struct {
+	Content string `xml:"ram:Content"`
+}
+
+type xmlTransaction struct {
+	Lines     []xmlLineItem `xml:"ram:IncludedSupplyChainTradeLineItem"`
+	Agreement xmlAgreement  `xml:"ram:ApplicableHeaderTradeAgreement"`
+	Delivery  struct {
+		Date *xmlOccurrence `xml:"ram:ActualDeliverySupplyChainEvent"`
+	} `xml:"ram:ApplicableHeaderTradeDelivery"`
+	Settlement xmlSettlement `xml:"ram:ApplicableHeaderTradeSettlement"`
+}
+
+type xmlLineItem struct {
+	ID         string            `xml:"ram:AssociatedDocumentLineDocument>ram:LineID"`
+	Product    xmlProduct        `xml:"ram:SpecifiedTradeProduct"`
+	Price      *xmlPrice         `xml:"ram:SpecifiedLineTradeAgreement>ram:NetPriceProductTradePrice"`
+	Quantity   *xmlQuantity      `xml:"ram:SpecifiedLineTradeDelivery>ram:BilledQuantity"`
+	Settlement xmlLineSettlement `xml:"ram:SpecifiedLineTradeSettlement"`
+}
+
+type xmlProduct struct {
+	SellerID    string `xml:"ram:SellerAssignedID,omitempty"`
+	Name        string `xml:"ram:Name"`
+	Description string `xml:"ram:Description,omitempty"`
+}
+
+type xmlPrice struct {
+	Amount   Decimal      `xml:"ram:ChargeAmount"`
+	Quantity *xmlQuantity `xml:"ram:BasisQuantity"`
+}
+
+type xmlQuantity struct {
+	UnitCode string  `xml:"unitCode,attr,omitempty"`
+	Value    Decimal `xml:",chardata"`
+}
+
+type xmlLineSettlement struct {
+	Tax   *xmlTax `xml:"ram:ApplicableTradeTax"`
+	Total Decimal `xml:"ram:SpecifiedTradeSettlementLineMonetarySummation>ram:LineTotalAmount,omitempty"`
+}
+
+type xmlTax struct {
+	TypeCode string  `xml:"ram:TypeCode"`
+	Category string  `xml:"ram:CategoryCode,omitempty"`
+	Rate     Decimal `xml:"ram:RateApplicablePercent,omitempty"`
+}
+
+type xmlAgreement struct {
+	BuyerReference string        `xml:"ram:BuyerReference,omitempty"`
+	Seller         xmlTradeParty `xml:"ram:SellerTradeParty"`
+	Buyer          xmlTradeParty `xml:"ram:BuyerTradeParty"`
+	OrderReference *xmlReference `xml:"ram:BuyerOrderReferencedDocument"`
+}
+
+type xmlReference struct {
+	ID string `xml:"ram:IssuerAssignedID"`
+}
+
+type xmlTradeParty struct {
+	ID               string               `xml:"ram:ID,omitempty"`
+	Name             string               `xml:"ram:Name,omitempty"`
+	Contact          *xmlContact          `xml:"ram:DefinedTradeContact"`
+	Address          *xmlAddress          `xml:"ram:PostalTradeAddress"`
+	ElectronAddress  *xmlURI              `xml:"ram:URIUniversalCommunication"`
+	TaxRegistrations []xmlTaxRegistration `xml:"ram:SpecifiedTaxRegistration"`
+}
+
+type xmlContact struct {
+	Name  string    `xml:"ram:PersonName,omitempty"`
+	Phone *xmlPhone `xml:"ram:TelephoneUniversalCommunication"`
+	Email *xmlURI   `xml:"ram:EmailURIUniversalCommunication"`
+}
+
+type xmlPhone struct {
+	Number string `xml:"ram:CompleteNumber"`
+}
+
+type xmlURI struct {
+	ID     string `xml:"ram:URIID"`
+	Scheme string `xml:"-"`
+}
+
+// MarshalXML writes the scheme as attribute of URIID, which encoding/xml cannot express with
+// tags on a nested element.
+func (u xmlURI) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	id := xml.StartElement{Name: xml.Name{Local: "ram:URIID"}}
+	if u.Scheme != "" {
+		id.Attr = []xml.Attr{{Name: xml.Name{Local: "schemeID"}, Value: u.Scheme}}
+	}
+	for _, token := range []xml.Token{start, id, xml.CharData(u.ID), id.End(), start.End()} {
+		if err := e.EncodeToken(token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type xmlAddress struct {
+	PostCode  string `xml:"ram:PostcodeCode,omitempty"`
+	LineOne   string `xml:"ram:LineOne,omitempty"`
+	LineTwo   string `xml:"ram:LineTwo,omitempty"`
+	LineThree string `xml:"ram:LineThree,omitempty"`
+	City      string `xml:"ram:CityName,omitempty"`
+	Country   string `xml:"ram:CountryID,omitempty"`
+}
+
+type xmlTaxRegistration struct {
+	ID xmlSchemeID `xml:"ram:ID"`
+}
+
+type xmlSchemeID struct {
+	Scheme string `xml:"schemeID,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type xmlOccurrence struct {
+	Date *xmlDate `xml:"ram:OccurrenceDateTime>udt:DateTimeString"`
+}
+
+type xmlSettlement struct {
+	PaymentReference string                  `xml:"ram:PaymentReference,omitempty"`
+	Currency         string                  `xml:"ram:InvoiceCurrencyCode"`
+	PaymentMeans     []xmlPaymentMeans       `xml:"ram:SpecifiedTradeSettlementPaymentMeans"`
+	Taxes            []xmlHeaderTax          `xml:"ram:ApplicableTradeTax"`
+	AllowanceCharges []xmlAllowanceCharge    `xml:"ram:SpecifiedTradeAllowanceCharge"`
+	PaymentTerms     *xmlPaymentTerms        `xml:"ram:SpecifiedTradePaymentTerms"`
+	Summation        xmlSummation            `xml:"ram:SpecifiedTradeSettlementHeaderMonetarySummation"`
+	Preceding        []xmlPrecedingReference `xml:"ram:InvoiceReferencedDocument"`
+}
+
+type xmlPaymentMeans struct {
+	TypeCode    string          `xml:"ram:TypeCode"`
+	Information string          `xml:"ram:Information,omitempty"`
+	Account     *xmlAccount     `xml:"ram:PayeePartyCreditorFinancialAccount"`
+	Institution *xmlInstitution `xml:"ram:PayeeSpecifiedCreditorFinancialInstitution"`
+}
+
+type xmlAccount struct {
+	IBAN        string `xml:"ram:IBANID,omitempty"`
+	AccountName string `xml:"ram:AccountName,omitempty"`
+}
+
+type xmlInstitution struct {
+	BIC string `xml:"ram:BICID"`
+}
+
+type xmlHeaderTax struct {
+	TaxAmount       Decimal `xml:"ram:CalculatedAmount"`
+	TypeCode        string  `xml:"ram:TypeCode"`
+	ExemptionReason string  `xml:"ram:ExemptionReason,omitempty"`
+	BasisAmount     Decimal `xml:"ram:BasisAmount"`
+	Category        string  `xml:"ram:CategoryCode"`
+	Rate            Decimal `xml:"ram:RateApplicablePercent,omitempty"`
+}
+
+type xmlAllowanceCharge struct {
+	Charge bool    `xml:"ram:ChargeIndicator>udt:Indicator"`
+	Amount Decimal `xml:"ram:ActualAmount"`
+	Reason string  `xml:"ram:Reason,omitempty"`
+	Tax    *xmlTax `xml:"ram:CategoryTradeTax"`
+}
+
+type xmlPaymentTerms struct {
+	Description string       `xml:"ram:Description,omitempty"`
+	DueDate     *xmlDateTime `xml:"ram:DueDateDateTime"`
+}
+
+type xmlDateTime struct {
+	DateTime *xmlDate `xml:"udt:DateTimeString"`
+}
+
+type xmlSummation struct {
+	LineTotal      Decimal    `xml:"ram:LineTotalAmount,omitempty"`
+	ChargeTotal    Decimal    `xml:"ram:ChargeTotalAmount,omitempty"`
+	AllowanceTotal Decimal    `xml:"ram:AllowanceTotalAmount,omitempty"`
+	TaxBasisTotal  Decimal    `xml:"ram:TaxBasisTotalAmount"`
+	TaxTotal       *xmlAmount `xml:"ram:TaxTotalAmount"`
+	GrandTotal     Decimal    `xml:"ram:GrandTotalAmount"`
+	Prepaid        Decimal    `xml:"ram:TotalPrepaidAmount,omitempty"`
+	DuePayable     Decimal    `xml:"ram:DuePayableAmount"`
+}
+
+type xmlAmount struct {
+	Currency string  `xml:"currencyID,attr,omitempty"`
+	Amount   Decimal `xml:",chardata"`
+}
+
+type xmlPrecedingReference struct {
+	ID        string            `xml:"ram:IssuerAssignedID"`
+	IssueDate *xmlFormattedDate `xml:"ram:FormattedIssueDateTime"`
+}
+
+type xmlFormattedDate struct {
+	DateTime *xmlDate `xml:"qdt:DateTimeString"`
+}
+
+type xmlDate struct {
+	Format string `xml:"format,attr"`
+	Value  string `xml:",chardata"`
+}
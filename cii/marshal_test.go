@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package cii_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+	invoice := &cii.Invoice{
+		GuidelineID: "urn:cen.eu:en16931:2017",
+		ID:          "G-1 & 2",
+		TypeCode:    "381",
+		IssueDate:   time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		Notes:       []string{"Rücksendung <defekt>"},
+		Seller: cii.Party{
+			Name:           "Lieferant GmbH",
+			VATID:          "DE123456789",
+			ElectronID:     "rechnung@lieferant.de",
+			ElectronScheme: "EM",
+			Address:        cii.Address{Lines: []string{"a", "b", "c", "d"}, Country: "DE"},
+		},
+		Buyer:    cii.Party{Name: "Kunde AG", Email: "kunde@example.com"},
+		Currency: "EUR",
+		Lines: []cii.Line{
+			{ID: "1", Name: "Apfel", Quantity: "2", UnitCode: "H87", NetPrice: "1.50", Total: "3.00", VATCategory: "S", VATRate: "19"},
+		},
+		VAT:       []cii.VATBreakdown{{Category: "S", Rate: "19", BasisAmount: "3.00", TaxAmount: "0.57"}},
+		Totals:    cii.Totals{TaxBasisTotal: "3.00", TaxTotal: "0.57", GrandTotal: "3.57", DuePayable: "3.57"},
+		Preceding: []cii.Reference{{ID: "R-1", IssueDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+
+	data, err := cii.Marshal(invoice)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<ram:URIID schemeID="EM">rechnung@lieferant.de</ram:URIID>`)
+	assert.Contains(t, string(data), `<ram:TaxTotalAmount currencyID="EUR">0.57</ram:TaxTotalAmount>`)
+	assert.Contains(t, string(data), `<qdt:DateTimeString format="102">20240201</qdt:DateTimeString>`)
+	assert.NotContains(t, string(data), "ram:GrossPriceProductTradePrice")
+
+	parsed, err := cii.Parse(data)
+	require.NoError(t, err)
+
+	// Four address lines do not fit the three of the schema.
+	invoice.Seller.Address.Lines = []string{"a", "b", "c, d"}
+	assert.Equal(t, invoice, parsed)
+}
+
+func TestMarshal_Testdata(t *testing.T) {
+	files, err := filepath.Glob("../testdata/*/*.pdf")
+	require.NoError(t, err)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			pdf, err := os.Open(file)
+			require.NoError(t, err)
+			defer pdf.Close()
+
+			data, _, err := gopdfattach.Extract(pdf)
+			require.NoError(t, err)
+
+			invoice, err := cii.Parse(data)
+			require.NoError(t, err)
+
+			written, err := cii.Marshal(invoice)
+			require.NoError(t, err)
+
+			parsed, err := cii.Parse(written)
+			require.NoError(t, err)
+			assert.Equal(t, invoice, parsed)
+		})
+	}
+}
@@ -0,0 +1,374 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package cii
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotCII is returned by Parse if the root element is not rsm:CrossIndustryInvoice.
+var ErrNotCII = errors.New("not a CII invoice")
+
+const rsmNamespace = "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100"
+
+// Parse reads a CII invoice. Elements that are missing are left empty; Parse does not validate.
+func Parse(data []byte) (*Invoice, error) {
+	var doc document
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("could not parse XML: %w", err)
+	}
+
+	if doc.XMLName.Local != "CrossIndustryInvoice" || doc.XMLName.Space != rsmNamespace {
+		return nil, fmt.Errorf("%w: root element is %s", ErrNotCII, doc.XMLName.Local)
+	}
+
+	return doc.invoice()
+}
+
+// charsetReader accepts ISO-8859-1 besides UTF-8, the only other encoding seen in practice.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+}
+
+type document struct {
+	XMLName     xml.Name
+	GuidelineID string    `xml:"ExchangedDocumentContext>GuidelineSpecifiedDocumentContextParameter>ID"`
+	ID          string    `xml:"ExchangedDocument>ID"`
+	TypeCode    string    `xml:"ExchangedDocument>TypeCode"`
+	IssueDate   dateTime  `xml:"ExchangedDocument>IssueDateTime>DateTimeString"`
+	Notes       []string  `xml:"ExchangedDocument>IncludedNote>Content"`
+	Transaction tradeInfo `xml:"SupplyChainTradeTransaction"`
+}
+
+type tradeInfo struct {
+	Lines     []lineItem `xml:"IncludedSupplyChainTradeLineItem"`
+	Agreement struct {
+		BuyerReference string `xml:"BuyerReference"`
+		Seller         party  `xml:"SellerTradeParty"`
+		Buyer          party  `xml:"BuyerTradeParty"`
+		OrderReference string `xml:"BuyerOrderReferencedDocument>IssuerAssignedID"`
+	} `xml:"ApplicableHeaderTradeAgreement"`
+	Delivery struct {
+		Date dateTime `xml:"ActualDeliverySupplyChainEvent>OccurrenceDateTime>DateTimeString"`
+	} `xml:"ApplicableHeaderTradeDelivery"`
+	Settlement struct {
+		PaymentReference string `xml:"PaymentReference"`
+		Currency         string `xml:"InvoiceCurrencyCode"`
+		PaymentMeans     []struct {
+			TypeCode    string `xml:"TypeCode"`
+			Information string `xml:"Information"`
+			IBAN        string `xml:"PayeePartyCreditorFinancialAccount>IBANID"`
+			AccountName string `xml:"PayeePartyCreditorFinancialAccount>AccountName"`
+			BIC         string `xml:"PayeeSpecifiedCreditorFinancialInstitution>BICID"`
+		} `xml:"SpecifiedTradeSettlementPaymentMeans"`
+		Taxes []struct {
+			TaxAmount       Decimal `xml:"CalculatedAmount"`
+			ExemptionReason string  `xml:"ExemptionReason"`
+			BasisAmount     Decimal `xml:"BasisAmount"`
+			Category        string  `xml:"CategoryCode"`
+			Rate            Decimal `xml:"RateApplicablePercent"`
+		} `xml:"ApplicableTradeTax"`
+		AllowanceCharges []struct {
+			Charge      bool    `xml:"ChargeIndicator>Indicator"`
+			Amount      Decimal `xml:"ActualAmount"`
+			Reason      string  `xml:"Reason"`
+			VATCategory string  `xml:"CategoryTradeTax>CategoryCode"`
+			VATRate     Decimal `xml:"CategoryTradeTax>RateApplicablePercent"`
+		} `xml:"SpecifiedTradeAllowanceCharge"`
+		PaymentTerms []struct {
+			Description string   `xml:"Description"`
+			DueDate     dateTime `xml:"DueDateDateTime>DateTimeString"`
+		} `xml:"SpecifiedTradePaymentTerms"`
+		Summation struct {
+			LineTotal      Decimal `xml:"LineTotalAmount"`
+			ChargeTotal    Decimal `xml:"ChargeTotalAmount"`
+			AllowanceTotal Decimal `xml:"AllowanceTotalAmount"`
+			TaxBasisTotal  Decimal `xml:"TaxBasisTotalAmount"`
+			TaxTotal       []struct {
+				Currency string  `xml:"currencyID,attr"`
+				Amount   Decimal `xml:",chardata"`
+			} `xml:"TaxTotalAmount"`
+			GrandTotal Decimal `xml:"GrandTotalAmount"`
+			Prepaid    Decimal `xml:"TotalPrepaidAmount"`
+			DuePayable Decimal `xml:"DuePayableAmount"`
+		} `xml:"SpecifiedTradeSettlementHeaderMonetarySummation"`
+		Preceding []struct {
+			ID        string   `xml:"IssuerAssignedID"`
+			IssueDate dateTime `xml:"FormattedIssueDateTime>DateTimeString"`
+		} `xml:"InvoiceReferencedDocument"`
+	} `xml:"ApplicableHeaderTradeSettlement"`
+}
+
+type lineItem struct {
+	ID      string `xml:"AssociatedDocumentLineDocument>LineID"`
+	Product struct {
+		SellerID    string `xml:"SellerAssignedID"`
+		Name        string `xml:"Name"`
+		Description string `xml:"Description"`
+	} `xml:"SpecifiedTradeProduct"`
+	NetPrice struct {
+		Amount   Decimal `xml:"ChargeAmount"`
+		Quantity Decimal `xml:"BasisQuantity"`
+	} `xml:"SpecifiedLineTradeAgreement>NetPriceProductTradePrice"`
+	Quantity struct {
+		UnitCode string  `xml:"unitCode,attr"`
+		Value    Decimal `xml:",chardata"`
+	} `xml:"SpecifiedLineTradeDelivery>BilledQuantity"`
+	Settlement struct {
+		VATCategory string  `xml:"ApplicableTradeTax>CategoryCode"`
+		VATRate     Decimal `xml:"ApplicableTradeTax>RateApplicablePercent"`
+		Total       Decimal `xml:"SpecifiedTradeSettlementLineMonetarySummation>LineTotalAmount"`
+	} `xml:"SpecifiedLineTradeSettlement"`
+}
+
+type party struct {
+	ID         []string `xml:"ID"`
+	GlobalID   []string `xml:"GlobalID"`
+	Name       string   `xml:"Name"`
+	Contact    string   `xml:"DefinedTradeContact>PersonName"`
+	Phone      string   `xml:"DefinedTradeContact>TelephoneUniversalCommunication>CompleteNumber"`
+	Email      string   `xml:"DefinedTradeContact>EmailURIUniversalCommunication>URIID"`
+	ElectronID struct {
+		Scheme string `xml:"schemeID,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"URIUniversalCommunication>URIID"`
+	Address struct {
+		PostCode  string `xml:"PostcodeCode"`
+		LineOne   string `xml:"LineOne"`
+		LineTwo   string `xml:"LineTwo"`
+		LineThree string `xml:"LineThree"`
+		City      string `xml:"CityName"`
+		Country   string `xml:"CountryID"`
+	} `xml:"PostalTradeAddress"`
+	TaxRegistrations []struct {
+		ID struct {
+			Scheme string `xml:"schemeID,attr"`
+			Value  string `xml:",chardata"`
+		} `xml:"ID"`
+	} `xml:"SpecifiedTaxRegistration"`
+}
+
+// dateTime is a udt:DateTimeString, in practice always format 102 (YYYYMMDD).
+type dateTime struct {
+	Format string `xml:"format,attr"`
+	Value  string `xml:",chardata"`
+}
+
+func (d dateTime) time() (time.Time, error) {
+	value := strings.TrimSpace(d.Value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	layout := "20060102"
+	switch d.Format {
+	case "", "102":
+	case "610":
+		layout = "200601"
+	case "616":
+		// Year and week number are not a date, the value is kept out of the model.
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported date format %q", d.Format)
+	}
+
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse date %q: %w", value, err)
+	}
+	return t, nil
+}
+
+func (doc *document) invoice() (*Invoice, error) {
+	t := &doc.Transaction
+	s := &t.Settlement
+
+	out := &Invoice{
+		GuidelineID:    strings.TrimSpace(doc.GuidelineID),
+		ID:             strings.TrimSpace(doc.ID),
+		TypeCode:       strings.TrimSpace(doc.TypeCode),
+		BuyerReference: strings.TrimSpace(t.Agreement.BuyerReference),
+		OrderReference: strings.TrimSpace(t.Agreement.OrderReference),
+		Seller:         t.Agreement.Seller.party(),
+		Buyer:          t.Agreement.Buyer.party(),
+		Currency:       strings.TrimSpace(s.Currency),
+		PaymentRef:     strings.TrimSpace(s.PaymentReference),
+	}
+
+	var err error
+	dates := []struct {
+		dst *time.Time
+		src dateTime
+	}{
+		{&out.IssueDate, doc.IssueDate},
+		{&out.DeliveryDate, t.Delivery.Date},
+	}
+	for _, d := range dates {
+		if *d.dst, err = d.src.time(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, note := range doc.Notes {
+		if note = strings.TrimSpace(note); note != "" {
+			out.Notes = append(out.Notes, note)
+		}
+	}
+
+	for _, m := range s.PaymentMeans {
+		out.PaymentMeans = append(out.PaymentMeans, PaymentMeans{
+			TypeCode:    strings.TrimSpace(m.TypeCode),
+			Information: strings.TrimSpace(m.Information),
+			IBAN:        strings.TrimSpace(m.IBAN),
+			AccountName: strings.TrimSpace(m.AccountName),
+			BIC:         strings.TrimSpace(m.BIC),
+		})
+	}
+
+	var terms []string
+	for _, term := range s.PaymentTerms {
+		if description := strings.TrimSpace(term.Description); description != "" {
+			terms = append(terms, description)
+		}
+		if out.DueDate.IsZero() {
+			if out.DueDate, err = term.DueDate.time(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	out.PaymentTerms = strings.Join(terms, "\n")
+
+	for _, p := range s.Preceding {
+		ref := Reference{ID: strings.TrimSpace(p.ID)}
+		if ref.IssueDate, err = p.IssueDate.time(); err != nil {
+			return nil, err
+		}
+		out.Preceding = append(out.Preceding, ref)
+	}
+
+	for _, l := range t.Lines {
+		out.Lines = append(out.Lines, Line{
+			ID:          strings.TrimSpace(l.ID),
+			Name:        strings.TrimSpace(l.Product.Name),
+			Description: strings.TrimSpace(l.Product.Description),
+			SellerID:    strings.TrimSpace(l.Product.SellerID),
+			Quantity:    l.Quantity.Value.trim(),
+			UnitCode:    strings.TrimSpace(l.Quantity.UnitCode),
+			NetPrice:    l.NetPrice.Amount.trim(),
+			BaseQty:     l.NetPrice.Quantity.trim(),
+			Total:       l.Settlement.Total.trim(),
+			VATCategory: strings.TrimSpace(l.Settlement.VATCategory),
+			VATRate:     l.Settlement.VATRate.trim(),
+		})
+	}
+
+	for _, ac := range s.AllowanceCharges {
+		out.AllowanceCharge = append(out.AllowanceCharge, AllowanceCharge{
+			Charge:      ac.Charge,
+			Amount:      ac.Amount.trim(),
+			Reason:      strings.TrimSpace(ac.Reason),
+			VATCategory: strings.TrimSpace(ac.VATCategory),
+			VATRate:     ac.VATRate.trim(),
+		})
+	}
+
+	for _, tax := range s.Taxes {
+		out.VAT = append(out.VAT, VATBreakdown{
+			Category:        strings.TrimSpace(tax.Category),
+			Rate:            tax.Rate.trim(),
+			BasisAmount:     tax.BasisAmount.trim(),
+			TaxAmount:       tax.TaxAmount.trim(),
+			ExemptionReason: strings.TrimSpace(tax.ExemptionReason),
+		})
+	}
+
+	sum := &s.Summation
+	out.Totals = Totals{
+		LineTotal:      sum.LineTotal.trim(),
+		AllowanceTotal: sum.AllowanceTotal.trim(),
+		ChargeTotal:    sum.ChargeTotal.trim(),
+		TaxBasisTotal:  sum.TaxBasisTotal.trim(),
+		GrandTotal:     sum.GrandTotal.trim(),
+		Prepaid:        sum.Prepaid.trim(),
+		DuePayable:     sum.DuePayable.trim(),
+	}
+
+	// BT-111 repeats the tax total in the accounting currency.
+	for _, total := range sum.TaxTotal {
+		if total.Currency == "" || total.Currency == out.Currency || out.Totals.TaxTotal == "" {
+			out.Totals.TaxTotal = total.Amount.trim()
+		}
+		if total.Currency == out.Currency {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+func (d Decimal) trim() Decimal {
+	return Decimal(strings.TrimSpace(string(d)))
+}
+
+func (p party) party() Party {
+	out := Party{
+		Name:           strings.TrimSpace(p.Name),
+		Contact:        strings.TrimSpace(p.Contact),
+		Phone:          strings.TrimSpace(p.Phone),
+		Email:          strings.TrimSpace(p.Email),
+		ElectronID:     strings.TrimSpace(p.ElectronID.Value),
+		ElectronScheme: strings.TrimSpace(p.ElectronID.Scheme),
+		Address: Address{
+			PostCode: strings.TrimSpace(p.Address.PostCode),
+			City:     strings.TrimSpace(p.Address.City),
+			Country:  strings.TrimSpace(p.Address.Country),
+		},
+	}
+
+	for _, ids := range [][]string{p.ID, p.GlobalID} {
+		if out.ID == "" && len(ids) > 0 {
+			out.ID = strings.TrimSpace(ids[0])
+		}
+	}
+
+	for _, line := range []string{p.Address.LineOne, p.Address.LineTwo, p.Address.LineThree} {
+		if line = strings.TrimSpace(line); line != "" {
+			out.Address.Lines = append(out.Address.Lines, line)
+		}
+	}
+
+	for _, registration := range p.TaxRegistrations {
+		switch value := strings.TrimSpace(registration.ID.Value); registration.ID.Scheme {
+		case "VA":
+			out.VATID = value
+		case "FC":
+			out.TaxID = value
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package cii_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	data, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	invoice, err := cii.Parse(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "urn:cen.eu:en16931:2017#compliant#urn:factur-x.eu:1p0:basic", invoice.GuidelineID)
+	assert.Equal(t, "471102", invoice.ID)
+	assert.Equal(t, "380", invoice.TypeCode)
+	assert.Equal(t, time.Date(2020, 3, 5, 0, 0, 0, 0, time.UTC), invoice.IssueDate)
+	assert.Len(t, invoice.Notes, 3)
+	assert.Equal(t, "EUR", invoice.Currency)
+
+	assert.Equal(t, "Lieferant GmbH", invoice.Seller.Name)
+	assert.Equal(t, "DE123456789", invoice.Seller.VATID)
+	assert.Equal(t, "201/113/40209", invoice.Seller.TaxID)
+	assert.Equal(t, cii.Address{Lines: []string{"Lieferantenstraße 20"}, PostCode: "80333", City: "München", Country: "DE"},
+		invoice.Seller.Address)
+
+	require.NotEmpty(t, invoice.Lines)
+	line := invoice.Lines[0]
+	assert.Equal(t, "1", line.ID)
+	assert.Equal(t, cii.Decimal("20.0000"), line.Quantity)
+	assert.Equal(t, "H87", line.UnitCode)
+	assert.Equal(t, cii.Decimal("9.90"), line.NetPrice)
+	assert.Equal(t, cii.Decimal("198.00"), line.Total)
+	assert.Equal(t, "S", line.VATCategory)
+
+	require.NotEmpty(t, invoice.VAT)
+	assert.NotEmpty(t, invoice.Totals.GrandTotal)
+	assert.NotEmpty(t, invoice.Totals.TaxTotal)
+}
+
+func TestParse_Testdata(t *testing.T) {
+	files, err := filepath.Glob("../testdata/*/*.pdf")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			pdf, err := os.Open(file)
+			require.NoError(t, err)
+			defer pdf.Close()
+
+			data, _, err := gopdfattach.Extract(pdf)
+			require.NoError(t, err)
+
+			invoice, err := cii.Parse(data)
+			require.NoError(t, err)
+			assert.NotEmpty(t, invoice.ID)
+			assert.NotEmpty(t, invoice.Seller.Name)
+			assert.NotEmpty(t, invoice.Totals.DuePayable)
+		})
+	}
+}
+
+func TestParse_NotCII(t *testing.T) {
+	_, err := cii.Parse([]byte(`<Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"/>`))
+	assert.ErrorIs(t, err, cii.ErrNotCII)
+
+	_, err = cii.Parse([]byte(`<rsm:CrossIndustryInvoice`))
+	assert.Error(t, err)
+}
+
+func TestDecimal(t *testing.T) {
+	r, ok := cii.Decimal("19.250").Rat()
+	require.True(t, ok)
+	assert.Equal(t, "77/4", r.String())
+
+	assert.True(t, cii.Decimal("").IsZero())
+	assert.True(t, cii.Decimal("0.00").IsZero())
+	assert.False(t, cii.Decimal("abc").IsZero())
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Command gopdfattach-server serves the attach, extract and validate functions of gopdfattach over HTTP.
+// See /openapi.yaml of a running server for the API description.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	maxRequestSize := flag.Int64("max-request-size", 32<<20, "maximum request body size in bytes")
+	timeout := flag.Duration("timeout", 60*time.Second, "maximum processing time per request")
+	flag.Parse()
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: server.New(&server.Options{
+			MaxRequestSize: *maxRequestSize,
+			Timeout:        *timeout,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       *timeout,
+		WriteTimeout:      *timeout + 10*time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Println("listening on", *addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalln("could not start server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	// Let running requests finish before exiting.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("could not shut down server:", err)
+	}
+}
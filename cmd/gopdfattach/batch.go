@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/batch"
+)
+
+func runBatch(args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	pdfDir := flags.String("pdf-dir", "", "directory with the PDF files")
+	xmlDir := flags.String("xml-dir", "", "directory with the XML files")
+	outDir := flags.String("out-dir", "", "directory the hybrid PDFs are written to")
+	fileType := flags.String("type", "facturx", `"facturx" or "zugferd"`)
+	workers := flags.Int("workers", 0, "number of files processed concurrently, defaults to the number of CPUs")
+	documentType := flags.String("document-type", "", "document type, defaults to INVOICE")
+	conformanceLevel := flags.String("conformance-level", "", `conformance level, defaults to "EN 16931"`)
+	version := flags.String("version", "", "version of the standard")
+	incremental := flags.Bool("incremental", false, "append the attachment as incremental update")
+	_ = flags.Parse(args)
+
+	if *pdfDir == "" || *xmlDir == "" || *outDir == "" {
+		flags.Usage()
+		return fmt.Errorf("-pdf-dir, -xml-dir and -out-dir are required")
+	}
+
+	var t string
+	switch *fileType {
+	case "facturx", "factur-x":
+		t = gopdfattach.FileTypeFacturX
+	case "zugferd":
+		t = gopdfattach.FileTypeZugferd
+	default:
+		return fmt.Errorf("unknown type %q", *fileType)
+	}
+
+	config := &gopdfattach.AttachConfig{
+		DocumentType:     *documentType,
+		ConformanceLevel: *conformanceLevel,
+		Version:          *version,
+		Incremental:      *incremental,
+		Limits:           gopdfattach.DefaultLimits,
+	}
+
+	// Fail early instead of reporting the same error for every file.
+	if err := config.Validate(t); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return fmt.Errorf("could not create output directory: %w", err)
+	}
+
+	pairs, unmatched, err := batch.PairFiles(*pdfDir, *xmlDir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range unmatched {
+		fmt.Fprintln(os.Stderr, "skipped", file+": no file with the same basename")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	jobs := make(chan batch.Job)
+	go func() {
+		defer close(jobs)
+		for _, pair := range pairs {
+			job := pair.Job(*outDir)
+			job.FileType = t
+			job.Config = config
+
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var processed, failed int
+	for result := range batch.Run(ctx, jobs, &batch.Options{Workers: *workers}) {
+		processed++
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "error %s: %v\n", result.Job.ID, result.Err)
+			continue
+		}
+		fmt.Printf("ok %s (%s)\n", result.Job.ID, result.Duration.Round(time.Millisecond))
+	}
+
+	fmt.Printf("%d processed, %d failed, %d skipped\n", processed, failed, len(unmatched))
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, processed)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/cii"
+)
+
+// lineFlags collects repeated -line flags of the form ID=QUANTITY, ID=QUANTITY@PRICE or ID=@PRICE.
+type lineFlags []cii.LineAdjustment
+
+func (l *lineFlags) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *lineFlags) Set(value string) error {
+	id, adjustment, ok := strings.Cut(value, "=")
+	if !ok || id == "" {
+		return fmt.Errorf("expected ID=QUANTITY[@PRICE], got %q", value)
+	}
+	quantity, price, _ := strings.Cut(adjustment, "@")
+	*l = append(*l, cii.LineAdjustment{LineID: id, Quantity: cii.Decimal(quantity), NetPrice: cii.Decimal(price)})
+	return nil
+}
+
+func runCredit(args []string) error {
+	var lines lineFlags
+
+	flags := flag.NewFlagSet("credit", flag.ExitOnError)
+	out := flags.String("o", "", "file the credit note or correction is written to")
+	id := flags.String("id", "", "document number of the credit note or correction")
+	date := flags.String("date", "", "issue date as YYYY-MM-DD, defaults to today")
+	note := flags.String("note", "", "note on the document, e.g. the reason")
+	correction := flags.Bool("correction", false, "write a corrected invoice (384) with the line differences instead of a credit note (381)")
+	password := flags.String("password", "", "user or owner password of an encrypted PDF")
+	flags.Var(&lines, "line", "line to credit or correct as ID=QUANTITY[@PRICE], repeatable; without it a credit note covers all lines")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 || *out == "" || *id == "" {
+		flags.Usage()
+		return fmt.Errorf("expected -o, -id and exactly one hybrid PDF file")
+	}
+
+	config := &cii.CorrectionConfig{ID: *id, Note: *note, Lines: lines}
+	if *date != "" {
+		issued, err := time.Parse(time.DateOnly, *date)
+		if err != nil {
+			return fmt.Errorf("invalid -date: %w", err)
+		}
+		config.IssueDate = issued
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	xml, _, err := gopdfattach.ExtractWithConfig(file, &gopdfattach.ExtractConfig{
+		UserPassword:  *password,
+		OwnerPassword: *password,
+		Limits:        gopdfattach.DefaultLimits,
+	})
+	if err != nil {
+		return err
+	}
+
+	original, err := cii.Parse(xml)
+	if err != nil {
+		return err
+	}
+
+	create := cii.CreditNote
+	if *correction {
+		create = cii.Correction
+	}
+	document, err := create(original, config)
+	if err != nil {
+		return err
+	}
+
+	pdf, err := gopdfattach.RenderCorrection(document, original, &gopdfattach.AttachConfig{Limits: gopdfattach.DefaultLimits})
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(*out, pdf, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s over %s %s for invoice %s, written to %s\n",
+		document.TypeCode, document.ID, document.Totals.GrandTotal, document.Currency, original.ID, *out)
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MarlinKuhn/gopdfattach"
+)
+
+func runCrossCheck(args []string) error {
+	flags := flag.NewFlagSet("crosscheck", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "write the report as JSON")
+	password := flags.String("password", "", "user or owner password of an encrypted PDF")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one PDF file")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := gopdfattach.CrossCheck(file, &gopdfattach.ExtractConfig{
+		UserPassword:  *password,
+		OwnerPassword: *password,
+		Limits:        gopdfattach.DefaultLimits,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if n := len(report.Mismatches()); n > 0 {
+		return fmt.Errorf("%d XML values are not shown in the PDF", n)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/diff"
+)
+
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "write the changes as JSON")
+	password := flags.String("password", "", "user or owner password of encrypted PDFs")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("expected the original and the new invoice, each a hybrid PDF or CII XML")
+	}
+
+	config := &gopdfattach.ExtractConfig{
+		UserPassword:  *password,
+		OwnerPassword: *password,
+		Limits:        gopdfattach.DefaultLimits,
+	}
+
+	before, err := invoiceXML(flags.Arg(0), config)
+	if err != nil {
+		return err
+	}
+	after, err := invoiceXML(flags.Arg(1), config)
+	if err != nil {
+		return err
+	}
+
+	result, err := diff.XML(before, after)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	return result.WriteText(os.Stdout)
+}
+
+// invoiceXML reads a CII XML file or extracts it from a hybrid PDF.
+func invoiceXML(path string, config *gopdfattach.ExtractConfig) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return data, nil
+	}
+
+	xml, _, err := gopdfattach.ExtractWithConfig(bytes.NewReader(data), config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return xml, nil
+}
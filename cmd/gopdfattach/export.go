@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/export"
+)
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "json", `"json", "csv" or "datev"`)
+	output := flags.String("o", "", "file the export is written to, defaults to stdout")
+	password := flags.String("password", "", "user or owner password of encrypted PDFs")
+	comma := flags.String("comma", ",", "CSV field separator")
+	decimalComma := flags.Bool("decimal-comma", false, "write CSV numbers with a decimal comma")
+	consultant := flags.Int("consultant", 0, "DATEV consultant number (Beraternummer)")
+	client := flags.Int("client", 0, "DATEV client number (Mandantennummer)")
+	fiscalYear := flags.String("fiscal-year-start", "", "DATEV fiscal year start as YYYY-MM-DD, defaults to January 1")
+	account := flags.String("account", "", `DATEV account the invoices are booked on, defaults to "10000"`)
+	accounts := flags.String("accounts", "", "JSON file with the DATEV account mapping by VAT category, defaults to SKR03 revenue accounts")
+	description := flags.String("description", "", "DATEV batch description")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one directory")
+	}
+
+	var write func(w io.Writer, records []export.Record) error
+	switch *format {
+	case "json":
+		write = export.WriteJSON
+	case "csv":
+		r, size := utf8.DecodeRuneInString(*comma)
+		if size == 0 || size != len(*comma) {
+			return fmt.Errorf("-comma must be a single character")
+		}
+		config := &export.CSVConfig{Comma: r, DecimalComma: *decimalComma}
+		write = func(w io.Writer, records []export.Record) error { return export.WriteCSV(w, records, config) }
+	case "datev":
+		config := &export.DATEVConfig{
+			ConsultantNumber: *consultant,
+			ClientNumber:     *client,
+			Account:          *account,
+			Description:      *description,
+		}
+		if *fiscalYear != "" {
+			start, err := time.Parse(time.DateOnly, *fiscalYear)
+			if err != nil {
+				return fmt.Errorf("invalid -fiscal-year-start: %w", err)
+			}
+			config.FiscalYearStart = start
+		}
+		if *accounts != "" {
+			data, err := os.ReadFile(*accounts)
+			if err != nil {
+				return err
+			}
+			if err = json.Unmarshal(data, &config.Accounts); err != nil {
+				return fmt.Errorf("could not parse account mapping: %w", err)
+			}
+		}
+		write = func(w io.Writer, records []export.Record) error { return export.WriteDATEV(w, records, config) }
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	records, err := readRecords(flags.Arg(0), *password)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return write(os.Stdout, records)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err = write(file, records); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readRecords reads the invoices of the PDFs in dir in name order. PDFs that are no hybrid CII
+// invoices are reported and skipped.
+func readRecords(dir, password string) ([]export.Record, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &gopdfattach.ExtractConfig{
+		UserPassword:  password,
+		OwnerPassword: password,
+		Limits:        gopdfattach.DefaultLimits,
+	}
+
+	var records []export.Record
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".pdf") {
+			continue
+		}
+
+		record, err := readRecord(filepath.Join(dir, entry.Name()), config)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "skipped", entry.Name()+":", err)
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func readRecord(path string, config *gopdfattach.ExtractConfig) (export.Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return export.Record{}, err
+	}
+	defer file.Close()
+
+	return export.Read(file, filepath.Base(path), config)
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MarlinKuhn/gopdfattach"
+)
+
+func runInspect(args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "write the report as JSON")
+	password := flags.String("password", "", "user or owner password of an encrypted PDF")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one PDF file")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := gopdfattach.Inspect(file, &gopdfattach.ExtractConfig{
+		UserPassword:  *password,
+		OwnerPassword: *password,
+		Limits:        gopdfattach.DefaultLimits,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	return report.WriteText(os.Stdout)
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Command gopdfattach processes Factur-X / ZUGFeRD invoices from the command line.
+//
+//	gopdfattach batch -pdf-dir pdfs -xml-dir xmls -out-dir out
+//	gopdfattach credit -id G-1 [-correction] [-line 2=10] -o credit.pdf invoice.pdf
+//	gopdfattach crosscheck [-json] invoice.pdf
+//	gopdfattach diff [-json] original.pdf corrected.pdf
+//	gopdfattach export -format csv -o invoices.csv pdfs
+//	gopdfattach inspect [-json] invoice.pdf
+//	gopdfattach repair -o fixed.pdf invoice.pdf
+//	gopdfattach upgrade -o upgraded.pdf invoice.pdf
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "batch", usage: "attach XML files to the PDF files with the same basename", run: runBatch},
+	{name: "credit", usage: "create a credit note or corrected invoice for a hybrid invoice", run: runCredit},
+	{name: "crosscheck", usage: "check that the PDF shows the key values of its invoice XML", run: runCrossCheck},
+	{name: "diff", usage: "compare two invoices, hybrid PDFs or CII XML, on their business terms", run: runDiff},
+	{name: "export", usage: "export the invoices of a directory of hybrid PDFs as JSON, CSV or DATEV", run: runExport},
+	{name: "inspect", usage: "report the hybrid invoice structure of a PDF", run: runInspect},
+	{name: "repair", usage: "fix the metadata and attachment of a non-compliant hybrid invoice", run: runRepair},
+	{name: "upgrade", usage: "rewrite ZUGFeRD 2.0 metadata as current Factur-X/ZUGFeRD metadata", run: runUpgrade},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+
+		if err := cmd.run(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "gopdfattach:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gopdfattach <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MarlinKuhn/gopdfattach"
+)
+
+func runRepair(args []string) error {
+	flags := flag.NewFlagSet("repair", flag.ExitOnError)
+	out := flags.String("o", "", "file the repaired PDF is written to")
+	password := flags.String("password", "", "user or owner password of an encrypted PDF")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 || *out == "" {
+		flags.Usage()
+		return fmt.Errorf("expected -o and exactly one PDF file")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	pdf, fixes, err := gopdfattach.Repair(file, &gopdfattach.RepairConfig{
+		UserPassword:  *password,
+		OwnerPassword: *password,
+		Limits:        gopdfattach.DefaultLimits,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(*out, pdf, 0o644); err != nil {
+		return err
+	}
+
+	for _, fix := range fixes {
+		fmt.Printf("fixed %s: %s\n", fix.Problem, fix.Description)
+	}
+	fmt.Printf("%d fixes, written to %s\n", len(fixes), *out)
+
+	return nil
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MarlinKuhn/gopdfattach"
+)
+
+func runUpgrade(args []string) error {
+	flags := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	out := flags.String("o", "", "file the upgraded PDF is written to")
+	password := flags.String("password", "", "user or owner password of an encrypted PDF")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 || *out == "" {
+		flags.Usage()
+		return fmt.Errorf("expected -o and exactly one PDF file")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	pdf, err := gopdfattach.Upgrade(file, &gopdfattach.RepairConfig{
+		UserPassword:  *password,
+		OwnerPassword: *password,
+		Limits:        gopdfattach.DefaultLimits,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(*out, pdf, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("upgraded, written to %s\n", *out)
+
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract_ConsistentTestdata(t *testing.T) {
+	files, err := filepath.Glob("testdata/*/*.pdf")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			pdf, err := os.Open(file)
+			require.NoError(t, err)
+			defer pdf.Close()
+
+			_, infos, err := ExtractWithConfig(pdf, &ExtractConfig{Strict: true})
+			require.NoError(t, err)
+			assert.Empty(t, infos.Warnings)
+		})
+	}
+}
+
+func TestExtract_Inconsistent(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	// The XML is a BASIC invoice (guideline ID urn:factur-x.eu:1p0:basic, type code 380).
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		config         *AttachConfig
+		afRelationship AF // written into the file specification after attaching, since Validate rejects it
+		warnings       []string
+	}{
+		"consistent": {
+			config: &AttachConfig{ConformanceLevel: ConformanceBasic},
+		},
+		"conformance level": {
+			config:   nil, // defaults to EN 16931
+			warnings: []string{`XMP conformance level "EN 16931" does not match guideline ID "urn:cen.eu:en16931:2017#compliant#urn:factur-x.eu:1p0:basic" (BASIC)`},
+		},
+		"document type": {
+			config:   &AttachConfig{ConformanceLevel: ConformanceBasic, DocumentType: DocumentTypeOrder},
+			warnings: []string{`XMP document type "ORDER" does not match type code 380 (INVOICE)`},
+		},
+		"af relationship": {
+			config:         &AttachConfig{ConformanceLevel: ConformanceBasic},
+			afRelationship: AFData,
+			warnings:       []string{"AFRelationship Data is not allowed for BASIC, use Alternative or Source"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hybrid, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), test.config)
+			require.NoError(t, err)
+			if test.afRelationship != "" {
+				hybrid = setAFRelationship(t, hybrid, test.afRelationship)
+			}
+
+			_, infos, err := Extract(bytes.NewReader(hybrid))
+			require.NoError(t, err)
+			assert.Equal(t, test.warnings, infos.Warnings)
+
+			extracted, infos, err := ExtractWithConfig(bytes.NewReader(hybrid), &ExtractConfig{Strict: true})
+			if test.warnings == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, xml, extracted)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrInconsistent)
+			assert.ErrorContains(t, err, test.warnings[0])
+			assert.Nil(t, infos)
+		})
+	}
+}
+
+// setAFRelationship rewrites pdf with the AFRelationship of its associated file set to relationship.
+func setAFRelationship(t *testing.T, pdf []byte, relationship AF) []byte {
+	t.Helper()
+
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	catalog, err := ctx.Catalog()
+	require.NoError(t, err)
+
+	spec, err := ctx.DereferenceDict(catalog.ArrayEntry("AF")[0])
+	require.NoError(t, err)
+	spec.Update("AFRelationship", types.Name(relationship))
+
+	var out bytes.Buffer
+	require.NoError(t, api.Write(ctx, &out, model.NewDefaultConfiguration()))
+	return out.Bytes()
+}
+
+func TestAttach_AFRelationshipDefault(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+
+	for level, sample := range map[string]string{
+		ConformanceMinimum: "testdata/MINIMUM/MINIMUM_Rechnung.pdf",
+		ConformanceBasicWL: "testdata/BASIC WL/BASIC-WL_Einfach.pdf",
+	} {
+		t.Run(level, func(t *testing.T) {
+			f, err := os.Open(sample)
+			require.NoError(t, err)
+			defer f.Close()
+			xml, _, err := Extract(f)
+			require.NoError(t, err)
+
+			hybrid, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), &AttachConfig{ConformanceLevel: level})
+			require.NoError(t, err)
+
+			extracted, infos, err := ExtractWithConfig(bytes.NewReader(hybrid), &ExtractConfig{Strict: true})
+			require.NoError(t, err)
+			assert.Equal(t, xml, extracted)
+			assert.Equal(t, level, infos.ConformanceLevel)
+
+			report, err := Inspect(bytes.NewReader(hybrid), nil)
+			require.NoError(t, err)
+			assert.Equal(t, "Data", report.InvoiceFile().AFRelationship)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
+)
+
+// RenderCorrection builds a hybrid PDF of a credit note or corrected invoice made with
+// cii.CreditNote or cii.Correction. The document is first checked with cii.ValidateCorrection
+// against original, which may be nil to check the reference rules only, then written as CII XML
+// and rendered and attached like RenderFacturX.
+func RenderCorrection(correction, original *cii.Invoice, config *AttachConfig) ([]byte, error) {
+	return RenderCorrectionContext(context.Background(), correction, original, config)
+}
+
+// RenderCorrectionContext is like RenderCorrection but stops once ctx is cancelled.
+func RenderCorrectionContext(ctx context.Context, correction, original *cii.Invoice, config *AttachConfig) (_ []byte, err error) {
+	defer recovery.Recover("render correction", &err)
+
+	if err := cii.ValidateCorrection(correction, original); err != nil {
+		return nil, err
+	}
+
+	xml, err := cii.Marshal(correction)
+	if err != nil {
+		return nil, err
+	}
+
+	return RenderFacturXContext(ctx, bytes.NewReader(xml), config)
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderCorrection(t *testing.T) {
+	file, err := os.Open("testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	xml, _, err := Extract(file)
+	require.NoError(t, err)
+	original, err := cii.Parse(xml)
+	require.NoError(t, err)
+
+	credit, err := cii.CreditNote(original, &cii.CorrectionConfig{
+		ID:        "G-1",
+		IssueDate: time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC),
+		Lines:     []cii.LineAdjustment{{LineID: "2", Quantity: "10"}},
+	})
+	require.NoError(t, err)
+
+	pdf, err := RenderCorrection(credit, original, nil)
+	require.NoError(t, err)
+
+	extracted, infos, err := ExtractWithConfig(bytes.NewReader(pdf), &ExtractConfig{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, string(ConformanceEN16931), infos.ConformanceLevel)
+
+	parsed, err := cii.Parse(extracted)
+	require.NoError(t, err)
+	assert.Equal(t, credit, parsed)
+
+	report, err := CrossCheck(bytes.NewReader(pdf), nil)
+	require.NoError(t, err)
+	assert.Empty(t, report.Mismatches())
+
+	// The reference rules are checked before rendering.
+	credit.Preceding = nil
+	_, err = RenderCorrection(credit, original, nil)
+	assert.ErrorIs(t, err, cii.ErrInvalidCorrection)
+}
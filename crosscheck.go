@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"context"
+	"io"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
+)
+
+type (
+	// CrossCheckReport compares key values of the embedded invoice XML with the visible text of the
+	// PDF, see CrossCheck. It is JSON-serialisable and WriteText renders it for humans.
+	CrossCheckReport = extract.CrossCheckReport
+	// CheckedValue is an XML value looked up in the visible text.
+	CheckedValue = extract.CheckedValue
+	// ShownValue is a value found next to a label in the visible text.
+	ShownValue = extract.ShownValue
+	// CrossCheckField names an invoice value that is compared with the visible text.
+	CrossCheckField = extract.CrossCheckField
+)
+
+const (
+	FieldInvoiceNumber = extract.FieldInvoiceNumber
+	FieldIssueDate     = extract.FieldIssueDate
+	FieldDeliveryDate  = extract.FieldDeliveryDate
+	FieldDueDate       = extract.FieldDueDate
+	FieldGrandTotal    = extract.FieldGrandTotal
+	FieldDuePayable    = extract.FieldDuePayable
+	FieldIBAN          = extract.FieldIBAN
+)
+
+// CrossCheck extracts the text of the pages of a hybrid invoice and looks up the invoice number,
+// dates, grand total, due payable amount and IBANs of its CII XML in it. Values that no page
+// shows are listed by CrossCheckReport.Mismatches together with what the pages show next to a
+// matching label instead. The embedded XML must be CII, otherwise the error wraps cii.ErrNotCII.
+func CrossCheck(pdf io.ReadSeeker, config *ExtractConfig) (*CrossCheckReport, error) {
+	return CrossCheckContext(context.Background(), pdf, config)
+}
+
+// CrossCheckContext is like CrossCheck but stops once ctx is cancelled.
+func CrossCheckContext(ctx context.Context, pdf io.ReadSeeker, config *ExtractConfig) (_ *CrossCheckReport, err error) {
+	defer recovery.Recover("cross-check", &err)
+
+	return extract.CrossCheck(ctx, pdf, config.toConfig())
+}
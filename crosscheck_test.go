@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/MarlinKuhn/gopdfattach/internal/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossCheck(t *testing.T) {
+	file, err := os.Open("testdata/BASIC/BASIC_Einfach.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	report, err := CrossCheck(file, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "factur-x.xml", report.FileName)
+	assert.Equal(t, 2, report.Pages)
+	assert.Empty(t, report.Mismatches())
+
+	assert.Equal(t, []CheckedValue{
+		{Field: FieldInvoiceNumber, Value: "471102", Pages: []int{1}},
+		{Field: FieldIssueDate, Value: "2020-03-05", Pages: []int{1}},
+		{Field: FieldDeliveryDate, Value: "2020-03-05", Pages: []int{1}},
+		{Field: FieldDueDate, Value: "2020-04-04", Pages: []int{1, 2}},
+		{Field: FieldGrandTotal, Value: "235.62", Pages: []int{2}},
+		{Field: FieldDuePayable, Value: "235.62", Pages: []int{2}},
+	}, report.Values)
+}
+
+func TestCrossCheck_Testdata(t *testing.T) {
+	files, err := filepath.Glob("testdata/*/*.pdf")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, name := range files {
+		t.Run(name, func(t *testing.T) {
+			file, err := os.Open(name)
+			require.NoError(t, err)
+			defer file.Close()
+
+			report, err := CrossCheck(file, nil)
+			require.NoError(t, err)
+			assert.NotEmpty(t, report.Values)
+			assert.Empty(t, report.Mismatches())
+		})
+	}
+}
+
+func TestCrossCheck_Rendered(t *testing.T) {
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	pdf, err := RenderFacturX(bytes.NewReader(xml), nil)
+	require.NoError(t, err)
+
+	report, err := CrossCheck(bytes.NewReader(pdf), nil)
+	require.NoError(t, err)
+	assert.Empty(t, report.Mismatches())
+}
+
+func TestCrossCheck_Mismatch(t *testing.T) {
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	// The PDF shows another invoice number and total than the attached XML.
+	invoice, err := cii.Parse(xml)
+	require.NoError(t, err)
+	invoice.ID = "471199"
+	invoice.Totals.GrandTotal = "1190.00"
+	invoice.Totals.DuePayable = "1190.00"
+
+	visible, err := render.Render(invoice)
+	require.NoError(t, err)
+
+	pdf, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(visible), &AttachConfig{ConformanceLevel: ConformanceBasic})
+	require.NoError(t, err)
+
+	report, err := CrossCheck(bytes.NewReader(pdf), nil)
+	require.NoError(t, err)
+
+	mismatches := report.Mismatches()
+	require.Len(t, mismatches, 3)
+
+	assert.Equal(t, FieldInvoiceNumber, mismatches[0].Field)
+	assert.Equal(t, "471102", mismatches[0].Value)
+	assert.Contains(t, mismatches[0].Shown, ShownValue{Page: 1, Label: "Number", Text: "471199"})
+
+	assert.Equal(t, FieldGrandTotal, mismatches[1].Field)
+	assert.Equal(t, "235.62", mismatches[1].Value)
+	assert.Contains(t, mismatches[1].Shown, ShownValue{Page: 1, Label: "Total", Text: "1190.00"})
+
+	assert.Equal(t, FieldDuePayable, mismatches[2].Field)
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), `page 1 shows "1190.00" after "Total"`)
+}
+
+func TestCrossCheck_NoInvoice(t *testing.T) {
+	file, err := os.Open("testdata/invoice.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = CrossCheck(file, nil)
+	assert.Error(t, err)
+}
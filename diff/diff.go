@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package diff compares two invoices on their EN 16931 business terms instead of their XML, so
+// formatting, element order and number notation like 19 versus 19.00 do not count as changes.
+// Lines are matched by their ID (BT-126) and VAT breakdowns by category and rate, which shows
+// what a corrected invoice changes compared to the original.
+package diff
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/cii"
+)
+
+// Kind is the kind of a Change.
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Change is a business term or a group of terms, like a line, that differs between two invoices.
+type Change struct {
+	Kind  Kind   `json:"kind"`
+	Term  string `json:"term"`            // BT ID of a term or BG ID of a whole group, e.g. "BT-112" or "BG-25"
+	Group string `json:"group,omitempty"` // e.g. "line 2" or "vat S 19%", empty for header terms
+	Field string `json:"field,omitempty"` // e.g. "grand_total", empty for a whole group
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// Result lists the changes from the original to the new invoice in the document order of the
+// original, followed by the groups only the new invoice has.
+type Result struct {
+	Changes []Change `json:"changes"`
+}
+
+// Equal reports whether the invoices agree in all business terms.
+func (r *Result) Equal() bool {
+	return len(r.Changes) == 0
+}
+
+// Invoices compares two parsed invoices, before is the original and after the new one.
+func Invoices(before, after *cii.Invoice) *Result {
+	result := &Result{Changes: []Change{}}
+
+	oldGroups, newGroups := groups(before), groups(after)
+	index := func(gs []group) map[string]group {
+		m := make(map[string]group, len(gs))
+		for _, g := range gs {
+			m[g.key] = g
+		}
+		return m
+	}
+	oldIndex, newIndex := index(oldGroups), index(newGroups)
+
+	for _, o := range oldGroups {
+		n, ok := newIndex[o.key]
+		if !ok {
+			result.Changes = append(result.Changes, Change{Kind: Removed, Term: o.bg, Group: o.key, Old: o.summary})
+			continue
+		}
+
+		for i, ot := range o.terms {
+			nt := n.terms[i]
+			if equal(ot, nt) {
+				continue
+			}
+
+			c := Change{Kind: Changed, Term: ot.bt, Group: o.key, Field: ot.field, Old: ot.value, New: nt.value}
+			switch {
+			case ot.value == "":
+				c.Kind = Added
+			case nt.value == "":
+				c.Kind = Removed
+			}
+			result.Changes = append(result.Changes, c)
+		}
+	}
+
+	for _, n := range newGroups {
+		if _, ok := oldIndex[n.key]; !ok {
+			result.Changes = append(result.Changes, Change{Kind: Added, Term: n.bg, Group: n.key, New: n.summary})
+		}
+	}
+
+	return result
+}
+
+func equal(a, b term) bool {
+	if a.value == b.value {
+		return true
+	}
+	if !a.num || a.value == "" || b.value == "" {
+		return false
+	}
+
+	x, ok := cii.Decimal(a.value).Rat()
+	if !ok {
+		return false
+	}
+	y, ok := cii.Decimal(b.value).Rat()
+	return ok && x.Cmp(y) == 0
+}
+
+// XML compares two CII invoices. The error wraps cii.ErrNotCII if one is another syntax.
+func XML(before, after []byte) (*Result, error) {
+	a, err := cii.Parse(before)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse old invoice: %w", err)
+	}
+
+	b, err := cii.Parse(after)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse new invoice: %w", err)
+	}
+
+	return Invoices(a, b), nil
+}
+
+// PDF extracts the invoice XML of two hybrid PDFs like gopdfattach.ExtractWithConfig and compares
+// the invoices.
+func PDF(before, after io.ReadSeeker, config *gopdfattach.ExtractConfig) (*Result, error) {
+	return PDFContext(context.Background(), before, after, config)
+}
+
+// PDFContext is like PDF but stops once ctx is cancelled.
+func PDFContext(ctx context.Context, before, after io.ReadSeeker, config *gopdfattach.ExtractConfig) (*Result, error) {
+	a, _, err := gopdfattach.ExtractContext(ctx, before, config)
+	if err != nil {
+		return nil, fmt.Errorf("could not extract old invoice: %w", err)
+	}
+
+	b, _, err := gopdfattach.ExtractContext(ctx, after, config)
+	if err != nil {
+		return nil, fmt.Errorf("could not extract new invoice: %w", err)
+	}
+
+	return XML(a, b)
+}
+
+// WriteText writes one change per line, e.g.
+//
+//	changed  BT-112  grand_total        235.62 -> 211.00
+//	removed  BG-25   line 2             Joghurt Banane, 50 H87 × 5.5000 = 275.00
+func (r *Result) WriteText(w io.Writer) error {
+	if r.Equal() {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range r.Changes {
+		name := strings.TrimSpace(c.Group + " " + c.Field)
+
+		var value string
+		switch {
+		case c.Kind == Changed:
+			value = oneLine(c.Old) + " -> " + oneLine(c.New)
+		case c.Kind == Added:
+			value = oneLine(c.New)
+		default:
+			value = oneLine(c.Old)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Kind, c.Term, name, value)
+	}
+	return tw.Flush()
+}
+
+// oneLine joins multi-line values like notes and address lines.
+func oneLine(s string) string {
+	return strings.ReplaceAll(s, "\n", " / ")
+}
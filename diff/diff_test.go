@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func find(r *Result, term, group string) (Change, bool) {
+	for _, c := range r.Changes {
+		if c.Term == term && c.Group == group {
+			return c, true
+		}
+	}
+	return Change{}, false
+}
+
+func TestXML_Equal(t *testing.T) {
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	result, err := XML(xml, xml)
+	require.NoError(t, err)
+	assert.True(t, result.Equal())
+
+	var out bytes.Buffer
+	require.NoError(t, result.WriteText(&out))
+	assert.Equal(t, "no changes\n", out.String())
+}
+
+func TestXML_NumberNotation(t *testing.T) {
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	// Reformatting an amount is no change, changing it is.
+	reformatted := bytes.Replace(xml, []byte(">235.62<"), []byte(">235.620<"), 1)
+	require.NotEqual(t, xml, reformatted)
+	result, err := XML(xml, reformatted)
+	require.NoError(t, err)
+	assert.True(t, result.Equal())
+
+	changed := bytes.Replace(xml, []byte(">235.62<"), []byte(">211.00<"), 1)
+	result, err = XML(xml, changed)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Kind: Changed, Term: "BT-112", Field: "grand_total", Old: "235.62", New: "211.00"}}, result.Changes)
+}
+
+func TestXML_NotCII(t *testing.T) {
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	_, err = XML(xml, []byte(`<Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"/>`))
+	assert.ErrorIs(t, err, cii.ErrNotCII)
+}
+
+func TestInvoices_Groups(t *testing.T) {
+	before := &cii.Invoice{
+		ID: "R-1",
+		Lines: []cii.Line{
+			{ID: "1", Name: "Apfel", Quantity: "2", UnitCode: "H87", NetPrice: "1.50", Total: "3.00"},
+			{ID: "2", Name: "Birne", Quantity: "1", Total: "2.00"},
+		},
+		VAT: []cii.VATBreakdown{{Category: "S", Rate: "19.00", BasisAmount: "5.00", TaxAmount: "0.95"}},
+	}
+	after := &cii.Invoice{
+		ID: "R-1",
+		Lines: []cii.Line{
+			{ID: "1", Name: "Apfel", Quantity: "3.0", UnitCode: "H87", NetPrice: "1.5", Total: "4.50"},
+			{ID: "3", Name: "Kirsche", Total: "1.00"},
+		},
+		VAT: []cii.VATBreakdown{{Category: "S", Rate: "19", BasisAmount: "5.50", TaxAmount: "1.05"}},
+	}
+
+	result := Invoices(before, after)
+	assert.Equal(t, []Change{
+		{Kind: Changed, Term: "BT-129", Group: "line 1", Field: "quantity", Old: "2", New: "3.0"},
+		{Kind: Changed, Term: "BT-131", Group: "line 1", Field: "total", Old: "3.00", New: "4.50"},
+		{Kind: Removed, Term: "BG-25", Group: "line 2", Old: "Birne, 1 = 2.00"},
+		{Kind: Changed, Term: "BT-116", Group: "vat S 19%", Field: "basis_amount", Old: "5.00", New: "5.50"},
+		{Kind: Changed, Term: "BT-117", Group: "vat S 19%", Field: "tax_amount", Old: "0.95", New: "1.05"},
+		{Kind: Added, Term: "BG-25", Group: "line 3", New: "Kirsche = 1.00"},
+	}, result.Changes)
+
+	var out bytes.Buffer
+	require.NoError(t, result.WriteText(&out))
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "changed  BT-129  line 1 quantity         2 -> 3.0", lines[0])
+	assert.Equal(t, "removed  BG-25   line 2                  Birne, 1 = 2.00", lines[2])
+
+	data, err := json.Marshal(result.Changes[2])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"removed","term":"BG-25","group":"line 2","old":"Birne, 1 = 2.00"}`, string(data))
+}
+
+func TestInvoices_DuplicateLineIDs(t *testing.T) {
+	before := &cii.Invoice{Lines: []cii.Line{{ID: "1", Name: "a"}, {ID: "1", Name: "b"}, {ID: "1", Name: "c"}}}
+	after := &cii.Invoice{Lines: []cii.Line{{ID: "1", Name: "a"}, {ID: "1", Name: "b"}, {ID: "1", Name: "d"}}}
+
+	result := Invoices(before, after)
+	assert.Equal(t, []Change{{Kind: Changed, Term: "BT-153", Group: "line 1#3", Field: "name", Old: "c", New: "d"}}, result.Changes)
+}
+
+func TestPDF(t *testing.T) {
+	before, err := os.Open("../testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+	defer before.Close()
+
+	after, err := os.Open("../testdata/EN16931/EN16931_Rechnungskorrektur.pdf")
+	require.NoError(t, err)
+	defer after.Close()
+
+	result, err := PDF(before, after, nil)
+	require.NoError(t, err)
+
+	c, ok := find(result, "BT-3", "")
+	require.True(t, ok)
+	assert.Equal(t, Change{Kind: Changed, Term: "BT-3", Field: "type_code", Old: "380", New: "384"}, c)
+
+	c, ok = find(result, "BT-112", "")
+	require.True(t, ok)
+	assert.Equal(t, "529.87", c.Old)
+	assert.Equal(t, "-8.79", c.New)
+
+	c, ok = find(result, "BT-153", "line 1")
+	require.True(t, ok)
+	assert.Equal(t, "Trennblätter A4", c.Old)
+
+	c, ok = find(result, "BG-20", "allowance 1")
+	require.True(t, ok)
+	assert.Equal(t, Added, c.Kind)
+}
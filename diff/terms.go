@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package diff
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+)
+
+// term is a business term of an invoice.
+type term struct {
+	bt    string // e.g. "BT-112"
+	field string // e.g. "grand_total"
+	value string
+	num   bool // value is a decimal and compared numerically
+}
+
+// group is a repeatable part of an invoice, e.g. a line, identified by its key. The header terms
+// form the group with the empty key.
+type group struct {
+	bg      string // e.g. "BG-25"
+	key     string // e.g. "line 2"
+	summary string // describes an added or removed group
+	terms   []term
+}
+
+func text(bt, field, value string) term {
+	return term{bt: bt, field: field, value: strings.TrimSpace(value)}
+}
+
+func number(bt, field string, value cii.Decimal) term {
+	return term{bt: bt, field: field, value: strings.TrimSpace(string(value)), num: true}
+}
+
+func date(bt, field string, value time.Time) term {
+	t := term{bt: bt, field: field}
+	if !value.IsZero() {
+		t.value = value.Format(time.DateOnly)
+	}
+	return t
+}
+
+// groups flattens an invoice into its header and repeatable groups, in document order.
+func groups(inv *cii.Invoice) []group {
+	header := group{terms: []term{
+		text("BT-24", "guideline_id", inv.GuidelineID),
+		text("BT-1", "id", inv.ID),
+		text("BT-3", "type_code", inv.TypeCode),
+		date("BT-2", "issue_date", inv.IssueDate),
+		text("BT-5", "currency", inv.Currency),
+		text("BT-22", "notes", strings.Join(inv.Notes, "\n")),
+		text("BT-10", "buyer_reference", inv.BuyerReference),
+		text("BT-13", "order_reference", inv.OrderReference),
+		date("BT-72", "delivery_date", inv.DeliveryDate),
+		text("BT-83", "payment_reference", inv.PaymentRef),
+		text("BT-20", "payment_terms", inv.PaymentTerms),
+		date("BT-9", "due_date", inv.DueDate),
+	}}
+	header.terms = append(header.terms, party("seller", inv.Seller)...)
+	header.terms = append(header.terms, party("buyer", inv.Buyer)...)
+
+	t := inv.Totals
+	header.terms = append(header.terms,
+		number("BT-106", "line_total", t.LineTotal),
+		number("BT-107", "allowance_total", t.AllowanceTotal),
+		number("BT-108", "charge_total", t.ChargeTotal),
+		number("BT-109", "tax_basis_total", t.TaxBasisTotal),
+		number("BT-110", "tax_total", t.TaxTotal),
+		number("BT-112", "grand_total", t.GrandTotal),
+		number("BT-113", "prepaid", t.Prepaid),
+		number("BT-115", "due_payable", t.DuePayable),
+	)
+
+	out := []group{header}
+
+	for _, p := range inv.Preceding {
+		out = append(out, group{
+			bg:      "BG-3",
+			key:     "preceding invoice " + p.ID,
+			summary: p.ID,
+			terms:   []term{date("BT-26", "issue_date", p.IssueDate)},
+		})
+	}
+
+	for i, p := range inv.PaymentMeans {
+		out = append(out, group{
+			bg:      "BG-16",
+			key:     "payment means " + strconv.Itoa(i+1),
+			summary: strings.TrimSpace(p.TypeCode + " " + p.IBAN),
+			terms: []term{
+				text("BT-81", "type_code", p.TypeCode),
+				text("BT-82", "information", p.Information),
+				text("BT-84", "iban", strings.Join(strings.Fields(p.IBAN), "")),
+				text("BT-85", "account_name", p.AccountName),
+				text("BT-86", "bic", p.BIC),
+			},
+		})
+	}
+
+	for i, a := range inv.AllowanceCharge {
+		bg, kind, bts := "BG-20", "allowance ", [4]string{"BT-92", "BT-97", "BT-95", "BT-96"}
+		if a.Charge {
+			bg, kind, bts = "BG-21", "charge ", [4]string{"BT-99", "BT-104", "BT-102", "BT-103"}
+		}
+		out = append(out, group{
+			bg:      bg,
+			key:     kind + strconv.Itoa(i+1),
+			summary: strings.TrimSpace(string(a.Amount) + " " + a.Reason),
+			terms: []term{
+				number(bts[0], "amount", a.Amount),
+				text(bts[1], "reason", a.Reason),
+				text(bts[2], "vat_category", a.VATCategory),
+				number(bts[3], "vat_rate", a.VATRate),
+			},
+		})
+	}
+
+	for _, l := range inv.Lines {
+		out = append(out, group{
+			bg:      "BG-25",
+			key:     "line " + l.ID,
+			summary: lineSummary(l),
+			terms: []term{
+				text("BT-153", "name", l.Name),
+				text("BT-154", "description", l.Description),
+				text("BT-155", "seller_item_id", l.SellerID),
+				number("BT-129", "quantity", l.Quantity),
+				text("BT-130", "unit_code", l.UnitCode),
+				number("BT-146", "net_price", l.NetPrice),
+				number("BT-149", "base_quantity", l.BaseQty),
+				number("BT-131", "total", l.Total),
+				text("BT-151", "vat_category", l.VATCategory),
+				number("BT-152", "vat_rate", l.VATRate),
+			},
+		})
+	}
+
+	for _, v := range inv.VAT {
+		out = append(out, group{
+			bg:      "BG-23",
+			key:     "vat " + vatKey(v),
+			summary: strings.TrimSpace(string(v.BasisAmount) + " + " + string(v.TaxAmount)),
+			terms: []term{
+				number("BT-116", "basis_amount", v.BasisAmount),
+				number("BT-117", "tax_amount", v.TaxAmount),
+				text("BT-120", "exemption_reason", v.ExemptionReason),
+			},
+		})
+	}
+
+	// Keys must be unique, e.g. for lines sharing an ID.
+	seen := make(map[string]int)
+	for i := range out {
+		key := out[i].key
+		if seen[key]++; seen[key] > 1 {
+			out[i].key += "#" + strconv.Itoa(seen[key])
+		}
+	}
+
+	return out
+}
+
+func party(role string, p cii.Party) []term {
+	// Seller and buyer terms have their own BT numbers.
+	bt := map[string][12]string{
+		"seller": {"BT-27", "BT-29", "BT-31", "BT-32", "BT-34", "BT-35", "BT-37", "BT-38", "BT-40", "BT-41", "BT-42", "BT-43"},
+		"buyer":  {"BT-44", "BT-46", "BT-48", "", "BT-49", "BT-50", "BT-52", "BT-53", "BT-55", "BT-56", "BT-57", "BT-58"},
+	}[role]
+
+	return []term{
+		text(bt[0], role+".name", p.Name),
+		text(bt[1], role+".id", p.ID),
+		text(bt[2], role+".vat_id", p.VATID),
+		text(bt[3], role+".tax_id", p.TaxID),
+		text(bt[4], role+".electronic_address", p.ElectronID),
+		text(bt[5], role+".address.lines", strings.Join(p.Address.Lines, "\n")),
+		text(bt[6], role+".address.city", p.Address.City),
+		text(bt[7], role+".address.post_code", p.Address.PostCode),
+		text(bt[8], role+".address.country", p.Address.Country),
+		text(bt[9], role+".contact", p.Contact),
+		text(bt[10], role+".phone", p.Phone),
+		text(bt[11], role+".email", p.Email),
+	}
+}
+
+// vatKey identifies a VAT breakdown by category and rate, e.g. "S 19%".
+func vatKey(v cii.VATBreakdown) string {
+	rate := strings.TrimSpace(string(v.Rate))
+	if r, ok := v.Rate.Rat(); ok && rate != "" {
+		rate = strings.TrimSuffix(strings.TrimRight(r.FloatString(4), "0"), ".")
+	}
+	if rate == "" {
+		return v.Category
+	}
+	return v.Category + " " + rate + "%"
+}
+
+func lineSummary(l cii.Line) string {
+	s := l.Name
+	if l.Quantity != "" {
+		s += ", " + strings.TrimSpace(string(l.Quantity)+" "+l.UnitCode)
+	}
+	if l.NetPrice != "" {
+		s += " × " + string(l.NetPrice)
+	}
+	if l.Total != "" {
+		s += " = " + string(l.Total)
+	}
+	return strings.TrimPrefix(s, ", ")
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package email finds Factur-X / ZUGFeRD invoices in e-mail messages and MIME archives.
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+)
+
+// maxDepth bounds the nesting of multiparts and attached messages.
+const maxDepth = 32
+
+// Message is an e-mail message and the invoices found in it.
+type Message struct {
+	From      string
+	To        string
+	Subject   string
+	MessageID string
+	Date      time.Time // zero if the Date header is missing or invalid
+	Invoices  []Invoice // in the order of the parts
+}
+
+// Invoice is an invoice found in a part of a message.
+type Invoice struct {
+	Part Part
+	XML  []byte
+
+	// Info describes the hybrid PDF the XML was extracted from. It is nil for standalone XML parts.
+	Info *gopdfattach.XMLInfo
+
+	// Err is set if the part is a PDF but Extract failed, e.g. because it is not a hybrid invoice.
+	Err error
+}
+
+// Part describes the MIME part an invoice was found in.
+type Part struct {
+	// Path locates the part like an IMAP section, e.g. "2" is the second part of the top-level
+	// multipart and "3.1" the first part of the third. A message without multipart has path "1".
+	Path        string
+	ContentType string // media type without parameters, e.g. "application/pdf"
+	FileName    string // from Content-Disposition or Content-Type, empty if neither names the part
+	Size        int    // decoded size in bytes
+}
+
+// Extract parses an RFC 5322 message and runs gopdfattach.ExtractContext with config on every PDF
+// part. XML parts whose root element is a CII or UBL invoice are returned as standalone invoices.
+// Nested multiparts and attached messages (message/rfc822) are searched as well. Parts are decoded
+// according to their Content-Transfer-Encoding.
+//
+// config.Limits.MaxInputSize also bounds the decoded size of each part. Errors of single PDFs are
+// reported in Invoice.Err; Extract itself only fails if the message cannot be parsed.
+func Extract(ctx context.Context, r io.Reader, config *gopdfattach.ExtractConfig) (*Message, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read message: %w", err)
+	}
+
+	out := &Message{
+		From:      decodeHeader(msg.Header.Get("From")),
+		To:        decodeHeader(msg.Header.Get("To")),
+		Subject:   decodeHeader(msg.Header.Get("Subject")),
+		MessageID: msg.Header.Get("Message-Id"),
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		out.Date = date
+	}
+
+	w := walker{ctx: ctx, config: config, out: out}
+	if err := w.entity(textproto.MIMEHeader(msg.Header), msg.Body, "", 0); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+type walker struct {
+	ctx    context.Context
+	config *gopdfattach.ExtractConfig
+	out    *Message
+}
+
+func (w *walker) maxPartSize() int64 {
+	if w.config == nil {
+		return 0
+	}
+	return w.config.Limits.MaxInputSize
+}
+
+// entity processes one MIME entity. path is the path assigned by the parent multipart, empty for the
+// top-level entity.
+func (w *walker) entity(header textproto.MIMEHeader, body io.Reader, path string, depth int) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	if depth > maxDepth {
+		return fmt.Errorf("could not read message: parts are nested deeper than %d levels", maxDepth)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// RFC 2045 defaults to text/plain for missing or invalid content types.
+		mediaType, params = "text/plain", nil
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return w.multipart(body, params["boundary"], path, depth)
+	case mediaType == "message/rfc822":
+		msg, err := mail.ReadMessage(decode(header, body))
+		if err != nil {
+			return fmt.Errorf("could not read attached message %s: %w", leafPath(path), err)
+		}
+		// The attached message's parts are children of the message part.
+		return w.entity(textproto.MIMEHeader(msg.Header), msg.Body, leafPath(path), depth+1)
+	default:
+		return w.leaf(header, mediaType, params, body, leafPath(path))
+	}
+}
+
+func (w *walker) multipart(body io.Reader, boundary, path string, depth int) error {
+	if boundary == "" {
+		return fmt.Errorf("could not read multipart %s: missing boundary", path)
+	}
+
+	reader := multipart.NewReader(body, boundary)
+	for i := 1; ; i++ {
+		// NextRawPart leaves quoted-printable to decode, which handles all encodings alike.
+		part, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read multipart %s: %w", path, err)
+		}
+
+		err = w.entity(part.Header, part, partPath(path, i), depth+1)
+		_ = part.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (w *walker) leaf(header textproto.MIMEHeader, mediaType string, params map[string]string, body io.Reader, path string) error {
+	fileName := partFileName(header, params)
+	ext := strings.ToLower(pathExt(fileName))
+
+	isPDF := mediaType == "application/pdf" || ext == ".pdf"
+	isXML := mediaType == "application/xml" || mediaType == "text/xml" || ext == ".xml"
+	if !isPDF && !isXML && mediaType != "application/octet-stream" {
+		return nil
+	}
+
+	data, err := limits.ReadAll(decode(header, body), w.maxPartSize(), "part")
+	if err != nil {
+		return fmt.Errorf("could not read part %s: %w", path, err)
+	}
+
+	part := Part{Path: path, ContentType: mediaType, FileName: fileName, Size: len(data)}
+
+	switch {
+	case isPDF || bytes.HasPrefix(data, []byte("%PDF-")):
+		xml, info, err := gopdfattach.ExtractContext(w.ctx, bytes.NewReader(data), w.config)
+		if err != nil && w.ctx.Err() != nil {
+			return w.ctx.Err()
+		}
+		w.out.Invoices = append(w.out.Invoices, Invoice{Part: part, XML: xml, Info: info, Err: err})
+	case isXML && isInvoiceXML(data):
+		w.out.Invoices = append(w.out.Invoices, Invoice{Part: part, XML: data})
+	}
+
+	return nil
+}
+
+// decode undoes the Content-Transfer-Encoding of body. 7bit, 8bit, binary and unknown encodings are
+// returned unchanged.
+func decode(header textproto.MIMEHeader, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		// The decoder skips line breaks; other whitespace is not allowed by RFC 2045 anyway.
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// invoiceRoots are the root elements of CII and UBL invoices.
+var invoiceRoots = map[xml.Name]bool{
+	{Space: "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100", Local: "CrossIndustryInvoice"}: true,
+	{Space: "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2", Local: "Invoice"}:                    true,
+	{Space: "urn:oasis:names:specification:ubl:schema:xsd:CreditNote-2", Local: "CreditNote"}:              true,
+}
+
+func isInvoiceXML(data []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// Only the root element name is needed, which is ASCII in any encoding a real invoice uses.
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return invoiceRoots[start.Name]
+		}
+	}
+}
+
+var wordDecoder mime.WordDecoder
+
+// decodeHeader decodes RFC 2047 encoded words, returning value unchanged if that fails.
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// partFileName returns the file name from Content-Disposition, falling back to the name parameter
+// of Content-Type. RFC 2231 parameters are handled by mime.ParseMediaType, RFC 2047 encoded words
+// as sent by many mail clients are decoded here.
+func partFileName(header textproto.MIMEHeader, params map[string]string) string {
+	if _, disposition, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && disposition["filename"] != "" {
+		return decodeHeader(disposition["filename"])
+	}
+	return decodeHeader(params["name"])
+}
+
+func pathExt(fileName string) string {
+	return path.Ext(strings.ReplaceAll(fileName, "\\", "/"))
+}
+
+func partPath(parent string, i int) string {
+	if parent == "" {
+		return strconv.Itoa(i)
+	}
+	return parent + "." + strconv.Itoa(i)
+}
+
+// leafPath returns the path of a non-multipart entity. Like IMAP, a single part message is part "1",
+// while a part of a multipart keeps the path assigned by its parent.
+func leafPath(path string) string {
+	if path == "" {
+		return "1"
+	}
+	return path
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime/quotedprintable"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func base64Lines(data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	return b.String()
+}
+
+func quotedPrintable(t *testing.T, data []byte) string {
+	var b bytes.Buffer
+	w := quotedprintable.NewWriter(&b)
+	w.Binary = true // keep line breaks byte-identical
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return b.String()
+}
+
+func testMessage(t *testing.T) (message []byte, hybrid, xml []byte) {
+	t.Helper()
+
+	hybrid, err := os.ReadFile("../testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+	plain, err := os.ReadFile("../testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err = os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	var b strings.Builder
+	b.WriteString("From: =?UTF-8?Q?M=C3=BCller_GmbH?= <rechnung@example.com>\r\n")
+	b.WriteString("To: ap@example.org\r\n")
+	b.WriteString("Subject: =?UTF-8?B?UmVjaG51bmcgUkUtMTAwMQ==?=\r\n")
+	b.WriteString("Date: Mon, 02 Jun 2025 10:15:00 +0200\r\n")
+	b.WriteString("Message-ID: <1001@example.com>\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: multipart/mixed; boundary=\"outer\"\r\n\r\n")
+
+	// 1: alternative text and HTML body
+	b.WriteString("--outer\r\nContent-Type: multipart/alternative; boundary=\"alt\"\r\n\r\n")
+	b.WriteString("--alt\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nAnbei die Rechnung.\r\n")
+	b.WriteString("--alt\r\nContent-Type: text/html; charset=utf-8\r\n\r\n<p>Anbei die Rechnung.</p>\r\n")
+	b.WriteString("--alt--\r\n")
+
+	// 2: hybrid invoice, base64
+	b.WriteString("--outer\r\nContent-Type: application/pdf; name=\"RE-1001.pdf\"\r\n")
+	b.WriteString("Content-Disposition: attachment; filename=\"RE-1001.pdf\"\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	b.WriteString(base64Lines(hybrid) + "\r\n")
+
+	// 3: standalone XML, quoted-printable, RFC 2231 file name
+	b.WriteString("--outer\r\nContent-Type: text/xml; charset=utf-8\r\n")
+	b.WriteString("Content-Disposition: attachment; filename*=UTF-8''Rechnung%20M%C3%BCller.xml\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	b.WriteString(quotedPrintable(t, xml) + "\r\n")
+
+	// 4: forwarded message with a plain PDF sent as octet-stream
+	b.WriteString("--outer\r\nContent-Type: message/rfc822\r\n\r\n")
+	b.WriteString("From: other@example.com\r\nSubject: AGB\r\nContent-Type: multipart/mixed; boundary=\"inner\"\r\n\r\n")
+	b.WriteString("--inner\r\nContent-Type: text/plain\r\n\r\nSee attachment.\r\n")
+	b.WriteString("--inner\r\nContent-Type: application/octet-stream\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+	b.WriteString(base64Lines(plain) + "\r\n")
+	b.WriteString("--inner--\r\n")
+
+	// 5: XML that is not an invoice
+	b.WriteString("--outer\r\nContent-Type: application/xml\r\n\r\n<?xml version=\"1.0\"?><order/>\r\n")
+	b.WriteString("--outer--\r\n")
+
+	return []byte(b.String()), hybrid, xml
+}
+
+func TestExtract(t *testing.T) {
+	message, hybrid, xml := testMessage(t)
+
+	msg, err := Extract(context.Background(), bytes.NewReader(message), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Müller GmbH <rechnung@example.com>", msg.From)
+	assert.Equal(t, "Rechnung RE-1001", msg.Subject)
+	assert.Equal(t, "<1001@example.com>", msg.MessageID)
+	assert.Equal(t, 2025, msg.Date.Year())
+
+	require.Len(t, msg.Invoices, 3)
+
+	pdf := msg.Invoices[0]
+	assert.Equal(t, Part{Path: "2", ContentType: "application/pdf", FileName: "RE-1001.pdf", Size: len(hybrid)}, pdf.Part)
+	assert.NoError(t, pdf.Err)
+	assert.NotEmpty(t, pdf.XML)
+	require.NotNil(t, pdf.Info)
+	assert.Equal(t, "factur-x.xml", pdf.Info.FileName)
+
+	standalone := msg.Invoices[1]
+	assert.Equal(t, Part{Path: "3", ContentType: "text/xml", FileName: "Rechnung Müller.xml", Size: len(xml)}, standalone.Part)
+	assert.Equal(t, xml, standalone.XML)
+	assert.Nil(t, standalone.Info)
+	assert.NoError(t, standalone.Err)
+
+	plain := msg.Invoices[2]
+	assert.Equal(t, "4.2", plain.Part.Path)
+	assert.Equal(t, "application/octet-stream", plain.Part.ContentType)
+	assert.Error(t, plain.Err)
+	assert.Nil(t, plain.XML)
+}
+
+func TestExtract_SinglePart(t *testing.T) {
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	message := fmt.Sprintf("From: a@example.com\r\nContent-Type: application/xml\r\nContent-Transfer-Encoding: base64\r\n\r\n%s\r\n", base64Lines(xml))
+
+	msg, err := Extract(context.Background(), strings.NewReader(message), nil)
+	require.NoError(t, err)
+	require.Len(t, msg.Invoices, 1)
+	assert.Equal(t, "1", msg.Invoices[0].Part.Path)
+	assert.Equal(t, xml, msg.Invoices[0].XML)
+}
+
+func TestExtract_Limits(t *testing.T) {
+	message, _, _ := testMessage(t)
+
+	_, err := Extract(context.Background(), bytes.NewReader(message), &gopdfattach.ExtractConfig{
+		Limits: gopdfattach.Limits{MaxInputSize: 1024},
+	})
+	assert.ErrorIs(t, err, gopdfattach.ErrLimitExceeded)
+}
+
+func TestExtract_Cancelled(t *testing.T) {
+	message, _, _ := testMessage(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Extract(ctx, bytes.NewReader(message), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExtract_Nesting(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Content-Type: multipart/mixed; boundary=\"b0\"\r\n\r\n")
+	for i := 1; i <= maxDepth+1; i++ {
+		fmt.Fprintf(&b, "--b%d\r\nContent-Type: multipart/mixed; boundary=\"b%d\"\r\n\r\n", i-1, i)
+	}
+	for i := maxDepth + 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "--b%d--\r\n", i)
+	}
+
+	_, err := Extract(context.Background(), strings.NewReader(b.String()), nil)
+	assert.ErrorContains(t, err, "nested deeper")
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encryptedTestPDF(t *testing.T, path, userPassword, ownerPassword string) []byte {
+	t.Helper()
+
+	original, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = api.Encrypt(bytes.NewReader(original), &out, model.NewAESConfiguration(userPassword, ownerPassword, 256))
+	require.NoError(t, err)
+	return out.Bytes()
+}
+
+func TestExtract_OwnerPasswordProtected(t *testing.T) {
+	pdf := encryptedTestPDF(t, "testdata/EN16931/EN16931_Einfach.pdf", "", "owner")
+
+	xml, infos, err := Extract(bytes.NewReader(pdf))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, xml)
+	assert.Equal(t, FileTypeFacturX, infos.FileType)
+}
+
+func TestExtract_UserPasswordProtected(t *testing.T) {
+	pdf := encryptedTestPDF(t, "testdata/EN16931/EN16931_Einfach.pdf", "user", "owner")
+
+	_, _, err := Extract(bytes.NewReader(pdf))
+	var encErr *EncryptedError
+	assert.ErrorAs(t, err, &encErr)
+
+	_, _, err = ExtractWithConfig(bytes.NewReader(pdf), &ExtractConfig{UserPassword: "wrong"})
+	assert.ErrorAs(t, err, &encErr)
+
+	xml, infos, err := ExtractWithConfig(bytes.NewReader(pdf), &ExtractConfig{UserPassword: "user"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, xml)
+	assert.Equal(t, FileTypeFacturX, infos.FileType)
+}
+
+func TestAttach_EncryptedWithoutPassword(t *testing.T) {
+	pdf := encryptedTestPDF(t, "testdata/invoice.pdf", "", "owner")
+	xmlFile, _ := os.Open("testdata/factur-x.xml")
+	defer xmlFile.Close()
+
+	pdfData, err := AttachFacturX(xmlFile, bytes.NewReader(pdf), nil)
+	var encErr *EncryptedError
+	assert.ErrorAs(t, err, &encErr)
+	assert.Nil(t, pdfData)
+}
+
+func TestAttach_EncryptedIncremental(t *testing.T) {
+	pdf := encryptedTestPDF(t, "testdata/invoice.pdf", "", "owner")
+	xmlFile, _ := os.Open("testdata/factur-x.xml")
+	defer xmlFile.Close()
+
+	pdfData, err := AttachFacturX(xmlFile, bytes.NewReader(pdf), &AttachConfig{OwnerPassword: "owner", Incremental: true})
+	var encErr *EncryptedError
+	assert.ErrorAs(t, err, &encErr)
+	assert.Nil(t, pdfData)
+}
+
+func TestAttach_EncryptedIsDecrypted(t *testing.T) {
+	pdf := encryptedTestPDF(t, "testdata/invoice.pdf", "user", "owner")
+	xmlFile, _ := os.Open("testdata/factur-x.xml")
+	defer xmlFile.Close()
+
+	pdfData, err := AttachFacturX(xmlFile, bytes.NewReader(pdf), &AttachConfig{OwnerPassword: "owner"})
+	require.NoError(t, err)
+
+	ctx, err := api.ReadContext(bytes.NewReader(pdfData), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	assert.Nil(t, ctx.Encrypt)
+
+	xml, infos, err := Extract(bytes.NewReader(pdfData))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, xml)
+	assert.Equal(t, FileTypeFacturX, infos.FileType)
+}
+
+// TestAttach_EncryptedWrongOwnerPassword checks that a wrong owner password does not remove the encryption.
+// pdfcpu opens a PDF without user password with any owner password, so the password is checked separately.
+func TestAttach_EncryptedWrongOwnerPassword(t *testing.T) {
+	original, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	for name, configuration := range map[string]func(user, owner string) *model.Configuration{
+		"RC4 40":  func(user, owner string) *model.Configuration { return model.NewRC4Configuration(user, owner, 40) },
+		"RC4 128": func(user, owner string) *model.Configuration { return model.NewRC4Configuration(user, owner, 128) },
+		"AES 128": func(user, owner string) *model.Configuration { return model.NewAESConfiguration(user, owner, 128) },
+		"AES 256": func(user, owner string) *model.Configuration { return model.NewAESConfiguration(user, owner, 256) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var ownerOnly, both bytes.Buffer
+			require.NoError(t, api.Encrypt(bytes.NewReader(original), &ownerOnly, configuration("", "owner")))
+			require.NoError(t, api.Encrypt(bytes.NewReader(original), &both, configuration("user", "owner")))
+
+			var encErr *EncryptedError
+			_, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(ownerOnly.Bytes()), &AttachConfig{OwnerPassword: "garbage"})
+			assert.ErrorAs(t, err, &encErr)
+			_, err = AttachFacturX(bytes.NewReader(xml), bytes.NewReader(both.Bytes()), &AttachConfig{UserPassword: "user", OwnerPassword: "garbage"})
+			assert.NoError(t, err, "the user password authenticates")
+
+			for _, config := range []*AttachConfig{
+				{OwnerPassword: "owner"},
+				{UserPassword: "user"},
+				{UserPassword: "user", OwnerPassword: "owner"},
+			} {
+				pdfData, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(both.Bytes()), config)
+				require.NoError(t, err)
+				extracted, _, err := Extract(bytes.NewReader(pdfData))
+				require.NoError(t, err)
+				assert.Equal(t, xml, extracted)
+			}
+		})
+	}
+}
+
+func TestRepair_EncryptedWrongOwnerPassword(t *testing.T) {
+	var pdf bytes.Buffer
+	require.NoError(t, api.Encrypt(bytes.NewReader(attachedTestPDF(t)), &pdf, model.NewAESConfiguration("", "owner", 256)))
+
+	var encErr *EncryptedError
+	_, _, err := Repair(bytes.NewReader(pdf.Bytes()), &RepairConfig{OwnerPassword: "garbage"})
+	assert.ErrorAs(t, err, &encErr)
+	_, err = Upgrade(bytes.NewReader(pdf.Bytes()), &RepairConfig{OwnerPassword: "garbage"})
+	assert.ErrorAs(t, err, &encErr)
+
+	_, fixes, err := Repair(bytes.NewReader(pdf.Bytes()), &RepairConfig{OwnerPassword: "owner"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, fixes)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// CSVConfig configures WriteCSV.
+type CSVConfig struct {
+	Comma        rune // field separator, defaults to ','; spreadsheets with a German locale expect ';'
+	DecimalComma bool // write numbers with a decimal comma, e.g. 1190,00
+}
+
+// csvColumns are the columns of WriteCSV. Header records (record "H") fill the invoice columns,
+// line records (record "L") the line columns; both carry source and invoice_id to be joined on.
+var csvColumns = []string{
+	"record", "source", "invoice_id",
+	// invoice
+	"type_code", "issue_date", "delivery_date", "due_date", "currency",
+	"seller_id", "seller_name", "seller_vat_id", "buyer_id", "buyer_name", "buyer_vat_id",
+	"buyer_reference", "order_reference", "payment_reference", "iban",
+	"line_total", "allowance_total", "charge_total", "tax_basis_total", "tax_total", "grand_total",
+	"prepaid", "due_payable",
+	// line
+	"line_id", "seller_item_id", "name", "quantity", "unit_code", "net_price", "line_amount",
+	"vat_category", "vat_rate",
+}
+
+// WriteCSV writes a column header and, per invoice, one header record followed by one record per
+// line. Dates are YYYY-MM-DD and numbers as written in the XML.
+func WriteCSV(w io.Writer, records []Record, config *CSVConfig) error {
+	var c CSVConfig
+	if config != nil {
+		c = *config
+	}
+
+	out := csv.NewWriter(w)
+	if c.Comma != 0 {
+		out.Comma = c.Comma
+	}
+
+	number := func(s string) string {
+		if c.DecimalComma {
+			return strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	}
+
+	if err := out.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		inv := r.Invoice
+
+		var iban string
+		for _, p := range inv.PaymentMeans {
+			if p.IBAN != "" {
+				iban = p.IBAN
+				break
+			}
+		}
+
+		t := inv.Totals
+		header := []string{
+			"H", r.Source, inv.ID,
+			inv.TypeCode, date(inv.IssueDate), date(inv.DeliveryDate), date(inv.DueDate), inv.Currency,
+			inv.Seller.ID, inv.Seller.Name, inv.Seller.VATID, inv.Buyer.ID, inv.Buyer.Name, inv.Buyer.VATID,
+			inv.BuyerReference, inv.OrderReference, inv.PaymentRef, iban,
+			number(decimal(t.LineTotal)), number(decimal(t.AllowanceTotal)), number(decimal(t.ChargeTotal)),
+			number(decimal(t.TaxBasisTotal)), number(decimal(t.TaxTotal)), number(decimal(t.GrandTotal)),
+			number(decimal(t.Prepaid)), number(decimal(t.DuePayable)),
+			"", "", "", "", "", "", "", "", "",
+		}
+		if err := out.Write(header); err != nil {
+			return err
+		}
+
+		for _, l := range inv.Lines {
+			line := make([]string, len(csvColumns))
+			line[0], line[1], line[2] = "L", r.Source, inv.ID
+			copy(line[len(line)-9:], []string{
+				l.ID, l.SellerID, l.Name, number(decimal(l.Quantity)), l.UnitCode,
+				number(decimal(l.NetPrice)), number(decimal(l.Total)), l.VATCategory, number(decimal(l.VATRate)),
+			})
+			if err := out.Write(line); err != nil {
+				return err
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// AccountMapping assigns the contra account (Gegenkonto) to bookings of a VAT category and rate.
+type AccountMapping struct {
+	Category string `json:"category"`          // VAT category code, e.g. "S"; empty matches every category
+	Rate     string `json:"rate,omitempty"`    // VAT rate in percent, e.g. "19"; empty matches every rate
+	Account  string `json:"account"`           // e.g. "8400"
+	TaxKey   string `json:"tax_key,omitempty"` // BU-Schlüssel, needed for accounts without automatic VAT
+}
+
+// SKR03Revenue maps the VAT categories of outgoing invoices to the revenue accounts of the DATEV
+// chart of accounts SKR03. The standard rate accounts book the VAT automatically.
+var SKR03Revenue = []AccountMapping{
+	{Category: "S", Rate: "19", Account: "8400"},
+	{Category: "S", Rate: "7", Account: "8300"},
+	{Category: "AE", Account: "8337"},
+	{Category: "K", Account: "8125"},
+	{Category: "G", Account: "8120"},
+	{Category: "E", Account: "8100"},
+	{Category: "Z", Account: "8100"},
+	{Category: "O", Account: "8100"},
+}
+
+// DATEVConfig configures WriteDATEV.
+type DATEVConfig struct {
+	ConsultantNumber int              // Beraternummer
+	ClientNumber     int              // Mandantennummer
+	FiscalYearStart  time.Time        // Wirtschaftsjahresbeginn, defaults to January 1 of the earliest issue date
+	AccountLength    int              // Sachkontenlänge, defaults to 4
+	Account          string           // customer or vendor account the invoices are booked on, defaults to "10000"
+	Accounts         []AccountMapping // contra accounts by VAT category, the first match wins; defaults to SKR03Revenue
+	Description      string           // Bezeichnung of the batch
+	Created          time.Time        // creation time written to the header, defaults to now
+}
+
+// datevColumns are the leading columns of the DATEV booking batch format; the remaining ones are
+// optional and left out.
+var datevColumns = []string{
+	"Umsatz (ohne Soll/Haben-Kz)", "Soll/Haben-Kennzeichen", "WKZ Umsatz", "Kurs", "Basis-Umsatz",
+	"WKZ Basis-Umsatz", "Konto", "Gegenkonto (ohne BU-Schlüssel)", "BU-Schlüssel", "Belegdatum",
+	"Belegfeld 1", "Belegfeld 2", "Skonto", "Buchungstext",
+}
+
+// booking is a line of the batch.
+type booking struct {
+	amount   *big.Rat // always positive
+	credit   bool     // Haben
+	currency string
+	account  string
+	contra   string
+	taxKey   string
+	date     time.Time
+	document string
+	text     string
+}
+
+// WriteDATEV writes the records as a DATEV-style booking batch (EXTF Buchungsstapel, format 700,
+// Windows-1252, semicolon separated). Every VAT breakdown of an invoice becomes one gross booking
+// on the customer account against the contra account of its category and rate. Invoices without a
+// VAT breakdown are booked with their grand total against a mapping with an empty category.
+// Credit notes (381) and negative amounts are booked on the credit side. All invoices must be
+// issued within the fiscal year, as DATEV dates omit the year.
+func WriteDATEV(w io.Writer, records []Record, config *DATEVConfig) error {
+	c := DATEVConfig{}
+	if config != nil {
+		c = *config
+	}
+	if c.AccountLength == 0 {
+		c.AccountLength = 4
+	}
+	if c.Account == "" {
+		c.Account = "10000"
+	}
+	if c.Accounts == nil {
+		c.Accounts = SKR03Revenue
+	}
+	if c.Created.IsZero() {
+		c.Created = time.Now()
+	}
+
+	var from, to time.Time
+	for _, r := range records {
+		d := r.Invoice.IssueDate
+		if d.IsZero() {
+			return fmt.Errorf("%s: invoice %s has no issue date", r.Source, r.Invoice.ID)
+		}
+		if from.IsZero() || d.Before(from) {
+			from = d
+		}
+		if to.IsZero() || d.After(to) {
+			to = d
+		}
+	}
+
+	if c.FiscalYearStart.IsZero() {
+		c.FiscalYearStart = time.Date(from.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	yearEnd := c.FiscalYearStart.AddDate(1, 0, 0)
+
+	var bookings []booking
+	for _, r := range records {
+		if d := r.Invoice.IssueDate; d.Before(c.FiscalYearStart) || !d.Before(yearEnd) {
+			return fmt.Errorf("%s: invoice %s is not issued in the fiscal year starting %s",
+				r.Source, r.Invoice.ID, c.FiscalYearStart.Format(time.DateOnly))
+		}
+
+		b, err := invoiceBookings(r, c)
+		if err != nil {
+			return err
+		}
+		bookings = append(bookings, b...)
+	}
+
+	// DATEV reads Windows-1252; characters it lacks become '?'.
+	bw := bufio.NewWriter(encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder()).Writer(w))
+
+	header := []string{
+		quote("EXTF"), "700", "21", quote("Buchungsstapel"), "13",
+		c.Created.Format("20060102150405") + fmt.Sprintf("%03d", c.Created.Nanosecond()/1e6),
+		"", quote("RE"), quote(""), quote(""),
+		strconv.Itoa(c.ConsultantNumber), strconv.Itoa(c.ClientNumber),
+		c.FiscalYearStart.Format("20060102"), strconv.Itoa(c.AccountLength),
+		from.Format("20060102"), to.Format("20060102"), quote(c.Description),
+		quote(""), "1", "0", "0", quote("EUR"),
+	}
+	writeDATEVLine(bw, header)
+
+	columns := make([]string, len(datevColumns))
+	for i, col := range datevColumns {
+		columns[i] = quote(col)
+	}
+	writeDATEVLine(bw, columns)
+
+	for _, b := range bookings {
+		side := "S"
+		if b.credit {
+			side = "H"
+		}
+		writeDATEVLine(bw, []string{
+			strings.Replace(b.amount.FloatString(2), ".", ",", 1), quote(side), quote(b.currency), "", "", quote(""),
+			b.account, b.contra, quote(b.taxKey), b.date.Format("0201"),
+			quote(truncate(b.document, 36)), quote(""), "", quote(truncate(b.text, 60)),
+		})
+	}
+
+	return bw.Flush()
+}
+
+func invoiceBookings(r Record, c DATEVConfig) ([]booking, error) {
+	inv := r.Invoice
+
+	type part struct {
+		category, rate string
+		gross          *big.Rat
+	}
+
+	var parts []part
+	for _, v := range inv.VAT {
+		gross := new(big.Rat).Add(rat(v.BasisAmount), rat(v.TaxAmount))
+		parts = append(parts, part{category: v.Category, rate: rateKey(v.Rate), gross: gross})
+	}
+	if len(parts) == 0 {
+		parts = append(parts, part{gross: rat(inv.Totals.GrandTotal)})
+	}
+
+	currency := inv.Currency
+	if currency == "" {
+		currency = "EUR"
+	}
+
+	text := inv.Buyer.Name
+	if text == "" {
+		text = inv.Seller.Name
+	}
+
+	var bookings []booking
+	for _, p := range parts {
+		if p.gross.Sign() == 0 {
+			continue
+		}
+
+		mapping, ok := findAccount(c.Accounts, p.category, p.rate)
+		if !ok && p.category == "" {
+			return nil, fmt.Errorf("%s: invoice %s has no VAT breakdown, add an account mapping with an empty category",
+				r.Source, inv.ID)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: no account mapping for VAT category %q and rate %q of invoice %s",
+				r.Source, p.category, p.rate, inv.ID)
+		}
+
+		// Credit notes reverse the booking, as does a negative amount.
+		credit := (inv.TypeCode == "381") != (p.gross.Sign() < 0)
+
+		bookings = append(bookings, booking{
+			amount:   new(big.Rat).Abs(p.gross),
+			credit:   credit,
+			currency: currency,
+			account:  c.Account,
+			contra:   mapping.Account,
+			taxKey:   mapping.TaxKey,
+			date:     inv.IssueDate,
+			document: inv.ID,
+			text:     text,
+		})
+	}
+
+	return bookings, nil
+}
+
+func findAccount(accounts []AccountMapping, category, rate string) (AccountMapping, bool) {
+	for _, a := range accounts {
+		if (a.Category == "" || a.Category == category) && (a.Rate == "" || rateKey(cii.Decimal(a.Rate)) == rate) {
+			return a, true
+		}
+	}
+	return AccountMapping{}, false
+}
+
+// rateKey normalises a VAT rate so that "19", "19.0" and "19.00" compare equal.
+func rateKey(d cii.Decimal) string {
+	s := decimal(d)
+	if s == "" {
+		return ""
+	}
+	r, ok := d.Rat()
+	if !ok {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(r.FloatString(4), "0"), ".")
+}
+
+// quote writes a DATEV text field, which is enclosed in double quotes that are doubled inside.
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+func writeDATEVLine(w *bufio.Writer, fields []string) {
+	w.WriteString(strings.Join(fields, ";"))
+	w.WriteString("\r\n")
+}
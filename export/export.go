@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package export writes invoices in flat formats for accounting: canonical JSON, CSV with header
+// and line records and DATEV-style booking batches (Buchungsstapel). The invoices are read from
+// hybrid PDFs with gopdfattach and parsed with the cii package.
+package export
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/MarlinKuhn/gopdfattach/cii"
+)
+
+// Record is an invoice to export.
+type Record struct {
+	Source  string // where the invoice was read from, e.g. the PDF file name
+	Invoice *cii.Invoice
+}
+
+// Read extracts the CII XML of a hybrid PDF and parses it. The error wraps cii.ErrNotCII if the
+// PDF carries another XML syntax.
+func Read(pdf io.ReadSeeker, source string, config *gopdfattach.ExtractConfig) (Record, error) {
+	return ReadContext(context.Background(), pdf, source, config)
+}
+
+// ReadContext is like Read but stops once ctx is cancelled.
+func ReadContext(ctx context.Context, pdf io.ReadSeeker, source string, config *gopdfattach.ExtractConfig) (Record, error) {
+	xml, _, err := gopdfattach.ExtractContext(ctx, pdf, config)
+	if err != nil {
+		return Record{}, err
+	}
+
+	invoice, err := cii.Parse(xml)
+	if err != nil {
+		return Record{}, err
+	}
+
+	return Record{Source: source, Invoice: invoice}, nil
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+func decimal(d cii.Decimal) string {
+	return strings.TrimSpace(string(d))
+}
+
+// rat returns d as a number, treating malformed values as zero like missing ones.
+func rat(d cii.Decimal) *big.Rat {
+	r, ok := d.Rat()
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func readRecord(t *testing.T, name string) Record {
+	t.Helper()
+
+	file, err := os.Open(name)
+	require.NoError(t, err)
+	defer file.Close()
+
+	record, err := Read(file, name, nil)
+	require.NoError(t, err)
+	return record
+}
+
+func TestRead_NotHybrid(t *testing.T) {
+	file, err := os.Open("../testdata/invoice.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = Read(file, "invoice.pdf", nil)
+	assert.Error(t, err)
+}
+
+func TestWriteJSON(t *testing.T) {
+	record := readRecord(t, "../testdata/BASIC/BASIC_Einfach.pdf")
+
+	var out bytes.Buffer
+	require.NoError(t, WriteJSON(&out, []Record{record}))
+
+	var doc map[string][]map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	require.Len(t, doc["invoices"], 1)
+
+	invoice := doc["invoices"][0]
+	assert.Equal(t, "../testdata/BASIC/BASIC_Einfach.pdf", invoice["source"])
+	assert.Equal(t, "471102", invoice["id"])
+	assert.Equal(t, "2020-03-05", invoice["issue_date"])
+	assert.Equal(t, "235.62", invoice["totals"].(map[string]any)["grand_total"])
+	assert.Equal(t, "Kunden AG Mitte", invoice["buyer"].(map[string]any)["name"])
+	assert.NotContains(t, invoice, "order_reference")
+
+	// The output is stable.
+	var again bytes.Buffer
+	require.NoError(t, WriteJSON(&again, []Record{record}))
+	assert.Equal(t, out.String(), again.String())
+}
+
+func TestWriteCSV(t *testing.T) {
+	records := []Record{
+		readRecord(t, "../testdata/BASIC/BASIC_Einfach.pdf"),
+		readRecord(t, "../testdata/EN16931/EN16931_Einfach.pdf"),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, WriteCSV(&out, records, &CSVConfig{Comma: ';', DecimalComma: true}))
+
+	reader := csv.NewReader(&out)
+	reader.Comma = ';'
+	rows, err := reader.ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, csvColumns, rows[0])
+	column := func(row []string, name string) string {
+		for i, c := range csvColumns {
+			if c == name {
+				return row[i]
+			}
+		}
+		t.Fatalf("no column %s", name)
+		return ""
+	}
+
+	// BASIC_Einfach has one line, EN16931_Einfach two.
+	require.Len(t, rows, 1+2+3)
+	assert.Equal(t, "H", column(rows[1], "record"))
+	assert.Equal(t, "235,62", column(rows[1], "grand_total"))
+	assert.Equal(t, "L", column(rows[2], "record"))
+	assert.Equal(t, "471102", column(rows[2], "invoice_id"))
+	assert.Equal(t, "198,00", column(rows[2], "line_amount"))
+	assert.Equal(t, "H", column(rows[3], "record"))
+	assert.Equal(t, "529,87", column(rows[3], "grand_total"))
+}
+
+func TestWriteDATEV(t *testing.T) {
+	invoice := func(id, typeCode string, vat ...cii.VATBreakdown) Record {
+		return Record{Source: id + ".pdf", Invoice: &cii.Invoice{
+			ID:        id,
+			TypeCode:  typeCode,
+			IssueDate: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+			Currency:  "EUR",
+			Buyer:     cii.Party{Name: `Müller "Mitte" GmbH`},
+			VAT:       vat,
+		}}
+	}
+
+	records := []Record{
+		invoice("R-1", "380",
+			cii.VATBreakdown{Category: "S", Rate: "19.00", BasisAmount: "1000.00", TaxAmount: "190.00"},
+			cii.VATBreakdown{Category: "S", Rate: "7", BasisAmount: "100.00", TaxAmount: "7.00"},
+		),
+		invoice("G-1", "381", cii.VATBreakdown{Category: "AE", BasisAmount: "50.00", TaxAmount: "0.00"}),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, WriteDATEV(&out, records, &DATEVConfig{
+		ConsultantNumber: 1001,
+		ClientNumber:     1,
+		Created:          time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC),
+	}))
+
+	text, err := charmap.Windows1252.NewDecoder().String(out.String())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n")
+	require.Len(t, lines, 5)
+
+	assert.True(t, strings.HasPrefix(lines[0], `"EXTF";700;21;"Buchungsstapel";13;20240401120000000;;"RE";"";"";1001;1;20240101;4;20240305;20240305;`))
+	assert.True(t, strings.HasPrefix(lines[1], `"Umsatz (ohne Soll/Haben-Kz)";"Soll/Haben-Kennzeichen"`))
+	assert.Equal(t, `1190,00;"S";"EUR";;;"";10000;8400;"";0503;"R-1";"";;"Müller ""Mitte"" GmbH"`, lines[2])
+	assert.Equal(t, `107,00;"S";"EUR";;;"";10000;8300;"";0503;"R-1";"";;"Müller ""Mitte"" GmbH"`, lines[3])
+	assert.Equal(t, `50,00;"H";"EUR";;;"";10000;8337;"";0503;"G-1";"";;"Müller ""Mitte"" GmbH"`, lines[4])
+}
+
+func TestWriteDATEV_Mapping(t *testing.T) {
+	record := Record{Source: "r.pdf", Invoice: &cii.Invoice{
+		ID:        "R-1",
+		IssueDate: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+		Totals:    cii.Totals{GrandTotal: "-10.00"},
+	}}
+
+	// Without a VAT breakdown only a mapping for every category applies.
+	err := WriteDATEV(&bytes.Buffer{}, []Record{record}, nil)
+	assert.ErrorContains(t, err, "no VAT breakdown")
+
+	var out bytes.Buffer
+	require.NoError(t, WriteDATEV(&out, []Record{record}, &DATEVConfig{
+		Account:  "12345",
+		Accounts: []AccountMapping{{Account: "8200", TaxKey: "9"}},
+	}))
+	assert.Contains(t, out.String(), "10,00;\"H\";\"EUR\";;;\"\";12345;8200;\"9\";0503;\"R-1\"")
+
+	// DATEV dates omit the year.
+	err = WriteDATEV(&bytes.Buffer{}, []Record{record}, &DATEVConfig{
+		FiscalYearStart: time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Accounts:        []AccountMapping{{Account: "8200"}},
+	})
+	assert.ErrorContains(t, err, "fiscal year")
+}
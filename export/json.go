@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+)
+
+// The JSON documents mirror cii.Invoice with snake_case keys. Dates are written as YYYY-MM-DD and
+// numbers as strings exactly as in the XML, so no precision is lost; empty values are omitted.
+type (
+	jsonDocument struct {
+		Invoices []jsonInvoice `json:"invoices"`
+	}
+
+	jsonInvoice struct {
+		Source          string                `json:"source,omitempty"`
+		GuidelineID     string                `json:"guideline_id,omitempty"`
+		ID              string                `json:"id"`
+		TypeCode        string                `json:"type_code"`
+		IssueDate       string                `json:"issue_date,omitempty"`
+		Currency        string                `json:"currency,omitempty"`
+		Notes           []string              `json:"notes,omitempty"`
+		BuyerReference  string                `json:"buyer_reference,omitempty"`
+		OrderReference  string                `json:"order_reference,omitempty"`
+		Seller          jsonParty             `json:"seller"`
+		Buyer           jsonParty             `json:"buyer"`
+		DeliveryDate    string                `json:"delivery_date,omitempty"`
+		PaymentRef      string                `json:"payment_reference,omitempty"`
+		PaymentMeans    []jsonPaymentMeans    `json:"payment_means,omitempty"`
+		PaymentTerms    string                `json:"payment_terms,omitempty"`
+		DueDate         string                `json:"due_date,omitempty"`
+		Preceding       []jsonReference       `json:"preceding_invoices,omitempty"`
+		Lines           []jsonLine            `json:"lines,omitempty"`
+		AllowanceCharge []jsonAllowanceCharge `json:"allowances_charges,omitempty"`
+		VAT             []jsonVAT             `json:"vat_breakdown,omitempty"`
+		Totals          jsonTotals            `json:"totals"`
+	}
+
+	jsonParty struct {
+		ID         string       `json:"id,omitempty"`
+		Name       string       `json:"name,omitempty"`
+		VATID      string       `json:"vat_id,omitempty"`
+		TaxID      string       `json:"tax_id,omitempty"`
+		Address    *jsonAddress `json:"address,omitempty"`
+		Contact    string       `json:"contact,omitempty"`
+		Phone      string       `json:"phone,omitempty"`
+		Email      string       `json:"email,omitempty"`
+		ElectronID string       `json:"electronic_address,omitempty"`
+	}
+
+	jsonAddress struct {
+		Lines    []string `json:"lines,omitempty"`
+		PostCode string   `json:"post_code,omitempty"`
+		City     string   `json:"city,omitempty"`
+		Country  string   `json:"country,omitempty"`
+	}
+
+	jsonPaymentMeans struct {
+		TypeCode    string `json:"type_code"`
+		Information string `json:"information,omitempty"`
+		IBAN        string `json:"iban,omitempty"`
+		AccountName string `json:"account_name,omitempty"`
+		BIC         string `json:"bic,omitempty"`
+	}
+
+	jsonReference struct {
+		ID        string `json:"id"`
+		IssueDate string `json:"issue_date,omitempty"`
+	}
+
+	jsonLine struct {
+		ID          string `json:"id"`
+		Name        string `json:"name,omitempty"`
+		Description string `json:"description,omitempty"`
+		SellerID    string `json:"seller_item_id,omitempty"`
+		Quantity    string `json:"quantity,omitempty"`
+		UnitCode    string `json:"unit_code,omitempty"`
+		NetPrice    string `json:"net_price,omitempty"`
+		BaseQty     string `json:"base_quantity,omitempty"`
+		Total       string `json:"total,omitempty"`
+		VATCategory string `json:"vat_category,omitempty"`
+		VATRate     string `json:"vat_rate,omitempty"`
+	}
+
+	jsonAllowanceCharge struct {
+		Charge      bool   `json:"charge"`
+		Amount      string `json:"amount"`
+		Reason      string `json:"reason,omitempty"`
+		VATCategory string `json:"vat_category,omitempty"`
+		VATRate     string `json:"vat_rate,omitempty"`
+	}
+
+	jsonVAT struct {
+		Category        string `json:"category"`
+		Rate            string `json:"rate,omitempty"`
+		BasisAmount     string `json:"basis_amount"`
+		TaxAmount       string `json:"tax_amount"`
+		ExemptionReason string `json:"exemption_reason,omitempty"`
+	}
+
+	jsonTotals struct {
+		LineTotal      string `json:"line_total,omitempty"`
+		AllowanceTotal string `json:"allowance_total,omitempty"`
+		ChargeTotal    string `json:"charge_total,omitempty"`
+		TaxBasisTotal  string `json:"tax_basis_total,omitempty"`
+		TaxTotal       string `json:"tax_total,omitempty"`
+		GrandTotal     string `json:"grand_total,omitempty"`
+		Prepaid        string `json:"prepaid,omitempty"`
+		DuePayable     string `json:"due_payable,omitempty"`
+	}
+)
+
+// WriteJSON writes the records as one JSON object {"invoices": [...]}. Keys are snake_case and
+// always in the same order, dates are YYYY-MM-DD, numbers are strings as written in the XML and
+// empty values are left out, so equal invoices give equal output.
+func WriteJSON(w io.Writer, records []Record) error {
+	doc := jsonDocument{Invoices: make([]jsonInvoice, 0, len(records))}
+	for _, r := range records {
+		doc.Invoices = append(doc.Invoices, toJSON(r))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+func toJSON(r Record) jsonInvoice {
+	inv := r.Invoice
+	out := jsonInvoice{
+		Source:         r.Source,
+		GuidelineID:    inv.GuidelineID,
+		ID:             inv.ID,
+		TypeCode:       inv.TypeCode,
+		IssueDate:      date(inv.IssueDate),
+		Currency:       inv.Currency,
+		Notes:          inv.Notes,
+		BuyerReference: inv.BuyerReference,
+		OrderReference: inv.OrderReference,
+		Seller:         jsonPartyOf(inv.Seller),
+		Buyer:          jsonPartyOf(inv.Buyer),
+		DeliveryDate:   date(inv.DeliveryDate),
+		PaymentRef:     inv.PaymentRef,
+		PaymentTerms:   inv.PaymentTerms,
+		DueDate:        date(inv.DueDate),
+		Totals: jsonTotals{
+			LineTotal:      decimal(inv.Totals.LineTotal),
+			AllowanceTotal: decimal(inv.Totals.AllowanceTotal),
+			ChargeTotal:    decimal(inv.Totals.ChargeTotal),
+			TaxBasisTotal:  decimal(inv.Totals.TaxBasisTotal),
+			TaxTotal:       decimal(inv.Totals.TaxTotal),
+			GrandTotal:     decimal(inv.Totals.GrandTotal),
+			Prepaid:        decimal(inv.Totals.Prepaid),
+			DuePayable:     decimal(inv.Totals.DuePayable),
+		},
+	}
+
+	for _, p := range inv.PaymentMeans {
+		out.PaymentMeans = append(out.PaymentMeans, jsonPaymentMeans(p))
+	}
+
+	for _, p := range inv.Preceding {
+		out.Preceding = append(out.Preceding, jsonReference{ID: p.ID, IssueDate: date(p.IssueDate)})
+	}
+
+	for _, l := range inv.Lines {
+		out.Lines = append(out.Lines, jsonLine{
+			ID:          l.ID,
+			Name:        l.Name,
+			Description: l.Description,
+			SellerID:    l.SellerID,
+			Quantity:    decimal(l.Quantity),
+			UnitCode:    l.UnitCode,
+			NetPrice:    decimal(l.NetPrice),
+			BaseQty:     decimal(l.BaseQty),
+			Total:       decimal(l.Total),
+			VATCategory: l.VATCategory,
+			VATRate:     decimal(l.VATRate),
+		})
+	}
+
+	for _, a := range inv.AllowanceCharge {
+		out.AllowanceCharge = append(out.AllowanceCharge, jsonAllowanceCharge{
+			Charge:      a.Charge,
+			Amount:      decimal(a.Amount),
+			Reason:      a.Reason,
+			VATCategory: a.VATCategory,
+			VATRate:     decimal(a.VATRate),
+		})
+	}
+
+	for _, v := range inv.VAT {
+		out.VAT = append(out.VAT, jsonVAT{
+			Category:        v.Category,
+			Rate:            decimal(v.Rate),
+			BasisAmount:     decimal(v.BasisAmount),
+			TaxAmount:       decimal(v.TaxAmount),
+			ExemptionReason: v.ExemptionReason,
+		})
+	}
+
+	return out
+}
+
+func jsonPartyOf(p cii.Party) jsonParty {
+	out := jsonParty{
+		ID:         p.ID,
+		Name:       p.Name,
+		VATID:      p.VATID,
+		TaxID:      p.TaxID,
+		Contact:    p.Contact,
+		Phone:      p.Phone,
+		Email:      p.Email,
+		ElectronID: p.ElectronID,
+	}
+	if a := p.Address; len(a.Lines) > 0 || a.PostCode != "" || a.City != "" || a.Country != "" {
+		out.Address = &jsonAddress{Lines: a.Lines, PostCode: a.PostCode, City: a.City, Country: a.Country}
+	}
+	return out
+}
@@ -5,9 +5,15 @@
 package gopdfattach
 
 import (
+	"context"
+	"crypto/x509"
 	"io"
+	"log/slog"
+	"time"
 
+	"github.com/MarlinKuhn/gopdfattach/internal/crypt"
 	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
 )
 
 const (
@@ -21,11 +27,98 @@ type XMLInfo struct {
 	FileName         string
 	Version          string
 	ConformanceLevel string
+	Signatures       []SignatureInfo // signature fields found in the PDF, in form field order
+
+	// Warnings lists where the XMP metadata contradicts the embedded XML, e.g. a conformance level
+	// that differs from the guideline ID, or its file specification. See ExtractConfig.Strict.
+	// It also reports form fields that could not be read while looking for signatures; these do
+	// not fail a strict extraction.
+	Warnings []string
+}
+
+// SignatureInfo describes a signature found in the PDF and the result of verifying it.
+// Verification checks the ByteRange and the CMS signature; the certificate chain is not validated.
+type SignatureInfo struct {
+	FieldName           string
+	SubFilter           string            // e.g. "ETSI.CAdES.detached" or "adbe.pkcs7.detached"
+	Name                string            // /Name of the signature dictionary
+	Reason              string            // /Reason of the signature dictionary
+	Location            string            // /Location of the signature dictionary
+	SigningTime         time.Time         // claimed signing time (/M), zero if absent
+	Certificate         *x509.Certificate // signer certificate, nil if the signature could not be parsed
+	Timestamped         bool              // signature carries an RFC 3161 time-stamp token
+	Valid               bool              // ByteRange is well-formed and the signature matches the signed bytes
+	Error               string            // why Valid is false
+	CoversWholeDocument bool              // nothing was appended to the file after signing
+	CoversAttachment    bool              // the embedded invoice lies inside the signed revision
+}
+
+// AttachmentSigned reports whether at least one valid signature covers the embedded invoice,
+// i.e. the XML was not added or replaced after signing.
+func (x *XMLInfo) AttachmentSigned() bool {
+	for _, sig := range x.Signatures {
+		if sig.Valid && sig.CoversAttachment {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractConfig holds the passwords used to open encrypted PDFs, the resource limits and the logger.
+// PDFs that are only protected by an owner password can be read without any password.
+type ExtractConfig struct {
+	UserPassword  string
+	OwnerPassword string
+	Limits        Limits       // see DefaultLimits
+	Logger        *slog.Logger // receives a debug event per step, nil disables logging
+
+	// Strict fails with an error wrapping ErrInconsistent instead of reporting XMLInfo.Warnings.
+	Strict bool
+}
+
+func (e *ExtractConfig) toConfig() extract.Config {
+	if e == nil {
+		return extract.Config{}
+	}
+
+	return extract.Config{
+		UserPassword:  e.UserPassword,
+		OwnerPassword: e.OwnerPassword,
+		Limits:        e.Limits.toLimits(),
+		Logger:        e.Logger,
+		Strict:        e.Strict,
+	}
 }
 
+// ErrInconsistent is returned by extraction in strict mode when the XMP metadata contradicts the
+// embedded XML or its file specification.
+var ErrInconsistent = extract.ErrInconsistent
+
+// ErrNoInvoiceXMP is returned by extraction when the XMP metadata of the PDF is missing or does not
+// describe a Factur-X or ZUGFeRD invoice, e.g. for a plain PDF.
+var ErrNoInvoiceXMP = extract.ErrNoInvoiceXMP
+
+// EncryptedError is returned when an encrypted PDF cannot be read or attached to, e.g. because
+// the password is wrong or missing. Reason explains why.
+type EncryptedError = crypt.Error
+
 // Extract extracts the embedded zugferd or x-rechnung from a PDF. Caution make sure to only use PDFs.
+// Signatures of the PDF are verified and reported in XMLInfo.Signatures.
 func Extract(pdf io.ReadSeeker) (xml []byte, infos *XMLInfo, err error) {
-	out, err := extract.FromReader(pdf)
+	return ExtractWithConfig(pdf, nil)
+}
+
+// ExtractWithConfig is like Extract but opens encrypted PDFs with the passwords of config and
+// enforces its limits.
+func ExtractWithConfig(pdf io.ReadSeeker, config *ExtractConfig) (xml []byte, infos *XMLInfo, err error) {
+	return ExtractContext(context.Background(), pdf, config)
+}
+
+// ExtractContext is like ExtractWithConfig but stops once ctx is cancelled.
+func ExtractContext(ctx context.Context, pdf io.ReadSeeker, config *ExtractConfig) (xml []byte, infos *XMLInfo, err error) {
+	defer recovery.Recover("extract", &err)
+
+	out, err := extract.FromReader(ctx, pdf, config.toConfig())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -35,6 +128,24 @@ func Extract(pdf io.ReadSeeker) (xml []byte, infos *XMLInfo, err error) {
 		FileName:         out.FileName,
 		Version:          out.Version,
 		ConformanceLevel: out.ConformanceLevel,
+		Warnings:         out.Warnings,
+	}
+
+	for _, sig := range out.Signatures {
+		infos.Signatures = append(infos.Signatures, SignatureInfo{
+			FieldName:           sig.FieldName,
+			SubFilter:           sig.SubFilter,
+			Name:                sig.Name,
+			Reason:              sig.Reason,
+			Location:            sig.Location,
+			SigningTime:         sig.SigningTime,
+			Certificate:         sig.Certificate,
+			Timestamped:         sig.Timestamped,
+			Valid:               sig.Valid,
+			Error:               sig.Error,
+			CoversWholeDocument: sig.CoversWholeDocument,
+			CoversAttachment:    sig.CoversAttachment,
+		})
 	}
 
 	switch out.FileType {
@@ -5,12 +5,16 @@
 package gopdfattach
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -254,3 +258,35 @@ func TestExtractXRechnung(t *testing.T) {
 		})
 	}
 }
+
+func TestExtract_NoInvoiceXMP(t *testing.T) {
+	plain, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+
+	ctx, err := api.ReadContext(bytes.NewReader(plain), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	sd, err := ctx.NewStreamDictForBuf([]byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:format>application/pdf</dc:format></rdf:Description>` +
+		`</rdf:RDF></x:xmpmeta>`))
+	require.NoError(t, err)
+	sd.InsertName("Type", "Metadata")
+	sd.InsertName("Subtype", "XML")
+	require.NoError(t, sd.Encode())
+	ref, err := ctx.IndRefForNewObject(*sd)
+	require.NoError(t, err)
+	catalog, err := ctx.Catalog()
+	require.NoError(t, err)
+	catalog["Metadata"] = *ref
+
+	var withXMP bytes.Buffer
+	require.NoError(t, api.Write(ctx, &withXMP, model.NewDefaultConfiguration()))
+
+	for name, pdf := range map[string][]byte{"no XMP": plain, "no invoice description": withXMP.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			xml, infos, err := Extract(bytes.NewReader(pdf))
+			assert.ErrorIs(t, err, ErrNoInvoiceXMP)
+			assert.Nil(t, xml)
+			assert.Nil(t, infos)
+		})
+	}
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run go test -run TestAttach_Golden -update to rewrite the golden files after an intended change.
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// golden is the structure of an attached PDF that the golden files pin down.
+type golden struct {
+	Info          XMLInfo           `json:"info"`
+	Extracted     string            `json:"extracted"`      // SHA-256 of the XML Extract returns
+	XMP           map[string]string `json:"xmp"`            // flattened catalog XMP properties
+	AF            []any             `json:"af"`             // catalog /AF with the file specification and embedded file dictionaries
+	EmbeddedFiles []string          `json:"embedded_files"` // names of the EmbeddedFiles name tree
+}
+
+func TestAttach_Golden(t *testing.T) {
+	samples, err := filepath.Glob("testdata/*/*.pdf")
+	require.NoError(t, err)
+	samples = append([]string{"testdata/invoice.pdf"}, samples...)
+
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	for _, sample := range samples {
+		name := strings.TrimSuffix(filepath.Base(sample), ".pdf")
+		t.Run(name, func(t *testing.T) {
+			pdf, err := os.Open(sample)
+			require.NoError(t, err)
+			defer pdf.Close()
+
+			out, err := AttachFacturX(bytes.NewReader(xml), pdf, &AttachConfig{ConformanceLevel: ConformanceBasic})
+			require.NoError(t, err)
+
+			// Samples that are hybrids already have their invoice replaced.
+			extracted, info, err := Extract(bytes.NewReader(out))
+			require.NoError(t, err)
+			require.Equal(t, xml, extracted)
+			requireEmbeddedFiles(t, out, xml)
+
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			require.NoError(t, encoder.Encode(goldenStructure(t, out, extracted, info)))
+			data := buf.Bytes()
+
+			path := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, data, 0o644))
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			require.NoError(t, err, "run go test -run TestAttach_Golden -update to create the golden file")
+			assert.Equal(t, string(want), string(data))
+		})
+	}
+}
+
+// requireEmbeddedFiles checks what the golden files must not bless: the invoice is embedded once
+// under its file name, as xml and with a /CheckSum matching the embedded data. Attachments of the
+// samples with other names are kept.
+func requireEmbeddedFiles(t *testing.T, pdf, xml []byte) {
+	t.Helper()
+
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	require.NoError(t, api.ValidateContext(ctx))
+
+	require.NoError(t, ctx.XRefTable.LocateNameTree("EmbeddedFiles", false))
+	var invoices []types.Object
+	require.NoError(t, ctx.XRefTable.Names["EmbeddedFiles"].Process(ctx.XRefTable, func(_ *model.XRefTable, name string, spec *types.Object) error {
+		if strings.HasPrefix(name, "factur-x.xml") {
+			invoices = append(invoices, *spec)
+		}
+		return nil
+	}))
+	require.Len(t, invoices, 1, "the invoice must be embedded exactly once")
+
+	spec, err := ctx.XRefTable.DereferenceDict(invoices[0])
+	require.NoError(t, err)
+	ef, err := ctx.XRefTable.DereferenceDict(spec["EF"])
+	require.NoError(t, err)
+	sd, _, err := ctx.XRefTable.DereferenceStreamDict(ef["F"])
+	require.NoError(t, err)
+	require.NotNil(t, sd)
+	require.NoError(t, sd.Decode())
+	require.Equal(t, xml, sd.Content)
+
+	params, err := ctx.XRefTable.DereferenceDict(sd.Dict["Params"])
+	require.NoError(t, err)
+	checkSum, err := types.StringOrHexLiteral(params["CheckSum"])
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%x", md5.Sum(xml)), *checkSum, "/CheckSum must be the MD5 of the embedded data")
+}
+
+func goldenStructure(t *testing.T, pdf, extracted []byte, info *XMLInfo) golden {
+	t.Helper()
+
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	require.NoError(t, api.ValidateContext(ctx))
+
+	out := golden{
+		Info:          *info,
+		Extracted:     fmt.Sprintf("%x", sha256.Sum256(extracted)),
+		XMP:           map[string]string{},
+		AF:            []any{},
+		EmbeddedFiles: []string{},
+	}
+
+	metadata, err := pdfcpu.ExtractMetadata(ctx)
+	require.NoError(t, err)
+	for _, meta := range metadata {
+		if meta.ParentType != "Catalog" {
+			continue
+		}
+		data, err := io.ReadAll(meta)
+		require.NoError(t, err)
+		out.XMP, err = flattenXMP(data)
+		require.NoError(t, err)
+	}
+
+	catalog, err := ctx.Catalog()
+	require.NoError(t, err)
+	af, err := ctx.XRefTable.DereferenceArray(catalog["AF"])
+	require.NoError(t, err)
+	for _, spec := range af {
+		out.AF = append(out.AF, goldenObject(ctx.XRefTable, spec, 0))
+	}
+
+	require.NoError(t, ctx.XRefTable.LocateNameTree("EmbeddedFiles", false))
+	if tree := ctx.XRefTable.Names["EmbeddedFiles"]; tree != nil {
+		require.NoError(t, tree.Process(ctx.XRefTable, func(_ *model.XRefTable, name string, _ *types.Object) error {
+			out.EmbeddedFiles = append(out.EmbeddedFiles, name)
+			return nil
+		}))
+	}
+
+	return out
+}
+
+var (
+	isoDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:\d{2})?$`)
+	pdfDate = regexp.MustCompile(`^D:\d{4}`)
+	uuid    = regexp.MustCompile(`(?i)^(uuid:)?[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// normalize replaces the values that change with every run.
+func normalize(value string) string {
+	switch {
+	case isoDate.MatchString(value), pdfDate.MatchString(value):
+		return "<date>"
+	case uuid.MatchString(value):
+		return "<uuid>"
+	default:
+		return value
+	}
+}
+
+// goldenObject converts a PDF object to JSON values: names get a leading slash, references are
+// resolved and streams are reduced to their dictionary without /Length.
+func goldenObject(xRefTable *model.XRefTable, o types.Object, depth int) any {
+	if depth > 8 {
+		return "<too deep>"
+	}
+
+	switch o := o.(type) {
+	case types.IndirectRef:
+		resolved, err := xRefTable.Dereference(o)
+		if err != nil {
+			return fmt.Sprintf("<%v>", err)
+		}
+		return goldenObject(xRefTable, resolved, depth+1)
+	case types.StreamDict:
+		// The compressed length depends on the flate implementation of the Go release.
+		dict := o.Dict.Clone().(types.Dict)
+		dict.Delete("Length")
+		return goldenObject(xRefTable, dict, depth)
+	case types.Dict:
+		out := make(map[string]any, len(o))
+		for key, value := range o {
+			out[key] = goldenObject(xRefTable, value, depth+1)
+		}
+		return out
+	case types.Array:
+		out := make([]any, len(o))
+		for i, value := range o {
+			out[i] = goldenObject(xRefTable, value, depth+1)
+		}
+		return out
+	case types.Name:
+		return "/" + string(o)
+	case types.StringLiteral, types.HexLiteral:
+		s, err := types.StringOrHexLiteral(o)
+		if err != nil {
+			return fmt.Sprintf("<%v>", err)
+		}
+		return normalize(*s)
+	case types.Integer:
+		return int(o)
+	case types.Float:
+		return float64(o)
+	case types.Boolean:
+		return bool(o)
+	case nil:
+		return nil
+	default:
+		return o.String()
+	}
+}
+
+// flattenXMP maps each property of an XMP packet to its value. Keys are the paths of prefixed
+// element names below rdf:Description, array items are numbered like dc:creator[1].
+func flattenXMP(data []byte) (map[string]string, error) {
+	const rdf = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+	out := make(map[string]string)
+	prefixes := map[string]string{"http://www.w3.org/XML/1998/namespace": "xml"}
+	qualified := func(n xml.Name) string {
+		if prefix, ok := prefixes[n.Space]; ok {
+			return prefix + ":" + n.Local
+		}
+		return n.Local
+	}
+
+	type element struct {
+		path  string // empty for rdf containers, which do not add to the path
+		items int    // rdf:li seen so far
+	}
+	stack := []element{{}}
+	current := func() string {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].path != "" {
+				return stack[i].path
+			}
+		}
+		return ""
+	}
+	join := func(parent, name string) string {
+		if parent == "" {
+			return name
+		}
+		return parent + "/" + name
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			for _, attr := range token.Attr {
+				if attr.Name.Space == "xmlns" {
+					prefixes[attr.Value] = attr.Name.Local
+				}
+			}
+			text.Reset()
+
+			var path string
+			switch {
+			case token.Name.Space == rdf && token.Name.Local == "li":
+				// The items are counted on the rdf:Seq, rdf:Bag or rdf:Alt around them.
+				container := &stack[len(stack)-1]
+				container.items++
+				path = fmt.Sprintf("%s[%d]", current(), container.items)
+			case token.Name.Space == rdf, token.Name.Local == "xmpmeta":
+			default:
+				path = join(current(), qualified(token.Name))
+			}
+
+			for _, attr := range token.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" || attr.Name.Space == rdf {
+					continue
+				}
+				key := join(path, qualified(attr.Name))
+				if path == "" {
+					key = join(current(), qualified(attr.Name))
+				}
+				out[key] = normalize(attr.Value)
+			}
+			stack = append(stack, element{path: path})
+		case xml.CharData:
+			text.Write(token)
+		case xml.EndElement:
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if value := strings.TrimSpace(text.String()); value != "" && top.path != "" {
+				out[top.path] = normalize(value)
+			}
+			text.Reset()
+		}
+	}
+}
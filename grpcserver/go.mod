@@ -0,0 +1,31 @@
+module github.com/MarlinKuhn/gopdfattach/grpcserver
+
+go 1.23
+
+require (
+	github.com/MarlinKuhn/gopdfattach v0.0.0-20261019051310-03f7d58cb4b6
+	github.com/MarlinKuhn/gopdfattach/api v0.0.0-20261019051310-03f7d58cb4b6
+	github.com/stretchr/testify v1.10.0
+	google.golang.org/grpc v1.70.0
+	google.golang.org/protobuf v1.36.5
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/hhrutter/lzw v1.0.0 // indirect
+	github.com/hhrutter/tiff v1.0.1 // indirect
+	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/pdfcpu/pdfcpu v0.9.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	github.com/trimmer-io/go-xmp v1.0.0 // indirect
+	golang.org/x/image v0.21.0 // indirect
+	golang.org/x/net v0.32.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241202173237-19429a94021a // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
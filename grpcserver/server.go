@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package grpcserver implements the gopdfattach.v1.GopdfattachService on top of the gopdfattach package.
+//
+//	s := grpc.NewServer()
+//	gopdfattachv1.RegisterGopdfattachServiceServer(s, grpcserver.New(nil))
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	gopdfattachv1 "github.com/MarlinKuhn/gopdfattach/api/gopdfattach/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Options configures the server.
+type Options struct {
+	MaxStreamSize int64 // maximum total size of the chunks of a streaming call in bytes, defaults to 64 MiB
+	// Limits bounds the resources spent on each PDF and XML, nil uses gopdfattach.DefaultLimits.
+	Limits *gopdfattach.Limits
+}
+
+func (o *Options) setDefaults() {
+	if o.MaxStreamSize <= 0 {
+		o.MaxStreamSize = 64 << 20
+	}
+
+	if o.Limits == nil {
+		limits := gopdfattach.DefaultLimits
+		o.Limits = &limits
+	}
+}
+
+// Server implements gopdfattachv1.GopdfattachServiceServer.
+type Server struct {
+	gopdfattachv1.UnimplementedGopdfattachServiceServer
+
+	options Options
+}
+
+// New returns a server. A nil options uses the defaults.
+func New(options *Options) *Server {
+	var o Options
+	if options != nil {
+		o = *options
+	}
+	o.setDefaults()
+
+	return &Server{options: o}
+}
+
+func (s *Server) Attach(ctx context.Context, req *gopdfattachv1.AttachRequest) (*gopdfattachv1.AttachResponse, error) {
+	pdf, err := s.attach(ctx, req.GetFileType(), req.GetPdf(), req.GetXml(), req.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &gopdfattachv1.AttachResponse{Pdf: pdf}, nil
+}
+
+func (s *Server) AttachStream(stream grpc.ClientStreamingServer[gopdfattachv1.AttachStreamRequest, gopdfattachv1.AttachStreamResponse]) error {
+	var (
+		metadata *gopdfattachv1.AttachMetadata
+		pdf, xml bytes.Buffer
+	)
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch payload := req.GetPayload().(type) {
+		case *gopdfattachv1.AttachStreamRequest_Metadata:
+			if metadata != nil || pdf.Len() > 0 || xml.Len() > 0 {
+				return status.Error(codes.InvalidArgument, "metadata must be sent once as the first message")
+			}
+			metadata = payload.Metadata
+		case *gopdfattachv1.AttachStreamRequest_PdfChunk:
+			pdf.Write(payload.PdfChunk)
+		case *gopdfattachv1.AttachStreamRequest_XmlChunk:
+			xml.Write(payload.XmlChunk)
+		}
+
+		if int64(pdf.Len()+xml.Len()) > s.options.MaxStreamSize {
+			return status.Errorf(codes.ResourceExhausted, "stream exceeds %d bytes", s.options.MaxStreamSize)
+		}
+	}
+
+	if metadata == nil {
+		return status.Error(codes.InvalidArgument, "missing metadata")
+	}
+
+	out, err := s.attach(stream.Context(), metadata.GetFileType(), pdf.Bytes(), xml.Bytes(), metadata.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&gopdfattachv1.AttachStreamResponse{Pdf: out})
+}
+
+func (s *Server) Extract(ctx context.Context, req *gopdfattachv1.ExtractRequest) (*gopdfattachv1.ExtractResponse, error) {
+	xml, info, err := s.extract(ctx, req.GetPdf(), &gopdfattach.ExtractConfig{
+		UserPassword:  req.GetUserPassword(),
+		OwnerPassword: req.GetOwnerPassword(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &gopdfattachv1.ExtractResponse{Xml: xml, Info: info}, nil
+}
+
+func (s *Server) ExtractStream(stream grpc.ClientStreamingServer[gopdfattachv1.ExtractStreamRequest, gopdfattachv1.ExtractStreamResponse]) error {
+	var (
+		config gopdfattach.ExtractConfig
+		pdf    bytes.Buffer
+	)
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch payload := req.GetPayload().(type) {
+		case *gopdfattachv1.ExtractStreamRequest_Metadata:
+			config.UserPassword = payload.Metadata.GetUserPassword()
+			config.OwnerPassword = payload.Metadata.GetOwnerPassword()
+		case *gopdfattachv1.ExtractStreamRequest_PdfChunk:
+			pdf.Write(payload.PdfChunk)
+		}
+
+		if int64(pdf.Len()) > s.options.MaxStreamSize {
+			return status.Errorf(codes.ResourceExhausted, "stream exceeds %d bytes", s.options.MaxStreamSize)
+		}
+	}
+
+	xml, info, err := s.extract(stream.Context(), pdf.Bytes(), &config)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&gopdfattachv1.ExtractStreamResponse{Xml: xml, Info: info})
+}
+
+func (s *Server) Validate(_ context.Context, req *gopdfattachv1.ValidateRequest) (*gopdfattachv1.ValidateResponse, error) {
+	fileType, err := toFileType(req.GetFileType())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := toAttachConfig(req.GetConfig()).Validate(fileType); err != nil {
+		return &gopdfattachv1.ValidateResponse{Valid: false, Error: err.Error()}, nil
+	}
+
+	return &gopdfattachv1.ValidateResponse{Valid: true}, nil
+}
+
+func (s *Server) attach(ctx context.Context, fileType gopdfattachv1.FileType, pdf, xml []byte, config *gopdfattachv1.AttachConfig) ([]byte, error) {
+	t, err := toFileType(fileType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pdf) == 0 || len(xml) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "pdf and xml are required")
+	}
+
+	attachConfig := toAttachConfig(config)
+	if attachConfig == nil {
+		attachConfig = &gopdfattach.AttachConfig{}
+	}
+	attachConfig.Limits = *s.options.Limits
+
+	var out []byte
+	switch t {
+	case gopdfattach.FileTypeFacturX:
+		out, err = gopdfattach.AttachFacturXContext(ctx, bytes.NewReader(xml), bytes.NewReader(pdf), attachConfig)
+	case gopdfattach.FileTypeZugferd:
+		out, err = gopdfattach.AttachZUGFeRDContext(ctx, bytes.NewReader(xml), bytes.NewReader(pdf), attachConfig)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return out, nil
+}
+
+func (s *Server) extract(ctx context.Context, pdf []byte, config *gopdfattach.ExtractConfig) ([]byte, *gopdfattachv1.XMLInfo, error) {
+	if len(pdf) == 0 {
+		return nil, nil, status.Error(codes.InvalidArgument, "pdf is required")
+	}
+
+	config.Limits = *s.options.Limits
+	xml, infos, err := gopdfattach.ExtractContext(ctx, bytes.NewReader(pdf), config)
+	if err != nil {
+		return nil, nil, toStatus(err)
+	}
+
+	return xml, toXMLInfo(infos), nil
+}
+
+// toStatus maps errors of the library to gRPC status errors.
+func toStatus(err error) error {
+	var encErr *gopdfattach.EncryptedError
+	var panicErr *gopdfattach.PanicError
+
+	switch {
+	case errors.As(err, &panicErr):
+		return status.Error(codes.Internal, err.Error())
+	case errors.Is(err, gopdfattach.ErrInvalidConfig):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &encErr):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, gopdfattach.ErrLimitExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		// Everything else is caused by a PDF or XML the library could not process.
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
+
+func toFileType(fileType gopdfattachv1.FileType) (string, error) {
+	switch fileType {
+	case gopdfattachv1.FileType_FILE_TYPE_FACTURX:
+		return gopdfattach.FileTypeFacturX, nil
+	case gopdfattachv1.FileType_FILE_TYPE_ZUGFERD:
+		return gopdfattach.FileTypeZugferd, nil
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "unsupported file type %s", fileType)
+	}
+}
+
+func toAttachConfig(config *gopdfattachv1.AttachConfig) *gopdfattach.AttachConfig {
+	if config == nil {
+		return nil
+	}
+
+	return &gopdfattach.AttachConfig{
+		DocumentType:     config.GetDocumentType(),
+		FileName:         config.GetFileName(),
+		Version:          config.GetVersion(),
+		ConformanceLevel: config.GetConformanceLevel(),
+		Creator:          config.GetCreator(),
+		AFRelationship:   gopdfattach.AF(config.GetAfRelationship()),
+		Incremental:      config.GetIncremental(),
+		UserPassword:     config.GetUserPassword(),
+		OwnerPassword:    config.GetOwnerPassword(),
+	}
+}
+
+func toXMLInfo(infos *gopdfattach.XMLInfo) *gopdfattachv1.XMLInfo {
+	info := &gopdfattachv1.XMLInfo{
+		DocumentType:     infos.DocumentType,
+		FileName:         infos.FileName,
+		Version:          infos.Version,
+		ConformanceLevel: infos.ConformanceLevel,
+		AttachmentSigned: infos.AttachmentSigned(),
+		Warnings:         infos.Warnings,
+	}
+
+	switch infos.FileType {
+	case gopdfattach.FileTypeFacturX:
+		info.FileType = gopdfattachv1.FileType_FILE_TYPE_FACTURX
+	case gopdfattach.FileTypeZugferd:
+		info.FileType = gopdfattachv1.FileType_FILE_TYPE_ZUGFERD
+	}
+
+	for _, sig := range infos.Signatures {
+		s := &gopdfattachv1.SignatureInfo{
+			FieldName:           sig.FieldName,
+			SubFilter:           sig.SubFilter,
+			Name:                sig.Name,
+			Reason:              sig.Reason,
+			Location:            sig.Location,
+			Timestamped:         sig.Timestamped,
+			Valid:               sig.Valid,
+			Error:               sig.Error,
+			CoversWholeDocument: sig.CoversWholeDocument,
+			CoversAttachment:    sig.CoversAttachment,
+		}
+		if !sig.SigningTime.IsZero() {
+			s.SigningTime = timestamppb.New(sig.SigningTime)
+		}
+		if sig.Certificate != nil {
+			s.Certificate = sig.Certificate.Raw
+		}
+		info.Signatures = append(info.Signatures, s)
+	}
+
+	return info
+}
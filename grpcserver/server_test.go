@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	gopdfattachv1 "github.com/MarlinKuhn/gopdfattach/api/gopdfattach/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T, options *Options) gopdfattachv1.GopdfattachServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	gopdfattachv1.RegisterGopdfattachServiceServer(srv, New(options))
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return gopdfattachv1.NewGopdfattachServiceClient(conn)
+}
+
+func TestAttachAndExtract(t *testing.T) {
+	client := newClient(t, nil)
+
+	pdf, err := os.ReadFile("../testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	attached, err := client.Attach(context.Background(), &gopdfattachv1.AttachRequest{
+		FileType: gopdfattachv1.FileType_FILE_TYPE_FACTURX,
+		Pdf:      pdf,
+		Xml:      xml,
+		Config:   &gopdfattachv1.AttachConfig{ConformanceLevel: "BASIC"},
+	})
+	require.NoError(t, err)
+
+	extracted, err := client.Extract(context.Background(), &gopdfattachv1.ExtractRequest{Pdf: attached.GetPdf()})
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted.GetXml())
+	assert.Equal(t, gopdfattachv1.FileType_FILE_TYPE_FACTURX, extracted.GetInfo().GetFileType())
+	assert.Equal(t, "BASIC", extracted.GetInfo().GetConformanceLevel())
+}
+
+func TestStreams(t *testing.T) {
+	client := newClient(t, nil)
+
+	pdf, err := os.ReadFile("../testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	attachStream, err := client.AttachStream(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, attachStream.Send(&gopdfattachv1.AttachStreamRequest{
+		Payload: &gopdfattachv1.AttachStreamRequest_Metadata{Metadata: &gopdfattachv1.AttachMetadata{
+			FileType: gopdfattachv1.FileType_FILE_TYPE_ZUGFERD,
+		}},
+	}))
+	for chunk := range slices.Chunk(pdf, 4096) {
+		require.NoError(t, attachStream.Send(&gopdfattachv1.AttachStreamRequest{
+			Payload: &gopdfattachv1.AttachStreamRequest_PdfChunk{PdfChunk: chunk},
+		}))
+	}
+	require.NoError(t, attachStream.Send(&gopdfattachv1.AttachStreamRequest{
+		Payload: &gopdfattachv1.AttachStreamRequest_XmlChunk{XmlChunk: xml},
+	}))
+	attached, err := attachStream.CloseAndRecv()
+	require.NoError(t, err)
+
+	extractStream, err := client.ExtractStream(context.Background())
+	require.NoError(t, err)
+	for chunk := range slices.Chunk(attached.GetPdf(), 4096) {
+		require.NoError(t, extractStream.Send(&gopdfattachv1.ExtractStreamRequest{
+			Payload: &gopdfattachv1.ExtractStreamRequest_PdfChunk{PdfChunk: chunk},
+		}))
+	}
+	extracted, err := extractStream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted.GetXml())
+	assert.Equal(t, gopdfattachv1.FileType_FILE_TYPE_ZUGFERD, extracted.GetInfo().GetFileType())
+}
+
+func TestStatusCodes(t *testing.T) {
+	client := newClient(t, &Options{MaxStreamSize: 1024})
+
+	pdf, err := os.ReadFile("../testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	_, err = client.Attach(context.Background(), &gopdfattachv1.AttachRequest{
+		FileType: gopdfattachv1.FileType_FILE_TYPE_FACTURX,
+		Pdf:      pdf,
+		Xml:      xml,
+		Config:   &gopdfattachv1.AttachConfig{ConformanceLevel: "EN16931"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Attach(context.Background(), &gopdfattachv1.AttachRequest{Pdf: pdf, Xml: xml})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Extract(context.Background(), &gopdfattachv1.ExtractRequest{Pdf: []byte("not a pdf")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stream, err := client.ExtractStream(context.Background())
+	require.NoError(t, err)
+	_ = stream.Send(&gopdfattachv1.ExtractStreamRequest{
+		Payload: &gopdfattachv1.ExtractStreamRequest_PdfChunk{PdfChunk: pdf},
+	})
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	resp, err := client.Validate(context.Background(), &gopdfattachv1.ValidateRequest{
+		FileType: gopdfattachv1.FileType_FILE_TYPE_ZUGFERD,
+		Config:   &gopdfattachv1.AttachConfig{ConformanceLevel: "XRECHNUNG"},
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.GetValid())
+	assert.NotEmpty(t, resp.GetError())
+}
+
+func TestLimits(t *testing.T) {
+	client := newClient(t, &Options{Limits: &gopdfattach.Limits{MaxObjects: 5}})
+
+	pdf, err := os.ReadFile("../testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("../testdata/factur-x.xml")
+	require.NoError(t, err)
+	hybrid, err := os.ReadFile("../testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+
+	_, err = client.Attach(context.Background(), &gopdfattachv1.AttachRequest{
+		FileType: gopdfattachv1.FileType_FILE_TYPE_FACTURX,
+		Pdf:      pdf,
+		Xml:      xml,
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = client.Extract(context.Background(), &gopdfattachv1.ExtractRequest{Pdf: hybrid})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	stream, err := client.ExtractStream(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&gopdfattachv1.ExtractStreamRequest{
+		Payload: &gopdfattachv1.ExtractStreamRequest_PdfChunk{PdfChunk: hybrid},
+	}))
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"context"
+	"io"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
+)
+
+type (
+	// Report describes the hybrid invoice structure of a PDF, see Inspect. It is JSON-serialisable
+	// and WriteText renders it for humans.
+	Report = extract.Report
+	// PDFAID holds the pdfaid XMP properties.
+	PDFAID = extract.PDFAID
+	// XMPInvoice holds the Factur-X (fx) or ZUGFeRD (zf) XMP properties.
+	XMPInvoice = extract.XMPInvoice
+	// XMPExtension is a PDF/A extension schema declared in the XMP metadata.
+	XMPExtension = extract.XMPExtension
+	// OutputIntent is an entry of the catalog /OutputIntents.
+	OutputIntent = extract.OutputIntent
+	// EmbeddedFile describes an embedded file and its file specification.
+	EmbeddedFile = extract.EmbeddedFile
+	// Problem is a deviation from Factur-X / ZUGFeRD or PDF/A-3 found by Inspect.
+	Problem = extract.Problem
+	// ProblemCode identifies the kind of a Problem.
+	ProblemCode = extract.ProblemCode
+)
+
+const (
+	ProblemEncrypted         = extract.ProblemEncrypted
+	ProblemNoXMP             = extract.ProblemNoXMP
+	ProblemNoPDFAID          = extract.ProblemNoPDFAID
+	ProblemNotPDFA3          = extract.ProblemNotPDFA3
+	ProblemNoInvoiceXMP      = extract.ProblemNoInvoiceXMP
+	ProblemNoExtensionSchema = extract.ProblemNoExtensionSchema
+	ProblemNoOutputIntent    = extract.ProblemNoOutputIntent
+	ProblemFileNotFound      = extract.ProblemFileNotFound
+	ProblemNotAssociated     = extract.ProblemNotAssociated
+	ProblemNoAFRelationship  = extract.ProblemNoAFRelationship
+	ProblemWrongSubtype      = extract.ProblemWrongSubtype
+	ProblemVersionBelow17    = extract.ProblemVersionBelow17
+)
+
+// Inspect reports the pdfaid and fx/zf XMP values, extension schemas, output intents, /AF entries
+// and embedded files of a PDF, and whether the XMP file name matches an attachment. Defects are
+// listed in Report.Problems instead of failing, so it also works on broken hybrids.
+func Inspect(pdf io.ReadSeeker, config *ExtractConfig) (*Report, error) {
+	return InspectContext(context.Background(), pdf, config)
+}
+
+// InspectContext is like Inspect but stops once ctx is cancelled.
+func InspectContext(ctx context.Context, pdf io.ReadSeeker, config *ExtractConfig) (_ *Report, err error) {
+	defer recovery.Recover("inspect", &err)
+
+	return extract.Inspect(ctx, pdf, config.toConfig())
+}
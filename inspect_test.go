@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	file, err := os.Open("testdata/EN16931/EN16931_Elektron.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	report, err := Inspect(file, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.4", report.PDFVersion)
+	assert.False(t, report.Encrypted)
+	assert.Equal(t, &PDFAID{Part: "3", Conformance: "B"}, report.PDFA)
+
+	require.NotNil(t, report.Invoice)
+	assert.Equal(t, "fx", report.Invoice.Namespace)
+	assert.Equal(t, "factur-x.xml", report.Invoice.DocumentFileName)
+	assert.Equal(t, "EN 16931", report.Invoice.ConformanceLevel)
+	assert.True(t, report.XMPFileFound)
+
+	require.Len(t, report.Extensions, 1)
+	assert.Equal(t, "urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#", report.Extensions[0].NamespaceURI)
+
+	assert.Len(t, report.AssociatedFiles, 3)
+	require.Len(t, report.EmbeddedFiles, 3)
+
+	invoice := report.InvoiceFile()
+	require.NotNil(t, invoice)
+	assert.Equal(t, "text/xml", invoice.Subtype)
+	assert.Equal(t, "Alternative", invoice.AFRelationship)
+	assert.True(t, invoice.Associated)
+
+	assert.ElementsMatch(t, []ProblemCode{ProblemVersionBelow17, ProblemNoOutputIntent}, problemCodes(report))
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"document_file_name":"factur-x.xml"`)
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), "factur-x.xml (embedded yes)")
+	assert.Contains(t, text.String(), "no-output-intent")
+}
+
+func TestInspect_PlainPDF(t *testing.T) {
+	file, err := os.Open("testdata/invoice.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	report, err := Inspect(file, nil)
+	require.NoError(t, err)
+
+	assert.False(t, report.HasXMP)
+	assert.Nil(t, report.Invoice)
+	assert.Empty(t, report.EmbeddedFiles)
+	assert.True(t, report.HasProblem(ProblemNoXMP))
+}
+
+func TestInspect_Attached(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	for name, attach := range map[string]func() ([]byte, error){
+		FileTypeFacturX: func() ([]byte, error) { return AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), nil) },
+		FileTypeZugferd: func() ([]byte, error) { return AttachZUGFeRD(bytes.NewReader(xml), bytes.NewReader(pdf), nil) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			hybrid, err := attach()
+			require.NoError(t, err)
+
+			report, err := Inspect(bytes.NewReader(hybrid), nil)
+			require.NoError(t, err)
+
+			require.NotNil(t, report.Invoice)
+			assert.Equal(t, "1.7", report.PDFVersion)
+			assert.True(t, report.XMPFileFound)
+			assert.Equal(t, len(xml), report.InvoiceFile().Size)
+			assert.Equal(t, []ProblemCode{ProblemNoOutputIntent}, problemCodes(report))
+		})
+	}
+}
+
+func problemCodes(report *Report) []ProblemCode {
+	var codes []ProblemCode
+	for _, p := range report.Problems {
+		codes = append(codes, p.Code)
+	}
+	return codes
+}
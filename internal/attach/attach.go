@@ -6,19 +6,26 @@ package attach
 
 import (
 	"bytes"
+	"compress/zlib"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/MarlinKuhn/gopdfattach/internal/crypt"
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/MarlinKuhn/gopdfattach/internal/logging"
 	_ "github.com/MarlinKuhn/gopdfattach/internal/xsd"
 	"github.com/MarlinKuhn/gopdfattach/internal/xsd/fx"
 	"github.com/MarlinKuhn/gopdfattach/internal/xsd/pdfaExtension"
 	"github.com/MarlinKuhn/gopdfattach/internal/xsd/pdfaid"
 	"github.com/MarlinKuhn/gopdfattach/internal/xsd/zf"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
@@ -47,6 +54,11 @@ type Config struct {
 	ConformanceLevel string
 	Creator          string
 	AFRelationship   string
+	Incremental      bool
+	UserPassword     string
+	OwnerPassword    string
+	Limits           limits.Limits
+	Logger           *slog.Logger // nil disables logging
 }
 
 func (c *Config) setDefaults() {
@@ -71,7 +83,7 @@ func (c *Config) setDefaults() {
 	}
 
 	if c.AFRelationship == "" {
-		c.AFRelationship = "Alternative"
+		c.AFRelationship = extract.AFRelationships(c.ConformanceLevel)[0]
 	}
 
 	switch c.XmlType {
@@ -87,7 +99,9 @@ func (c *Config) setDefaults() {
 	}
 }
 
-func Attach(zugFeRD io.Reader, pdf io.ReadSeeker, config Config) ([]byte, error) {
+// Attach embeds zugFeRD into pdf and converts it to PDF/A-3.
+// Processing stops once c is cancelled or a limit is exceeded.
+func Attach(c context.Context, zugFeRD io.Reader, pdf io.ReadSeeker, config Config) ([]byte, error) {
 	if zugFeRD == nil {
 		return nil, fmt.Errorf("missing XML file")
 	}
@@ -97,15 +111,87 @@ func Attach(zugFeRD io.Reader, pdf io.ReadSeeker, config Config) ([]byte, error)
 	}
 
 	config.setDefaults()
-	configuration := model.NewDefaultConfiguration()
-	ctx, err := api.ReadContext(pdf, configuration)
+	logger := logging.OrDiscard(config.Logger).With(
+		slog.String("xml_type", string(config.XmlType)),
+		slog.String("file_name", config.FileName),
+	)
+
+	start := time.Now()
+	if err := config.Limits.CheckInput(c, pdf); err != nil {
+		return nil, err
+	}
+
+	configuration := crypt.Configuration(config.UserPassword, config.OwnerPassword)
+
+	// An incremental update is appended to the exact input bytes, so they are kept around.
+	var original []byte
+	if config.Incremental {
+		size, err := pdf.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("could not seek PDF file: %w", err)
+		}
+		if _, err = pdf.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("could not seek PDF file: %w", err)
+		}
+
+		// Reading into a buffer of the known size avoids the copies io.ReadAll makes as it grows.
+		original = make([]byte, size)
+		if _, err = io.ReadFull(pdf, original); err != nil {
+			return nil, fmt.Errorf("could not read PDF file: %w", err)
+		}
+		pdf = bytes.NewReader(original)
+	}
+
+	ctx, err := pdfcpu.ReadWithContext(c, pdf, configuration)
 	if err != nil {
-		return nil, fmt.Errorf("could not read PDF file: %w", err)
+		return nil, fmt.Errorf("could not read PDF file: %w", crypt.ReadError(err))
+	}
+
+	logging.Step(c, logger, "read", start,
+		slog.Int64("input_size", ctx.Read.FileSize),
+		slog.Int("objects", len(ctx.XRefTable.Table)),
+		slog.String("pdf_version", ctx.VersionString()),
+		slog.Bool("encrypted", crypt.Encrypted(ctx)),
+	)
+
+	if err = config.Limits.CheckObjects(ctx); err != nil {
+		return nil, err
 	}
 
-	// Needs to be done for attachments to work!
-	if err = validate.XRefTable(ctx); err != nil {
-		return nil, fmt.Errorf("could not validate XRefTable: %w", err)
+	if crypt.Encrypted(ctx) {
+		// An incremental update would have to be encrypted like the rest of the file.
+		if config.Incremental {
+			return nil, &crypt.Error{Reason: "PDF/A-3 forbids encryption, an encrypted PDF cannot be updated incrementally"}
+		}
+
+		if err = crypt.Decrypt(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Needs to be done for attachments to work! An incremental update only appends the changed
+	// objects, so the name tree is all it needs, while pdfcpu relies on the validation when it
+	// rewrites the whole file.
+	start = time.Now()
+	if config.Incremental {
+		if err = extract.LoadEmbeddedFiles(ctx); err != nil {
+			return nil, fmt.Errorf("could not read embedded files: %w", err)
+		}
+		logging.Step(c, logger, "load embedded files", start)
+	} else {
+		if err = validate.XRefTable(ctx); err != nil {
+			return nil, fmt.Errorf("could not validate XRefTable: %w", err)
+		}
+		logging.Step(c, logger, "validate xref", start)
+	}
+
+	if err = c.Err(); err != nil {
+		return nil, err
+	}
+
+	var snapshot objectSnapshot
+	if config.Incremental {
+		snapshot = takeSnapshot(ctx)
 	}
 
 	catalog, err := ctx.Catalog()
@@ -113,130 +199,200 @@ func Attach(zugFeRD io.Reader, pdf io.ReadSeeker, config Config) ([]byte, error)
 		return nil, fmt.Errorf("could not get catalog: %w", err)
 	}
 
-	{
-		metadata, err := pdfcpu.ExtractMetadata(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("could not extract metadata: %w", err)
-		}
+	start = time.Now()
+	existing, xmpSize, err := updateMetadata(ctx, catalog, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !existing {
+		logger.LogAttrs(c, slog.LevelDebug, "no XMP metadata found, creating new document")
+	}
 
-		doc := xmp.NewDocument()
+	logging.Step(c, logger, "xmp merge", start,
+		slog.Bool("existing_metadata", existing),
+		slog.Int("xmp_size", xmpSize),
+	)
 
-		if len(metadata) == 0 {
-			log.Println("No metadata found in PDF, creating new XMP document")
+	start = time.Now()
+	xml := &countingReader{r: zugFeRD}
+	err = attachFileToPfd(ctx, model.Attachment{
+		Reader:   xml,
+		ID:       config.FileName,
+		FileName: config.FileName,
+		Desc:     "Factur-X/ZUGFeRD-Rechnung",
+	}, "text/xml", config.AFRelationship, config.Limits.MaxEmbeddedFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("could not add attachment: %w", err)
+	}
+	logging.Step(c, logger, "attach", start,
+		slog.Int64("xml_size", xml.n),
+		slog.String("af_relationship", config.AFRelationship),
+	)
+
+	if err = c.Err(); err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	var out []byte
+	if config.Incremental {
+		// The header cannot be changed in an update, the catalog version overrides it.
+		if ctx.HeaderVersion != nil && *ctx.HeaderVersion < model.V17 {
+			catalog.Update("Version", types.Name(model.V17.String()))
 		}
 
-		for _, meta := range metadata {
-			if meta.ParentType != "Catalog" {
-				continue
-			}
+		out, err = writeIncrement(ctx, original, snapshot)
+	} else {
+		// The output is about as large as the input and the XML, growing the buffer as it is
+		// written would copy it over and over.
+		var data = bytes.NewBuffer(make([]byte, 0, ctx.Read.FileSize+xml.n))
+		err = api.Write(ctx, data, configuration)
+		out = data.Bytes()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Step(c, logger, "write", start,
+		slog.Int("output_size", len(out)),
+		slog.Bool("incremental", config.Incremental),
+	)
+
+	return out, nil
+}
 
-			rawMetaXMP, err := io.ReadAll(meta)
-			if err != nil {
-				return nil, errors.Wrap(err, "could not read XMP metadata")
-			}
+// updateMetadata merges the PDF/A-3 identification, the extension schema and the fx or zf properties
+// of config into the catalog XMP metadata, creating it if missing or unparseable. Values and the
+// extension schema of the other invoice namespace are removed. It reports whether the PDF had
+// metadata and the size of the new XMP packet.
+func updateMetadata(ctx *model.Context, catalog types.Dict, config Config) (existing bool, size int, err error) {
+	metadata, err := pdfcpu.ExtractMetadata(ctx)
+	if err != nil {
+		return false, 0, fmt.Errorf("could not extract metadata: %w", err)
+	}
 
-			// XMP metadata manipulation
-			err = xmp.Unmarshal(rawMetaXMP, doc)
-			if err != nil {
-				return nil, fmt.Errorf("could not unmarshal XMP metadata: %w", err)
-			}
+	doc := xmp.NewDocument()
 
-			break
+	for _, meta := range metadata {
+		if meta.ParentType != "Catalog" {
+			continue
 		}
 
-		info, err := pdf2.MakeModel(doc)
+		rawMetaXMP, err := io.ReadAll(meta)
 		if err != nil {
-			return nil, fmt.Errorf("could not make model: %w", err)
+			return false, 0, errors.Wrap(err, "could not read XMP metadata")
 		}
 
-		info.PDFVersion = "1.7"
-		info.Keywords = "ZUGFeRD, PDF/A-3"
-		info.Creator = xmp.AgentName(config.Creator)
-		info.Producer = xmp.AgentName(config.Creator)
-
-		pdfa, err := pdfaid.MakeModel(doc)
-		if err != nil {
-			return nil, fmt.Errorf("could not make model: %w", err)
+		// XMP metadata manipulation. Metadata that cannot be parsed is replaced rather than merged.
+		if err = xmp.Unmarshal(rawMetaXMP, doc); err != nil {
+			doc = xmp.NewDocument()
 		}
 
-		pdfa.Conformance = "U"
-		pdfa.Part = "3"
+		break
+	}
+
+	info, err := pdf2.MakeModel(doc)
+	if err != nil {
+		return false, 0, fmt.Errorf("could not make model: %w", err)
+	}
+
+	info.PDFVersion = "1.7"
+	info.Keywords = "ZUGFeRD, PDF/A-3"
+	info.Creator = xmp.AgentName(config.Creator)
+	info.Producer = xmp.AgentName(config.Creator)
+
+	pdfa, err := pdfaid.MakeModel(doc)
+	if err != nil {
+		return false, 0, fmt.Errorf("could not make model: %w", err)
+	}
+
+	pdfa.Conformance = "U"
+	pdfa.Part = "3"
+
+	extension, err := pdfaExtension.MakeModel(doc)
+	if err != nil {
+		return false, 0, fmt.Errorf("could not make model: %w", err)
+	}
 
-		extension, err := pdfaExtension.MakeModel(doc)
+	switch config.XmlType {
+	case TypeFacturX:
+		makeModel, err := fx.MakeModel(doc)
 		if err != nil {
-			return nil, fmt.Errorf("could not make model: %w", err)
+			return false, 0, fmt.Errorf("could not make model: %w", err)
 		}
 
-		switch config.XmlType {
-		case TypeFacturX:
-			makeModel, err := fx.MakeModel(doc)
-			if err != nil {
-				return nil, fmt.Errorf("could not make model: %w", err)
-			}
-
-			makeModel.DocumentType = config.DocumentType
-			makeModel.DocumentFileName = config.FileName
-			makeModel.Version = config.Version
-			makeModel.ConformanceLevel = config.ConformanceLevel
-			extension.AddFx()
-		case TypeZugferd:
-			makeModel, err := zf.MakeModel(doc)
-			if err != nil {
-				return nil, fmt.Errorf("could not make model: %w", err)
-			}
-
-			makeModel.DocumentType = config.DocumentType
-			makeModel.DocumentFileName = config.FileName
-			makeModel.Version = config.Version
-			makeModel.ConformanceLevel = config.ConformanceLevel
-			extension.AddZf()
-		}
+		makeModel.DocumentType = config.DocumentType
+		makeModel.DocumentFileName = config.FileName
+		makeModel.Version = config.Version
+		makeModel.ConformanceLevel = config.ConformanceLevel
+		extension.AddFx()
 
-		rawMetaXMP, err := xmp.MarshalIndent(doc, "", "\t")
+		// A document describes one invoice, older ZUGFeRD 2.0 values would contradict it.
+		doc.RemoveNamespace(zf.NsZugferd)
+		extension.RemoveSchema(zf.NsZugferd.URI)
+	case TypeZugferd:
+		makeModel, err := zf.MakeModel(doc)
 		if err != nil {
-			return nil, fmt.Errorf("could not marshal metadata: %w", err)
+			return false, 0, fmt.Errorf("could not make model: %w", err)
 		}
 
-		{
-			// New XRefModel
-			streamDict, err := ctx.XRefTable.NewStreamDictForBuf(rawMetaXMP)
-			if err != nil {
-				return nil, fmt.Errorf("could not create stream: %w", err)
-			}
+		makeModel.DocumentType = config.DocumentType
+		makeModel.DocumentFileName = config.FileName
+		makeModel.Version = config.Version
+		makeModel.ConformanceLevel = config.ConformanceLevel
+		extension.AddZf()
+
+		doc.RemoveNamespace(fx.NsFacturX)
+		extension.RemoveSchema(fx.NsFacturX.URI)
+	}
 
-			streamDict.InsertName("Type", "Metadata")
-			streamDict.InsertName("Subtype", "XML")
+	rawMetaXMP, err := xmp.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return false, 0, fmt.Errorf("could not marshal metadata: %w", err)
+	}
 
-			err = streamDict.Encode()
-			if err != nil {
-				return nil, fmt.Errorf("could not encode stream: %w", err)
-			}
+	{
+		// New XRefModel
+		streamDict, err := ctx.XRefTable.NewStreamDictForBuf(rawMetaXMP)
+		if err != nil {
+			return false, 0, fmt.Errorf("could not create stream: %w", err)
+		}
 
-			indirectRef, err := ctx.XRefTable.IndRefForNewObject(*streamDict)
-			if err != nil {
-				return nil, fmt.Errorf("could not create indirect reference: %w", err)
-			}
+		streamDict.InsertName("Type", "Metadata")
+		streamDict.InsertName("Subtype", "XML")
 
-			catalog.Update("Metadata", *indirectRef)
+		err = streamDict.Encode()
+		if err != nil {
+			return false, 0, fmt.Errorf("could not encode stream: %w", err)
 		}
-	}
 
-	err = attachFileToPfd(ctx, model.Attachment{
-		Reader:   zugFeRD,
-		ID:       config.FileName,
-		FileName: config.FileName,
-		Desc:     "Factur-X/ZUGFeRD-Rechnung",
-	}, "text/xml", config.AFRelationship)
-	if err != nil {
-		return nil, fmt.Errorf("could not add attachment: %w", err)
+		indirectRef, err := ctx.XRefTable.IndRefForNewObject(*streamDict)
+		if err != nil {
+			return false, 0, fmt.Errorf("could not create indirect reference: %w", err)
+		}
+
+		catalog.Update("Metadata", *indirectRef)
 	}
 
-	var data = new(bytes.Buffer)
-	err = api.Write(ctx, data, configuration)
-	return data.Bytes(), err
+	return len(metadata) > 0, len(rawMetaXMP), nil
 }
 
-func attachFileToPfd(ctx *model.Context, a model.Attachment, mimeType string, afRelationship string) error {
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// attachFileToPfd embeds the file of a, which may be at most maxSize bytes large unless maxSize is 0,
+// and registers it in the EmbeddedFiles name tree and the catalog /AF.
+func attachFileToPfd(ctx *model.Context, a model.Attachment, mimeType string, afRelationship string, maxSize int64) error {
 	xRefTable := ctx.XRefTable
 	if err := xRefTable.LocateNameTree("EmbeddedFiles", true); err != nil {
 		return err
@@ -247,39 +403,47 @@ func attachFileToPfd(ctx *model.Context, a model.Attachment, mimeType string, af
 		return err
 	}
 
+	if err = removeAttachment(ctx, catalog, a.ID); err != nil {
+		return err
+	}
+
 	modTime := time.Now()
 	if a.ModTime != nil {
 		modTime = *a.ModTime
 	}
-	sd, err := xRefTable.NewEmbeddedStreamDict(a, modTime)
+
+	// The stream dictionary is built like xRefTable.NewEmbeddedStreamDict does, but the XML is hashed
+	// and compressed while it is read instead of being copied into buffers of its own first.
+	var raw bytes.Buffer
+	hash := md5.New()
+	zw := zlib.NewWriter(&raw)
+	size, err := limits.Copy(zw, io.TeeReader(a.Reader, hash), maxSize, "XML file")
 	if err != nil {
 		return err
 	}
-
-	streamDict, _, err := xRefTable.DereferenceStreamDict(*sd)
-	if err != nil {
+	if err = zw.Close(); err != nil {
 		return err
 	}
 
+	streamLength := int64(raw.Len())
+	streamDict := types.NewStreamDict(types.NewDict(), 0, &streamLength, nil, []types.PDFFilter{{Name: filter.Flate}})
+	streamDict.Raw = raw.Bytes()
+	streamDict.InsertInt("Length", int(streamLength))
+	streamDict.InsertName("Filter", filter.Flate)
+	streamDict.InsertName("Type", "EmbeddedFile")
 	if mimeType != "" {
 		streamDict.InsertName("Subtype", mimeType)
 	}
 
-	find, ok := streamDict.Find("Params")
-	if ok {
-		dict, err := xRefTable.DereferenceDict(find)
-		if err != nil {
-			return err
-		}
-
-		// md5
-		var buf bytes.Buffer
-		if _, err := io.Copy(&buf, a.Reader); err != nil {
-			return err
-		}
+	params := types.NewDict()
+	params.InsertInt("Size", int(size))
+	params.Insert("ModDate", types.StringLiteral(types.DateString(modTime)))
+	params.InsertString("CheckSum", hex.EncodeToString(hash.Sum(nil)))
+	streamDict.Insert("Params", params)
 
-		bb := buf.Bytes()
-		dict.InsertString("CheckSum", getMD5Hash(bb))
+	sd, err := xRefTable.IndRefForNewObject(streamDict)
+	if err != nil {
+		return err
 	}
 
 	d, err := xRefTable.NewFileSpecDict(a.ID, a.ID, a.Desc, *sd)
@@ -294,7 +458,11 @@ func attachFileToPfd(ctx *model.Context, a model.Attachment, mimeType string, af
 		return err
 	}
 
-	associatedFiles := catalog.ArrayEntry("AF")
+	// Existing entries may be an indirect array, which pdfcpu would not write.
+	associatedFiles, err := xRefTable.DereferenceArray(catalog["AF"])
+	if err != nil {
+		return err
+	}
 	associatedFiles = append(associatedFiles, *ir)
 	catalog.Update("AF", associatedFiles)
 
@@ -303,7 +471,55 @@ func attachFileToPfd(ctx *model.Context, a model.Attachment, mimeType string, af
 	return xRefTable.Names["EmbeddedFiles"].Add(xRefTable, a.ID, *ir, m, []string{"F", "UF"})
 }
 
-func getMD5Hash(text []byte) string {
-	hash := md5.Sum(text)
-	return hex.EncodeToString(hash[:])
+// removeAttachment removes the embedded file registered under fileName from the EmbeddedFiles name
+// tree and the catalog /AF, so attaching a new invoice to a hybrid replaces the old one instead of
+// being stored under a suffixed name the XMP metadata does not point to.
+func removeAttachment(ctx *model.Context, catalog types.Dict, fileName string) error {
+	spec, err := extract.FileSpec(ctx, fileName)
+	if err != nil || spec == nil {
+		return err
+	}
+
+	// Without an xref table the file specification is kept, it is dropped when the PDF is written
+	// since nothing refers to it anymore.
+	if _, _, err = ctx.Names["EmbeddedFiles"].Remove(nil, fileName); err != nil {
+		return fmt.Errorf("could not remove attachment %s: %w", fileName, err)
+	}
+
+	associatedFiles, err := ctx.DereferenceArray(catalog["AF"])
+	if err != nil || associatedFiles == nil {
+		return err
+	}
+
+	kept := associatedFiles[:0:0]
+	for _, o := range associatedFiles {
+		if !isFileSpec(ctx, o, spec, fileName) {
+			kept = append(kept, o)
+		}
+	}
+	catalog.Update("AF", kept)
+
+	return nil
+}
+
+// isFileSpec reports whether the /AF entry o is the file specification spec of fileName. Unless
+// both refer to the same object, /AF holds a copy, which is recognized by its file name.
+func isFileSpec(ctx *model.Context, o, spec types.Object, fileName string) bool {
+	ref, isRef := spec.(types.IndirectRef)
+	r, ok := o.(types.IndirectRef)
+	if isRef && ok {
+		return r.ObjectNumber == ref.ObjectNumber
+	}
+
+	d, err := ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return false
+	}
+
+	for _, key := range []string{"UF", "F"} {
+		if name, err := ctx.DereferenceText(d[key]); err == nil && name == fileName {
+			return true
+		}
+	}
+	return false
 }
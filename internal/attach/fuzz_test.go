@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package attach
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+)
+
+// FuzzAttach checks that no PDF or XML makes Attach panic. Run it with
+// go test -fuzz=FuzzAttach ./internal/attach
+func FuzzAttach(f *testing.F) {
+	xml, err := os.ReadFile("../../testdata/factur-x.xml")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	samples, err := filepath.Glob("../../testdata/*/*.pdf")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, sample := range append(samples, "../../testdata/invoice.pdf") {
+		pdf, err := os.ReadFile(sample)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(xml, pdf)
+	}
+
+	config := Config{
+		ConformanceLevel: "BASIC",
+		Limits: limits.Limits{
+			MaxInputSize:        8 << 20,
+			MaxObjects:          10_000,
+			MaxEmbeddedFileSize: 1 << 20,
+			MaxDecompressedSize: 16 << 20,
+		},
+	}
+
+	f.Fuzz(func(t *testing.T, xml, pdf []byte) {
+		out, err := Attach(context.Background(), bytes.NewReader(xml), bytes.NewReader(pdf), config)
+		if err == nil && len(out) == 0 {
+			t.Fatal("no output and no error")
+		}
+	})
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package attach
+
+import (
+	"fmt"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/incremental"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// objectSnapshot records the serialized form of every dict and array object of a context,
+// so objects modified afterwards can be detected.
+type objectSnapshot map[int]string
+
+func takeSnapshot(ctx *model.Context) objectSnapshot {
+	snapshot := objectSnapshot{}
+	for objNr, entry := range ctx.XRefTable.Table {
+		if entry == nil || entry.Free || entry.Object == nil {
+			continue
+		}
+
+		switch o := entry.Object.(type) {
+		case types.Dict:
+			snapshot[objNr] = o.PDFString()
+		case types.Array:
+			snapshot[objNr] = o.PDFString()
+		default:
+			// Streams and other objects are never modified in place; mark them as existing.
+			snapshot[objNr] = ""
+		}
+	}
+	return snapshot
+}
+
+// writeIncrement appends all objects of ctx that are new or changed since snapshot to original,
+// which must be the exact bytes ctx was read from.
+func writeIncrement(ctx *model.Context, original []byte, snapshot objectSnapshot) ([]byte, error) {
+	// pdfcpu keeps name trees in memory and only writes them back into their dicts on demand.
+	if err := ctx.BindNameTrees(); err != nil {
+		return nil, fmt.Errorf("could not bind name trees: %w", err)
+	}
+
+	update, err := incremental.New(original, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for objNr, entry := range ctx.XRefTable.Table {
+		if entry == nil || entry.Free || entry.Object == nil {
+			continue
+		}
+
+		before, existed := snapshot[objNr]
+		if existed {
+			var after string
+			switch o := entry.Object.(type) {
+			case types.Dict:
+				after = o.PDFString()
+			case types.Array:
+				after = o.PDFString()
+			default:
+				continue
+			}
+
+			if after == before {
+				continue
+			}
+		}
+
+		gen := 0
+		if entry.Generation != nil {
+			gen = *entry.Generation
+		}
+
+		update.Replace(*types.NewIndirectRef(objNr, gen), entry.Object)
+	}
+
+	result, err := update.Write()
+	if err != nil {
+		return nil, fmt.Errorf("could not write incremental update: %w", err)
+	}
+
+	return result.Data, nil
+}
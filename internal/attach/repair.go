@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package attach
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/crypt"
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/MarlinKuhn/gopdfattach/internal/logging"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// RepairConfig holds the passwords, limits and logger used by Repair.
+type RepairConfig struct {
+	Creator       string // written to the XMP metadata if it is rewritten, defaults to "gopdfattach"
+	UserPassword  string
+	OwnerPassword string
+	Limits        limits.Limits
+	Logger        *slog.Logger // nil disables logging
+}
+
+// Fix is a defect that Repair corrected.
+type Fix struct {
+	Problem     extract.ProblemCode
+	Description string
+}
+
+// xmpProblems are fixed by rewriting the XMP metadata.
+var xmpProblems = []extract.ProblemCode{
+	extract.ProblemNoXMP,
+	extract.ProblemNoPDFAID,
+	extract.ProblemNotPDFA3,
+	extract.ProblemNoInvoiceXMP,
+	extract.ProblemNoExtensionSchema,
+	extract.ProblemFileNotFound,
+}
+
+// Repair inspects a hybrid invoice and rewrites it with the defects of its XMP metadata, file
+// specification and catalog /AF fixed. The embedded XML is kept unchanged. Problems that cannot be
+// fixed without further input, like a missing output intent, are left as they are.
+func Repair(c context.Context, pdf io.ReadSeeker, config RepairConfig) ([]byte, []Fix, error) {
+	logger := logging.OrDiscard(config.Logger)
+
+	ctx, err := extract.Read(c, pdf, extract.Config{
+		UserPassword:  config.UserPassword,
+		OwnerPassword: config.OwnerPassword,
+		Limits:        config.Limits,
+		Logger:        config.Logger,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	start := time.Now()
+	report, err := extract.InspectContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	logging.Step(c, logger, "inspect", start, slog.Int("problems", len(report.Problems)))
+
+	var fixes []Fix
+	fixed := func(code extract.ProblemCode, format string, args ...any) {
+		fixes = append(fixes, Fix{Problem: code, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if report.Encrypted {
+		if err = crypt.Decrypt(ctx); err != nil {
+			return nil, nil, err
+		}
+		fixed(extract.ProblemEncrypted, "removed the encryption")
+	}
+
+	file := report.InvoiceFile()
+	if file == nil {
+		if file = invoiceCandidate(report.EmbeddedFiles); file == nil {
+			return nil, nil, fmt.Errorf("could not repair PDF: no embedded XML invoice found")
+		}
+	}
+
+	xmpConfig, err := repairedXMPConfig(ctx, report, file.Name, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get catalog: %w", err)
+	}
+
+	start = time.Now()
+	if hasAny(report, xmpProblems) {
+		if _, _, err = updateMetadata(ctx, catalog, xmpConfig); err != nil {
+			return nil, nil, err
+		}
+
+		for _, problem := range report.Problems {
+			switch problem.Code {
+			case extract.ProblemNoXMP, extract.ProblemNoInvoiceXMP:
+				fixed(problem.Code, "created %s XMP metadata for %s from the XML", xmpConfig.XmlType, file.Name)
+			case extract.ProblemNoPDFAID, extract.ProblemNotPDFA3:
+				fixed(problem.Code, "set pdfaid:part to 3")
+			case extract.ProblemNoExtensionSchema:
+				fixed(problem.Code, "added the %s PDF/A extension schema", xmpConfig.XmlType)
+			case extract.ProblemFileNotFound:
+				fixed(problem.Code, "set the XMP DocumentFileName to %s", file.Name)
+			}
+		}
+	}
+
+	spec, err := extract.FileSpec(ctx, file.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get file specification of %s: %w", file.Name, err)
+	}
+	if spec == nil {
+		return nil, nil, fmt.Errorf("could not get file specification of %s: not found", file.Name)
+	}
+
+	specDict, err := ctx.DereferenceDict(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get file specification of %s: %w", file.Name, err)
+	}
+	if specDict == nil {
+		return nil, nil, fmt.Errorf("could not get file specification of %s: not found", file.Name)
+	}
+
+	if file.AFRelationship == "" {
+		relationship := extract.AFRelationships(xmpConfig.ConformanceLevel)[0]
+		specDict.InsertName("AFRelationship", relationship)
+		fixed(extract.ProblemNoAFRelationship, "set the AFRelationship of %s to %s", file.Name, relationship)
+	}
+
+	if file.Subtype != "text/xml" {
+		sd, err := extract.EmbeddedFileStream(ctx, spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		sd.Update("Subtype", types.Name("text/xml"))
+		fixed(extract.ProblemWrongSubtype, "set the subtype of %s to text/xml", file.Name)
+	}
+
+	if err = associate(ctx, catalog, spec, !file.Associated); err != nil {
+		return nil, nil, err
+	}
+	if !file.Associated {
+		fixed(extract.ProblemNotAssociated, "added %s to the catalog /AF", file.Name)
+	}
+
+	if report.HasProblem(extract.ProblemVersionBelow17) {
+		// Writing the context always produces a PDF 1.7 header; a lower catalog /Version would override it.
+		catalog.Delete("Version")
+		fixed(extract.ProblemVersionBelow17, "raised the PDF version from %s to 1.7", report.PDFVersion)
+	}
+	logging.Step(c, logger, "repair", start, slog.Int("fixes", len(fixes)))
+
+	if err = c.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	start = time.Now()
+	var data bytes.Buffer
+	if err = api.Write(ctx, &data, crypt.Configuration(config.UserPassword, config.OwnerPassword)); err != nil {
+		return nil, nil, fmt.Errorf("could not write PDF: %w", err)
+	}
+	logging.Step(c, logger, "write", start, slog.Int("output_size", data.Len()))
+
+	return data.Bytes(), fixes, nil
+}
+
+// associate inlines the catalog /AF array, which pdfcpu does not write if it is an indirect object,
+// and appends spec to it if add is set.
+func associate(ctx *model.Context, catalog types.Dict, spec types.Object, add bool) error {
+	associatedFiles, err := ctx.DereferenceArray(catalog["AF"])
+	if err != nil {
+		return fmt.Errorf("could not get associated files: %w", err)
+	}
+
+	if add {
+		associatedFiles = append(associatedFiles, spec)
+	}
+
+	if associatedFiles != nil {
+		catalog.Update("AF", associatedFiles)
+	}
+	return nil
+}
+
+func hasAny(report *extract.Report, codes []extract.ProblemCode) bool {
+	for _, code := range codes {
+		if report.HasProblem(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// invoiceCandidate picks the embedded file that most likely is the invoice: an XML file with a
+// name used by Factur-X, ZUGFeRD or XRechnung, or otherwise the only XML file.
+func invoiceCandidate(files []extract.EmbeddedFile) *extract.EmbeddedFile {
+	var xmlFiles []*extract.EmbeddedFile
+	for i := range files {
+		name := strings.ToLower(files[i].Name)
+		switch name {
+		case "factur-x.xml", "zugferd-invoice.xml", "xrechnung.xml":
+			return &files[i]
+		}
+
+		if path.Ext(name) == ".xml" || files[i].Subtype == "text/xml" || files[i].Subtype == "application/xml" {
+			xmlFiles = append(xmlFiles, &files[i])
+		}
+	}
+
+	if len(xmlFiles) == 1 {
+		return xmlFiles[0]
+	}
+	return nil
+}
+
+// repairedXMPConfig returns the XMP values to write: the existing fx or zf values if present,
+// otherwise values derived from the guideline ID and type code of the XML. The file name is always
+// the one of the embedded invoice.
+func repairedXMPConfig(ctx *model.Context, report *extract.Report, fileName string, config RepairConfig) (Config, error) {
+	xmpConfig := Config{FileName: fileName, Creator: config.Creator}
+
+	if report.Invoice != nil {
+		xmpConfig.XmlType = TypeFacturX
+		if report.Invoice.Namespace == "zf" {
+			xmpConfig.XmlType = TypeZugferd
+		}
+		xmpConfig.DocumentType = report.Invoice.DocumentType
+		xmpConfig.Version = report.Invoice.Version
+		xmpConfig.ConformanceLevel = report.Invoice.ConformanceLevel
+		xmpConfig.setDefaults()
+		return xmpConfig, nil
+	}
+
+	data, err := extract.AttachmentData(ctx, fileName, config.Limits.MaxEmbeddedFileSize)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read attachment: %w", err)
+	}
+
+	document, err := extract.ReadDocumentContext(data)
+	if err != nil {
+		return Config{}, err
+	}
+
+	level, version, ok := extract.GuidelineLevel(document.GuidelineID)
+	if !ok {
+		return Config{}, fmt.Errorf("could not repair PDF: unknown guideline ID %q in %s", document.GuidelineID, fileName)
+	}
+
+	xmpConfig.XmlType = TypeFacturX
+	xmpConfig.ConformanceLevel = level
+	xmpConfig.Version = version
+	xmpConfig.DocumentType = extract.TypeCodeDocumentType(document.TypeCode)
+	xmpConfig.setDefaults()
+
+	return xmpConfig, nil
+}
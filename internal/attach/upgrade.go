@@ -0,0 +1,230 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package attach
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/crypt"
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/MarlinKuhn/gopdfattach/internal/logging"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Upgrade rewrites the XMP metadata of a hybrid invoice, typically a ZUGFeRD 2.0 one using the zf
+// namespace, as current Factur-X/ZUGFeRD metadata in the fx namespace. The conformance level and
+// XRechnung version are taken from the guideline ID of the XML, the attachment is renamed to
+// factur-x.xml (xrechnung.xml for XRECHNUNG) and its AFRelationship is set to one the level allows.
+// The embedded XML is kept unchanged.
+func Upgrade(c context.Context, pdf io.ReadSeeker, config RepairConfig) ([]byte, error) {
+	logger := logging.OrDiscard(config.Logger)
+
+	ctx, err := extract.Read(c, pdf, extract.Config{
+		UserPassword:  config.UserPassword,
+		OwnerPassword: config.OwnerPassword,
+		Limits:        config.Limits,
+		Logger:        config.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := extract.InspectContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Invoice == nil {
+		return nil, fmt.Errorf("could not upgrade PDF: no fx or zf XMP metadata found")
+	}
+
+	file := report.InvoiceFile()
+	if file == nil {
+		if file = invoiceCandidate(report.EmbeddedFiles); file == nil {
+			return nil, fmt.Errorf("could not upgrade PDF: no embedded XML invoice found")
+		}
+	}
+
+	if report.Encrypted {
+		if err = crypt.Decrypt(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	xmpConfig, err := upgradedXMPConfig(ctx, report, file.Name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Name != xmpConfig.FileName {
+		if slices.ContainsFunc(report.EmbeddedFiles, func(f extract.EmbeddedFile) bool { return f.Name == xmpConfig.FileName }) {
+			return nil, fmt.Errorf("could not upgrade PDF: %s is already embedded besides %s", xmpConfig.FileName, file.Name)
+		}
+
+		if err = renameAttachment(ctx, file.Name, xmpConfig.FileName); err != nil {
+			return nil, err
+		}
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, fmt.Errorf("could not get catalog: %w", err)
+	}
+
+	if _, _, err = updateMetadata(ctx, catalog, xmpConfig); err != nil {
+		return nil, err
+	}
+
+	spec, err := extract.FileSpec(ctx, xmpConfig.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not get file specification of %s: %w", xmpConfig.FileName, err)
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("could not get file specification of %s: not found", xmpConfig.FileName)
+	}
+
+	specDict, err := ctx.DereferenceDict(spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not get file specification of %s: %w", xmpConfig.FileName, err)
+	}
+	if specDict == nil {
+		return nil, fmt.Errorf("could not get file specification of %s: not found", xmpConfig.FileName)
+	}
+
+	if relationships := extract.AFRelationships(xmpConfig.ConformanceLevel); !slices.Contains(relationships, file.AFRelationship) {
+		specDict.Update("AFRelationship", types.Name(relationships[0]))
+	}
+
+	if file.Subtype != "text/xml" {
+		sd, err := extract.EmbeddedFileStream(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		sd.Update("Subtype", types.Name("text/xml"))
+	}
+
+	if err = associate(ctx, catalog, spec, !file.Associated); err != nil {
+		return nil, err
+	}
+
+	if report.HasProblem(extract.ProblemVersionBelow17) {
+		catalog.Delete("Version")
+	}
+
+	logging.Step(c, logger, "upgrade", start,
+		slog.String("namespace", report.Invoice.Namespace),
+		slog.String("file_name", xmpConfig.FileName),
+		slog.String("conformance_level", xmpConfig.ConformanceLevel),
+	)
+
+	if err = c.Err(); err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	var data bytes.Buffer
+	if err = api.Write(ctx, &data, crypt.Configuration(config.UserPassword, config.OwnerPassword)); err != nil {
+		return nil, fmt.Errorf("could not write PDF: %w", err)
+	}
+	logging.Step(c, logger, "write", start, slog.Int("output_size", data.Len()))
+
+	return data.Bytes(), nil
+}
+
+// upgradedXMPConfig returns the fx values for the invoice embedded as fileName. Values the XML does
+// not determine are taken from the existing XMP metadata.
+func upgradedXMPConfig(ctx *model.Context, report *extract.Report, fileName string, config RepairConfig) (Config, error) {
+	data, err := extract.AttachmentData(ctx, fileName, config.Limits.MaxEmbeddedFileSize)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read attachment: %w", err)
+	}
+
+	document, err := extract.ReadDocumentContext(data)
+	if err != nil {
+		return Config{}, err
+	}
+
+	xmpConfig := Config{
+		XmlType:          TypeFacturX,
+		FileName:         "factur-x.xml",
+		DocumentType:     report.Invoice.DocumentType,
+		ConformanceLevel: strings.ToUpper(report.Invoice.ConformanceLevel),
+		Version:          "1.0",
+		Creator:          config.Creator,
+	}
+
+	// ZUGFeRD 1.0 called the EN 16931 profile COMFORT.
+	if xmpConfig.ConformanceLevel == "COMFORT" {
+		xmpConfig.ConformanceLevel = "EN 16931"
+	}
+
+	if level, version, ok := extract.GuidelineLevel(document.GuidelineID); ok {
+		xmpConfig.ConformanceLevel = level
+		if version != "" {
+			xmpConfig.Version = version
+		}
+	}
+
+	if document.TypeCode != "" {
+		xmpConfig.DocumentType = extract.TypeCodeDocumentType(document.TypeCode)
+	}
+
+	if xmpConfig.ConformanceLevel == "XRECHNUNG" {
+		xmpConfig.FileName = "xrechnung.xml"
+	}
+
+	xmpConfig.setDefaults()
+
+	return xmpConfig, nil
+}
+
+// renameAttachment moves the embedded file from the name tree key oldName to newName and updates the
+// file names of its file specification.
+func renameAttachment(ctx *model.Context, oldName, newName string) error {
+	spec, err := extract.FileSpec(ctx, oldName)
+	if err != nil {
+		return fmt.Errorf("could not get file specification of %s: %w", oldName, err)
+	}
+	if spec == nil {
+		return fmt.Errorf("could not get file specification of %s: not found", oldName)
+	}
+
+	d, err := ctx.DereferenceDict(spec)
+	if err != nil {
+		return fmt.Errorf("could not get file specification of %s: %w", oldName, err)
+	}
+	if d == nil {
+		return fmt.Errorf("could not get file specification of %s: not found", oldName)
+	}
+
+	d.Update("F", types.StringLiteral(newName))
+	d.Update("UF", types.StringLiteral(newName))
+
+	tree := ctx.Names["EmbeddedFiles"]
+	if err = tree.Add(ctx.XRefTable, newName, spec, nil, nil); err != nil {
+		return fmt.Errorf("could not rename attachment: %w", err)
+	}
+
+	// Without an xref table the file specification is kept, only the key is removed.
+	_, ok, err := tree.Remove(nil, oldName)
+	if err != nil {
+		return fmt.Errorf("could not rename attachment: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("could not rename attachment: %s not found", oldName)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package crypt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Error is returned when an encrypted PDF cannot be processed.
+type Error struct {
+	Reason string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("encrypted PDF: %s: %v", e.Reason, e.Err)
+	}
+	return "encrypted PDF: " + e.Reason
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Configuration returns a pdfcpu configuration that opens encrypted PDFs with the given passwords.
+func Configuration(userPassword, ownerPassword string) *model.Configuration {
+	configuration := model.NewDefaultConfiguration()
+	configuration.UserPW = userPassword
+	configuration.OwnerPW = ownerPassword
+	return configuration
+}
+
+// ReadError converts password failures reported by pdfcpu while reading into an *Error.
+// Other errors are returned unchanged.
+func ReadError(err error) error {
+	if errors.Is(err, pdfcpu.ErrWrongPassword) {
+		return &Error{Reason: "wrong or missing user password", Err: err}
+	}
+	return err
+}
+
+// Encrypted reports whether ctx was read from an encrypted PDF.
+func Encrypted(ctx *model.Context) bool {
+	return ctx.Encrypt != nil
+}
+
+// Decrypt removes the encryption from ctx so it is written unencrypted, as required by PDF/A.
+// Encryption is only removed if the caller supplied the owner or user password, so protection is never dropped
+// silently. pdfcpu opens a PDF without user password with any owner password, so the password is checked here.
+func Decrypt(ctx *model.Context) error {
+	if !Encrypted(ctx) {
+		return nil
+	}
+
+	if ctx.UserPW == "" && ctx.OwnerPW == "" {
+		return &Error{Reason: "PDF/A-3 forbids encryption, provide the owner or user password to write the PDF decrypted"}
+	}
+
+	ok, err := authenticate(ctx.E, ctx.UserPW, ctx.OwnerPW)
+	if err != nil {
+		return &Error{Reason: "could not check the password", Err: err}
+	}
+	if !ok {
+		return &Error{Reason: "wrong owner or user password, the PDF is not written decrypted"}
+	}
+
+	ctx.Encrypt = nil
+	ctx.EncKey = nil
+	ctx.E = nil
+
+	return nil
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"golang.org/x/text/secure/precis"
+	"golang.org/x/text/unicode/norm"
+)
+
+// pad is the padding string of ISO 32000-1, 7.6.3.3 Algorithm 2.
+var pad = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41, 0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80, 0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// authenticate reports whether ownerPassword is the owner password or userPassword is the user password of e.
+// Empty passwords are never accepted, because pdfcpu opens a PDF without user password with any owner password.
+// The checks follow ISO 32000-2, 7.6.4.4, the way pdfcpu validates passwords while reading.
+func authenticate(e *model.Enc, userPassword, ownerPassword string) (bool, error) {
+	if ownerPassword != "" {
+		ok, err := ownerPasswordValid(e, ownerPassword)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	if userPassword != "" {
+		return userPasswordValid(e, userPassword)
+	}
+
+	return false, nil
+}
+
+func ownerPasswordValid(e *model.Enc, password string) (bool, error) {
+	switch e.R {
+	case 5, 6:
+		if len(e.O) < 40 || len(e.U) < 48 {
+			return false, nil
+		}
+		pw, err := prepare(password)
+		if err != nil {
+			return false, err
+		}
+		hash, err := hashAES256(e.R, append(append(pw, e.O[32:40]...), e.U[:48]...), pw, e.U[:48])
+		if err != nil {
+			return false, err
+		}
+		return bytes.HasPrefix(e.O, hash), nil
+	}
+
+	// Algorithm 7: decrypting /O with the key of the owner password gives the padded user password.
+	key := rc4Key(e, password)
+	userPassword := append([]byte{}, e.O...)
+	rounds := 0
+	if e.R >= 3 {
+		rounds = 19
+	}
+	for i := rounds; i >= 0; i-- {
+		if err := xorRC4(xorKey(key, i), userPassword); err != nil {
+			return false, err
+		}
+	}
+
+	return userPasswordValid(e, string(userPassword))
+}
+
+func userPasswordValid(e *model.Enc, password string) (bool, error) {
+	switch e.R {
+	case 5, 6:
+		if len(e.U) < 40 {
+			return false, nil
+		}
+		pw, err := prepare(password)
+		if err != nil {
+			return false, err
+		}
+		hash, err := hashAES256(e.R, append(pw, e.U[32:40]...), pw, nil)
+		if err != nil {
+			return false, err
+		}
+		return bytes.HasPrefix(e.U, hash), nil
+	}
+
+	// Algorithms 4 and 5 compute /U from the user password.
+	key := fileKey(e, password)
+	if e.R == 2 {
+		u := append([]byte{}, pad...)
+		if err := xorRC4(key, u); err != nil {
+			return false, err
+		}
+		return bytes.Equal(e.U, u), nil
+	}
+
+	h := md5.New()
+	h.Write(pad)
+	h.Write(e.ID)
+	u := h.Sum(nil)
+	for i := 0; i <= 19; i++ {
+		if err := xorRC4(xorKey(key, i), u); err != nil {
+			return false, err
+		}
+	}
+	return bytes.HasPrefix(e.U, u), nil
+}
+
+// fileKey computes the file encryption key from a user password with Algorithm 2.
+func fileKey(e *model.Enc, password string) []byte {
+	h := md5.New()
+	h.Write(padded(password))
+	h.Write(e.O)
+	p := uint32(e.P)
+	h.Write([]byte{byte(p), byte(p >> 8), byte(p >> 16), byte(p >> 24)})
+	h.Write(e.ID)
+	if e.R == 4 && !e.Emd {
+		h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+	return stretch(e, h.Sum(nil))
+}
+
+// rc4Key computes the key that encrypts /O from an owner password with Algorithm 3.
+func rc4Key(e *model.Enc, password string) []byte {
+	sum := md5.Sum(padded(password))
+	return stretch(e, sum[:])
+}
+
+// stretch rehashes key 50 times for revision 3 and later and cuts it to the key length.
+func stretch(e *model.Enc, key []byte) []byte {
+	n := 5
+	if e.R >= 3 {
+		n = e.L / 8
+		if n < 5 || n > len(key) {
+			n = len(key)
+		}
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(key[:n])
+			key = sum[:]
+		}
+	}
+	return key[:n]
+}
+
+func padded(password string) []byte {
+	pw := []byte(password)
+	if len(pw) >= 32 {
+		return pw[:32]
+	}
+	return append(pw, pad[:32-len(pw)]...)
+}
+
+func xorKey(key []byte, i int) []byte {
+	k := make([]byte, len(key))
+	for j := range key {
+		k[j] = key[j] ^ byte(i)
+	}
+	return k
+}
+
+func xorRC4(key, data []byte) error {
+	c, err := rc4.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	c.XORKeyStream(data, data)
+	return nil
+}
+
+// prepare processes an AES-256 password with SASLprep and truncates it to 127 bytes.
+func prepare(password string) ([]byte, error) {
+	pw, err := precis.NewIdentifier(precis.BidiRule, precis.Norm(norm.NFKC)).Bytes([]byte(password))
+	if err != nil {
+		return nil, err
+	}
+	if len(pw) > 127 {
+		pw = pw[:127]
+	}
+	return pw, nil
+}
+
+// hashAES256 computes the 32 byte password hash of revision 5 (SHA-256) or revision 6 (Algorithm 2.B).
+func hashAES256(r int, input, password, u []byte) ([]byte, error) {
+	sum := sha256.Sum256(input)
+	k := sum[:]
+	if r == 5 {
+		return k, nil
+	}
+
+	three := big.NewInt(3)
+	var e []byte
+	for round := 0; round < 64 || int(e[len(e)-1]) > round-32; round++ {
+		block := append(append(append([]byte{}, password...), k...), u...)
+		k1 := bytes.Repeat(block, 64)
+
+		c, err := aes.NewCipher(k[:16])
+		if err != nil {
+			return nil, err
+		}
+		e = make([]byte, len(k1))
+		cipher.NewCBCEncrypter(c, k[16:32]).CryptBlocks(e, k1)
+
+		switch new(big.Int).Mod(new(big.Int).SetBytes(e[:16]), three).Uint64() {
+		case 0:
+			sum := sha256.Sum256(e)
+			k = sum[:]
+		case 1:
+			sum := sha512.Sum384(e)
+			k = sum[:]
+		case 2:
+			sum := sha512.Sum512(e)
+			k = sum[:]
+		}
+	}
+
+	return k[:32], nil
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"fmt"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxNameTreeDepth bounds the nesting of name tree nodes, which also stops at cyclic /Kids.
+const maxNameTreeDepth = 32
+
+// LoadEmbeddedFiles builds the EmbeddedFiles name tree from the catalog. pdfcpu only fills name trees
+// while validating the whole PDF, which for a scan of hundreds of pages costs far more than reading
+// the few objects of the tree.
+func LoadEmbeddedFiles(ctx *model.Context) error {
+	if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+		return err
+	}
+
+	root := ctx.Names["EmbeddedFiles"]
+	if root == nil {
+		return nil
+	}
+
+	node, err := loadNameTree(ctx.XRefTable, root.D, 0)
+	if err != nil {
+		return err
+	}
+
+	ctx.Names["EmbeddedFiles"] = node
+	return nil
+}
+
+// loadNameTree reads the node d and its kids with their key ranges like the validation of pdfcpu.
+func loadNameTree(xRefTable *model.XRefTable, d types.Dict, depth int) (*model.Node, error) {
+	if depth > maxNameTreeDepth {
+		return nil, fmt.Errorf("name tree is nested deeper than %d levels", maxNameTreeDepth)
+	}
+
+	node := &model.Node{D: d}
+
+	if o, found := d.Find("Kids"); found {
+		kids, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range kids {
+			kid, err := xRefTable.DereferenceDict(o)
+			if err != nil {
+				return nil, err
+			}
+			if kid == nil {
+				continue
+			}
+
+			child, err := loadNameTree(xRefTable, kid, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			node.Kids = append(node.Kids, child)
+			if node.Kmin == "" {
+				node.Kmin = child.Kmin
+			}
+			node.Kmax = child.Kmax
+		}
+
+		return node, nil
+	}
+
+	names, err := xRefTable.DereferenceArray(d["Names"])
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+1 < len(names); i += 2 {
+		o, err := xRefTable.Dereference(names[i])
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := types.StringOrHexLiteral(o)
+		if err != nil {
+			return nil, err
+		}
+
+		node.AppendToNames(*key, names[i+1])
+		if node.Kmin == "" {
+			node.Kmin = *key
+		}
+		node.Kmax = *key
+	}
+
+	return node, nil
+}
+
+// FileSpec returns the file specification registered under fileName in the EmbeddedFiles name tree,
+// or nil if there is none.
+func FileSpec(ctx *model.Context, fileName string) (types.Object, error) {
+	if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+		return nil, err
+	}
+
+	tree := ctx.Names["EmbeddedFiles"]
+	if tree == nil {
+		return nil, nil
+	}
+
+	var spec types.Object
+	err := tree.Process(ctx.XRefTable, func(_ *model.XRefTable, k string, v *types.Object) error {
+		if k == fileName {
+			spec = *v
+		}
+		return nil
+	})
+
+	return spec, err
+}
+
+// EmbeddedFileStream returns the embedded file stream of a file specification.
+func EmbeddedFileStream(ctx *model.Context, spec types.Object) (*types.StreamDict, error) {
+	d, err := ctx.DereferenceDict(spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not get file specification: %w", err)
+	}
+	if d == nil {
+		return nil, fmt.Errorf("could not get file specification: not found")
+	}
+
+	ef, err := ctx.DereferenceDict(d["EF"])
+	if err != nil {
+		return nil, fmt.Errorf("could not get embedded file: %w", err)
+	}
+	if ef == nil {
+		return nil, fmt.Errorf("could not get embedded file: not found")
+	}
+
+	obj, found := ef.Find("F")
+	if !found {
+		obj, found = ef.Find("UF")
+	}
+	if !found {
+		return nil, fmt.Errorf("file specification has no embedded file")
+	}
+
+	sd, _, err := ctx.DereferenceStreamDict(obj)
+	if err != nil {
+		return nil, fmt.Errorf("could not get embedded file stream: %w", err)
+	}
+	if sd == nil {
+		return nil, fmt.Errorf("could not get embedded file stream: not found")
+	}
+
+	return sd, nil
+}
+
+// AttachmentData decodes the embedded file registered under fileName. Decoding stops with an error
+// wrapping limits.ErrExceeded once the data exceeds max bytes, unless max is 0.
+func AttachmentData(ctx *model.Context, fileName string, max int64) ([]byte, error) {
+	spec, err := FileSpec(ctx, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read embedded files: %w", err)
+	}
+
+	if spec == nil {
+		return nil, fmt.Errorf("no %s files found", fileName)
+	}
+
+	sd, err := EmbeddedFileStream(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return limits.Decode(sd, max, "embedded file "+fileName)
+}
+
+// attachmentObjects returns the object numbers of the file specification and embedded file stream
+// registered under fileName in the EmbeddedFiles name tree.
+func attachmentObjects(ctx *model.Context, fileName string) ([]int, error) {
+	spec, err := FileSpec(ctx, fileName)
+	if err != nil || spec == nil {
+		return nil, err
+	}
+
+	var objNrs []int
+	if ref, ok := spec.(types.IndirectRef); ok {
+		objNrs = append(objNrs, ref.ObjectNumber.Value())
+	}
+
+	d, err := ctx.DereferenceDict(spec)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	ef, err := ctx.DereferenceDict(d["EF"])
+	if err != nil || ef == nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"F", "UF"} {
+		if ref, ok := ef[key].(types.IndirectRef); ok {
+			objNrs = append(objNrs, ref.ObjectNumber.Value())
+		}
+	}
+
+	return objNrs, nil
+}
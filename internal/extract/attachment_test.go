@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namesPDF writes a PDF of one empty page whose EmbeddedFiles name tree consists of the given
+// objects, starting with object 4.
+func namesPDF(objects ...string) []byte {
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Names << /EmbeddedFiles 4 0 R >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>",
+	}, objects...)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+// readers are the functions reading a PDF for extraction, which have to load the same name tree.
+var readers = map[string]func(context.Context, io.ReadSeeker, Config) (*model.Context, error){
+	"ReadEmbeddedFiles": ReadEmbeddedFiles,
+	"ReadLazy":          ReadLazy,
+}
+
+func TestReadEmbeddedFiles(t *testing.T) {
+	pdf := namesPDF(
+		"<< /Kids [5 0 R 6 0 R] >>",
+		"<< /Limits [(a.xml) (b.xml)] /Names [(a.xml) 7 0 R (b.xml) 8 0 R] >>",
+		"<< /Limits [(factur-x.xml) (factur-x.xml)] /Names [(factur-x.xml) 9 0 R] >>",
+		"<< /Type /Filespec /F (a.xml) >>",
+		"<< /Type /Filespec /F (b.xml) >>",
+		"<< /Type /Filespec /F (factur-x.xml) >>",
+	)
+
+	for name, read := range readers {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := read(context.Background(), bytes.NewReader(pdf), Config{})
+			require.NoError(t, err)
+
+			tree := ctx.Names["EmbeddedFiles"]
+			require.NotNil(t, tree)
+			assert.Equal(t, "a.xml", tree.Kmin)
+			assert.Equal(t, "factur-x.xml", tree.Kmax)
+
+			for name, objNr := range map[string]int{"a.xml": 7, "b.xml": 8, "factur-x.xml": 9} {
+				spec, err := FileSpec(ctx, name)
+				require.NoError(t, err)
+				assert.Equal(t, *types.NewIndirectRef(objNr, 0), spec, name)
+
+				d, err := ctx.DereferenceDict(spec)
+				require.NoError(t, err)
+				assert.Equal(t, &name, d.StringEntry("F"))
+			}
+
+			spec, err := FileSpec(ctx, "c.xml")
+			require.NoError(t, err)
+			assert.Nil(t, spec)
+		})
+	}
+}
+
+func TestReadEmbeddedFiles_Cycle(t *testing.T) {
+	pdf := namesPDF("<< /Kids [4 0 R] >>")
+
+	for name, read := range readers {
+		t.Run(name, func(t *testing.T) {
+			_, err := read(context.Background(), bytes.NewReader(pdf), Config{})
+			assert.ErrorContains(t, err, "nested deeper than 32 levels")
+		})
+	}
+}
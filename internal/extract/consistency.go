@@ -0,0 +1,185 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// ErrInconsistent is returned in strict mode when the XMP metadata contradicts the embedded XML or
+// its file specification.
+var ErrInconsistent = errors.New("inconsistent hybrid invoice")
+
+// DocumentContext holds the values of a CII document that the XMP metadata describes.
+type DocumentContext struct {
+	GuidelineID string
+	TypeCode    string
+}
+
+// ReadDocumentContext reads the guideline ID (BT-24) and document type code (BT-3) of a CII invoice.
+// Values are left empty if the XML is not CII.
+func ReadDocumentContext(data []byte) (DocumentContext, error) {
+	var (
+		out     DocumentContext
+		path    []string
+		decoder = xml.NewDecoder(bytes.NewReader(data))
+	)
+	// Only ASCII element names and codes are read, which every encoding of a real invoice preserves.
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	for out.GuidelineID == "" || out.TypeCode == "" {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return out, fmt.Errorf("could not parse XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		case xml.CharData:
+			switch {
+			case endsWith(path, "ExchangedDocumentContext", "GuidelineSpecifiedDocumentContextParameter", "ID"):
+				out.GuidelineID = strings.TrimSpace(string(t))
+			case endsWith(path, "CrossIndustryInvoice", "ExchangedDocument", "TypeCode"):
+				out.TypeCode = strings.TrimSpace(string(t))
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func endsWith(path []string, suffix ...string) bool {
+	return len(path) >= len(suffix) && slices.Equal(path[len(path)-len(suffix):], suffix)
+}
+
+var xRechnungGuideline = regexp.MustCompile(`(?i)xrechnung_(\d+\.\d+(\.\d+)?)`)
+
+// GuidelineLevel maps a guideline ID to the XMP conformance level and, for XRechnung, the version.
+func GuidelineLevel(id string) (level, version string, ok bool) {
+	lower := strings.ToLower(id)
+
+	switch {
+	case strings.Contains(lower, "xrechnung"):
+		if m := xRechnungGuideline.FindStringSubmatch(id); m != nil {
+			version = m[1]
+		}
+		return "XRECHNUNG", version, true
+	case strings.HasSuffix(lower, ":minimum"):
+		return "MINIMUM", "", true
+	case strings.HasSuffix(lower, ":basicwl"):
+		return "BASIC WL", "", true
+	case strings.HasSuffix(lower, ":basic"):
+		return "BASIC", "", true
+	case strings.HasSuffix(lower, ":extended"):
+		return "EXTENDED", "", true
+	case lower == "urn:cen.eu:en16931:2017":
+		return "EN 16931", "", true
+	default:
+		return "", "", false
+	}
+}
+
+// TypeCodeDocumentType maps UNTDID 1001 codes of orders to the XMP document type. All other codes
+// are invoices, credit notes or corrections.
+func TypeCodeDocumentType(code string) string {
+	switch code {
+	case "220":
+		return "ORDER"
+	case "230":
+		return "ORDER_CHANGE"
+	case "231":
+		return "ORDER_RESPONSE"
+	default:
+		return "INVOICE"
+	}
+}
+
+// AFRelationships returns the AFRelationship values allowed for a conformance level, the preferred
+// one first. Factur-X requires Data for the profiles that are no complete invoice.
+func AFRelationships(level string) []string {
+	if level == "MINIMUM" || level == "BASIC WL" {
+		return []string{"Data"}
+	}
+	return []string{"Alternative", "Source"}
+}
+
+// consistencyWarnings compares the XMP values of out with the embedded XML and its file specification.
+func consistencyWarnings(ctx *model.Context, out *Output) ([]string, error) {
+	var warnings []string
+
+	document, err := ReadDocumentContext(out.Data)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
+	if document.GuidelineID != "" {
+		level, version, ok := GuidelineLevel(document.GuidelineID)
+		switch {
+		case !ok:
+			warnings = append(warnings, fmt.Sprintf("unknown guideline ID %q", document.GuidelineID))
+		case !strings.EqualFold(level, out.ConformanceLevel):
+			warnings = append(warnings, fmt.Sprintf("XMP conformance level %q does not match guideline ID %q (%s)",
+				out.ConformanceLevel, document.GuidelineID, level))
+		case version != "" && version != out.Version:
+			warnings = append(warnings, fmt.Sprintf("XMP version %q does not match XRechnung version %q of the guideline ID",
+				out.Version, version))
+		}
+	}
+
+	if document.TypeCode != "" {
+		if documentType := TypeCodeDocumentType(document.TypeCode); documentType != out.DocumentType {
+			warnings = append(warnings, fmt.Sprintf("XMP document type %q does not match type code %s (%s)",
+				out.DocumentType, document.TypeCode, documentType))
+		}
+	}
+
+	spec, err := FileSpec(ctx, out.FileName)
+	if err != nil || spec == nil {
+		return warnings, err
+	}
+
+	d, err := ctx.DereferenceDict(spec)
+	if err != nil || d == nil {
+		return warnings, err
+	}
+
+	for _, key := range []string{"UF", "F"} {
+		if name := text(ctx, d[key]); name != "" && name != out.FileName {
+			warnings = append(warnings, fmt.Sprintf("XMP file name %q differs from /%s %q of the file specification",
+				out.FileName, key, name))
+		}
+	}
+
+	var relationship string
+	if af := d.NameEntry("AFRelationship"); af != nil {
+		relationship = *af
+	}
+
+	expected := AFRelationships(out.ConformanceLevel)
+	switch {
+	case relationship == "":
+		warnings = append(warnings, fmt.Sprintf("file specification of %s has no AFRelationship", out.FileName))
+	case !slices.Contains(expected, relationship):
+		warnings = append(warnings, fmt.Sprintf("AFRelationship %s is not allowed for %s, use %s",
+			relationship, out.ConformanceLevel, strings.Join(expected, " or ")))
+	}
+
+	return warnings, nil
+}
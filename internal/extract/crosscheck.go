@@ -0,0 +1,413 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/MarlinKuhn/gopdfattach/internal/logging"
+	"github.com/MarlinKuhn/gopdfattach/internal/pdftext"
+)
+
+// CrossCheckReport compares key values of the embedded invoice XML with the visible text of the
+// PDF. A value that no page shows is a mismatch: the human-readable invoice and the XML disagree.
+type CrossCheckReport struct {
+	FileName string         `json:"file_name"` // embedded XML the values are taken from
+	Pages    int            `json:"pages"`
+	Values   []CheckedValue `json:"values"`
+}
+
+// CheckedValue is an XML value looked up in the visible text.
+type CheckedValue struct {
+	Field CrossCheckField `json:"field"`
+	Value string          `json:"value"` // as written in the XML, dates as YYYY-MM-DD
+	Pages []int           `json:"pages"` // pages showing the value, empty on a mismatch
+
+	// Shown lists what the pages show next to a label of the field instead, e.g. the amount after
+	// "Total". It is only filled on a mismatch and can be empty if no label is recognised.
+	Shown []ShownValue `json:"shown,omitempty"`
+}
+
+// ShownValue is a value found next to a label in the visible text.
+type ShownValue struct {
+	Page  int    `json:"page"`
+	Label string `json:"label"`
+	Text  string `json:"text"`
+}
+
+// CrossCheckField names an invoice value that is compared with the visible text.
+type CrossCheckField string
+
+const (
+	FieldInvoiceNumber CrossCheckField = "invoice_number" // BT-1
+	FieldIssueDate     CrossCheckField = "issue_date"     // BT-2
+	FieldDeliveryDate  CrossCheckField = "delivery_date"  // BT-72
+	FieldDueDate       CrossCheckField = "due_date"       // BT-9
+	FieldGrandTotal    CrossCheckField = "grand_total"    // BT-112
+	FieldDuePayable    CrossCheckField = "due_payable"    // BT-115
+	FieldIBAN          CrossCheckField = "iban"           // BT-84
+)
+
+// Mismatch reports whether no page shows the value.
+func (v CheckedValue) Mismatch() bool {
+	return len(v.Pages) == 0
+}
+
+// Mismatches returns the values that no page shows.
+func (r *CrossCheckReport) Mismatches() []CheckedValue {
+	var out []CheckedValue
+	for _, v := range r.Values {
+		if v.Mismatch() {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// CrossCheck reads a PDF with Read, parses its embedded CII invoice and looks up the invoice
+// number, dates, grand total, due payable amount and IBANs in the text of its pages. Amounts and
+// dates are matched in the usual notations, e.g. 1190.00, 1,190.00 and 1.190,00 or 2018-03-05,
+// 05.03.2018 and 5 March 2018.
+func CrossCheck(c context.Context, reader io.ReadSeeker, config Config) (*CrossCheckReport, error) {
+	logger := logging.OrDiscard(config.Logger)
+
+	ctx, err := Read(c, reader, config)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := InspectContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	file := report.InvoiceFile()
+	if file == nil {
+		return nil, fmt.Errorf("could not find the invoice XML named by the XMP metadata")
+	}
+
+	data, err := AttachmentData(ctx, file.Name, maxAttachmentSize(config.Limits))
+	if err != nil {
+		return nil, fmt.Errorf("could not read attachment: %w", err)
+	}
+
+	invoice, err := cii.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	pages, err := pdftext.Pages(c, ctx, config.Limits.MaxDecompressedSize)
+	if err != nil {
+		return nil, err
+	}
+	logging.Step(c, logger, "extract text", start, slog.Int("pages", len(pages)))
+
+	return &CrossCheckReport{
+		FileName: file.Name,
+		Pages:    len(pages),
+		Values:   crossCheck(invoice, pages),
+	}, nil
+}
+
+func crossCheck(invoice *cii.Invoice, pages []string) []CheckedValue {
+	var values []CheckedValue
+
+	if invoice.ID != "" {
+		values = append(values, checkValue(FieldInvoiceNumber, invoice.ID, pages, idMatcher(invoice.ID)))
+	}
+
+	for _, d := range []struct {
+		field CrossCheckField
+		date  time.Time
+	}{
+		{FieldIssueDate, invoice.IssueDate},
+		{FieldDeliveryDate, invoice.DeliveryDate},
+		{FieldDueDate, invoice.DueDate},
+	} {
+		if !d.date.IsZero() {
+			values = append(values, checkValue(d.field, d.date.Format(time.DateOnly), pages, dateMatcher(d.date)))
+		}
+	}
+
+	for _, a := range []struct {
+		field  CrossCheckField
+		amount cii.Decimal
+	}{
+		{FieldGrandTotal, invoice.Totals.GrandTotal},
+		{FieldDuePayable, invoice.Totals.DuePayable},
+	} {
+		if r, ok := a.amount.Rat(); ok {
+			values = append(values, checkValue(a.field, string(a.amount), pages, amountMatcher(r)))
+		}
+	}
+
+	var ibans []string
+	for _, means := range invoice.PaymentMeans {
+		iban := strings.ToUpper(strings.Join(strings.Fields(means.IBAN), ""))
+		if iban != "" && !slices.Contains(ibans, iban) {
+			ibans = append(ibans, iban)
+			values = append(values, checkValue(FieldIBAN, iban, pages, idMatcher(iban)))
+		}
+	}
+
+	return values
+}
+
+// matcher reports whether a text contains a value.
+type matcher func(text string) bool
+
+func checkValue(field CrossCheckField, value string, pages []string, match matcher) CheckedValue {
+	v := CheckedValue{Field: field, Value: value}
+	for i, text := range pages {
+		if match(text) {
+			v.Pages = append(v.Pages, i+1)
+		}
+	}
+
+	if v.Mismatch() {
+		v.Shown = shownValues(field, pages)
+	}
+	return v
+}
+
+// idMatcher matches an identifier as a whole word, ignoring case and whitespace inside it, which
+// IBANs are usually grouped with.
+func idMatcher(id string) matcher {
+	var pattern strings.Builder
+	pattern.WriteString(`(?i)(?:^|[^\p{L}\p{N}])`)
+	for i, r := range strings.Join(strings.Fields(id), "") {
+		if i > 0 {
+			pattern.WriteString(`\s*`)
+		}
+		pattern.WriteString(regexp.QuoteMeta(string(r)))
+	}
+	pattern.WriteString(`(?:$|[^\p{L}\p{N}])`)
+
+	re := regexp.MustCompile(pattern.String())
+	return re.MatchString
+}
+
+// amountMatcher matches the absolute value of amount, as credit notes often show amounts without
+// their sign.
+func amountMatcher(amount *big.Rat) matcher {
+	want := new(big.Rat).Abs(amount)
+	return func(text string) bool {
+		for _, token := range numberPattern.FindAllString(text, -1) {
+			for _, v := range numberValues(token) {
+				if v.Cmp(want) == 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+var numberPattern = regexp.MustCompile(`\d+(?:[.,'’]\d+)*`)
+
+// numberValues interprets a number token. The last separator is a decimal separator unless it
+// repeats; separators between groups of three digits can also be thousands separators, so
+// "1.190" yields both 1.19 and 1190.
+func numberValues(token string) []*big.Rat {
+	parts := strings.FieldsFunc(token, func(r rune) bool { return r < '0' || r > '9' })
+	seps := strings.FieldsFunc(token, func(r rune) bool { return r >= '0' && r <= '9' })
+
+	rat := func(s string) *big.Rat {
+		r, _ := new(big.Rat).SetString(s)
+		return r
+	}
+
+	if len(seps) == 0 {
+		return []*big.Rat{rat(token)}
+	}
+
+	grouped := func(seps []string, groups []string) bool {
+		if len(groups[0]) > 3 {
+			return false
+		}
+		for i, g := range groups[1:] {
+			if len(g) != 3 || seps[i] != seps[0] {
+				return false
+			}
+		}
+		return true
+	}
+
+	var values []*big.Rat
+
+	// The last separator as decimal separator, the others as thousands separators.
+	last := seps[len(seps)-1]
+	if (last == "." || last == ",") && !slices.Contains(seps[:len(seps)-1], last) &&
+		(len(seps) == 1 || grouped(seps[:len(seps)-1], parts[:len(parts)-1])) {
+		values = append(values, rat(strings.Join(parts[:len(parts)-1], "")+"."+parts[len(parts)-1]))
+	}
+
+	// All separators as thousands separators.
+	if grouped(seps, parts) {
+		values = append(values, rat(strings.Join(parts, "")))
+	}
+
+	return values
+}
+
+// dateMatcher matches date in ISO, numeric day-month-year and month-day-year notations and with
+// English, German or French month names.
+func dateMatcher(date time.Time) matcher {
+	y, m, d := date.Date()
+	return func(text string) bool {
+		return slices.ContainsFunc(datesIn(text), func(t [3]int) bool {
+			return t == [3]int{y, int(m), d}
+		})
+	}
+}
+
+var (
+	isoDate     = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	numericDate = regexp.MustCompile(`\b(\d{1,2})\s?([./-])\s?(\d{1,2})\s?[./-]\s?(\d{4}|\d{2})\b`)
+	dayMonth    = regexp.MustCompile(`(?i)\b(\d{1,2})\.?\s+([\p{L}]+)\.?,?\s+(\d{4})\b`)
+	monthDay    = regexp.MustCompile(`(?i)\b([\p{L}]+)\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+)
+
+var monthNames = map[string]int{
+	"january": 1, "february": 2, "march": 3, "april": 4, "may": 5, "june": 6, "july": 7,
+	"august": 8, "september": 9, "october": 10, "november": 11, "december": 12,
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "sept": 9,
+	"oct": 10, "nov": 11, "dec": 12,
+	"januar": 1, "jänner": 1, "februar": 2, "märz": 3, "mai": 5, "juni": 6, "juli": 7,
+	"oktober": 10, "dezember": 12, "mär": 3, "okt": 10, "dez": 12,
+	"janvier": 1, "février": 2, "mars": 3, "avril": 4, "juin": 6, "juillet": 7, "août": 8,
+	"septembre": 9, "octobre": 10, "novembre": 11, "décembre": 12,
+}
+
+// datesIn returns every date found in text as year, month and day. Ambiguous numeric dates with
+// slashes are returned in both day-month and month-day order.
+func datesIn(text string) [][3]int {
+	var dates [][3]int
+	add := func(y, m, d int) {
+		if y < 100 {
+			y += 2000
+		}
+		if m >= 1 && m <= 12 && d >= 1 && d <= 31 {
+			dates = append(dates, [3]int{y, m, d})
+		}
+	}
+
+	for _, g := range isoDate.FindAllStringSubmatch(text, -1) {
+		add(atoi(g[1]), atoi(g[2]), atoi(g[3]))
+	}
+	for _, g := range numericDate.FindAllStringSubmatch(text, -1) {
+		add(atoi(g[4]), atoi(g[3]), atoi(g[1]))
+		if g[2] == "/" {
+			add(atoi(g[4]), atoi(g[1]), atoi(g[3]))
+		}
+	}
+	for _, g := range dayMonth.FindAllStringSubmatch(text, -1) {
+		if m, ok := monthNames[strings.ToLower(g[2])]; ok {
+			add(atoi(g[3]), m, atoi(g[1]))
+		}
+	}
+	for _, g := range monthDay.FindAllStringSubmatch(text, -1) {
+		if m, ok := monthNames[strings.ToLower(g[1])]; ok {
+			add(atoi(g[3]), m, atoi(g[2]))
+		}
+	}
+
+	return dates
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// fieldLabels are the German, English and French labels invoices put in front of a field. The
+// value after a label on the same line is reported when the XML value is not found.
+var fieldLabels = map[CrossCheckField]*regexp.Regexp{
+	FieldInvoiceNumber: regexp.MustCompile(`(?i)rechnungs-?(?:nummer|nr\.?)|invoice\s*(?:number|no\.?|#)|num[ée]ro de facture|facture\s*n[°o]\.?|\bnr\.|\bnumber\b|\bno\.`),
+	FieldIssueDate:     regexp.MustCompile(`(?i)rechnungsdatum|invoice\s*date|date de facture|\bdatum\b|\bdate\b|\bvom\b`),
+	FieldDeliveryDate:  regexp.MustCompile(`(?i)liefer(?:-\s*und\s*leistungs)?datum|leistungsdatum|delivery\s*date|date de livraison`),
+	FieldDueDate:       regexp.MustCompile(`(?i)fälligkeitsdatum|fällig\s*am|zahlbar\s*bis|due\s*date|payable\s*by|date d'échéance|échéance`),
+	FieldGrandTotal:    regexp.MustCompile(`(?i)bruttosumme|bruttobetrag|rechnungsbetrag|endbetrag|grand\s*total|total\s*ttc|\btotal\b`),
+	FieldDuePayable:    regexp.MustCompile(`(?i)zahlbetrag|zu\s*zahlen|amount\s*due|due\s*payable|balance\s*due|net à payer|montant dû`),
+	FieldIBAN:          regexp.MustCompile(`\bIBAN\b`),
+}
+
+// fieldValues extract the value of a field from the text after its label.
+var (
+	wordValue   = regexp.MustCompile(`^[\s:#]*([\p{L}\p{N}][\p{L}\p{N}/._-]*)`)
+	amountValue = regexp.MustCompile(`^\D*?(-?\d+(?:[.,'’]\d+)*)`)
+	dateValue   = regexp.MustCompile(`^\D*?(\d{4}-\d{1,2}-\d{1,2}|\d{1,2}\s?[./-]\s?\d{1,2}\s?[./-]\s?\d{2,4}|\d{1,2}\.?\s+\p{L}+\.?\s+\d{4})`)
+	ibanValue   = regexp.MustCompile(`^[\s:]*([A-Z]{2}\d{2}(?: ?[A-Z0-9]{1,4})+)`)
+)
+
+func shownValues(field CrossCheckField, pages []string) []ShownValue {
+	var value *regexp.Regexp
+	switch field {
+	case FieldInvoiceNumber:
+		value = wordValue
+	case FieldIssueDate, FieldDeliveryDate, FieldDueDate:
+		value = dateValue
+	case FieldGrandTotal, FieldDuePayable:
+		value = amountValue
+	case FieldIBAN:
+		value = ibanValue
+	}
+
+	var shown []ShownValue
+	for i, text := range pages {
+		for _, line := range strings.Split(text, "\n") {
+			for _, loc := range fieldLabels[field].FindAllStringIndex(line, -1) {
+				m := value.FindStringSubmatch(line[loc[1]:])
+				if m == nil {
+					continue
+				}
+
+				v := ShownValue{Page: i + 1, Label: line[loc[0]:loc[1]], Text: strings.TrimSpace(m[1])}
+				if !slices.Contains(shown, v) {
+					shown = append(shown, v)
+				}
+			}
+		}
+	}
+	return shown
+}
+
+// WriteText writes the report in a human-readable form.
+func (r *CrossCheckReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Invoice XML:\t%s\n", r.FileName)
+	fmt.Fprintf(tw, "Pages:\t%d\n", r.Pages)
+
+	for _, v := range r.Values {
+		if !v.Mismatch() {
+			pages := make([]string, len(v.Pages))
+			for i, p := range v.Pages {
+				pages[i] = strconv.Itoa(p)
+			}
+			fmt.Fprintf(tw, "%s:\t%s\tshown on page %s\n", v.Field, v.Value, strings.Join(pages, ", "))
+			continue
+		}
+
+		fmt.Fprintf(tw, "%s:\t%s\tNOT SHOWN\n", v.Field, v.Value)
+		for _, s := range v.Shown {
+			fmt.Fprintf(tw, "\t\tpage %d shows %q after %q\n", s.Page, s.Text, s.Label)
+		}
+	}
+
+	return tw.Flush()
+}
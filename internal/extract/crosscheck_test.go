@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmountMatcher(t *testing.T) {
+	match := amountMatcher(big.NewRat(119000, 100))
+
+	for _, text := range []string{
+		"Total 1190.00 EUR",
+		"Total 1190,00 €",
+		"Gesamt: 1.190,00",
+		"Grand total 1,190.00",
+		"Total CHF 1'190.00",
+		"Zahlbetrag -1.190,00",
+		"1190",
+	} {
+		assert.True(t, match(text), text)
+	}
+
+	for _, text := range []string{
+		"Total 1191.00",
+		"Total 11900.00",
+		"Total 1.190.000,00",
+		"Total 119,00",
+	} {
+		assert.False(t, match(text), text)
+	}
+}
+
+func TestDateMatcher(t *testing.T) {
+	match := dateMatcher(time.Date(2018, time.March, 5, 0, 0, 0, 0, time.UTC))
+
+	for _, text := range []string{
+		"Datum 2018-03-05",
+		"vom 05.03.2018",
+		"vom 5.3.2018",
+		"vom 05.03.18",
+		"Date 05/03/2018",
+		"Date 03/05/2018",
+		"Date 5 March 2018",
+		"Datum 5. März 2018",
+		"Date March 5th, 2018",
+		"le 5 mars 2018",
+	} {
+		assert.True(t, match(text), text)
+	}
+
+	for _, text := range []string{
+		"Datum 2018-05-03",
+		"vom 05.04.2018",
+		"Date 5 May 2018",
+	} {
+		assert.False(t, match(text), text)
+	}
+}
+
+func TestIDMatcher(t *testing.T) {
+	assert.True(t, idMatcher("DE02120300000000202051")("IBAN: DE02 1203 0000 0000 2020 51"))
+	assert.True(t, idMatcher("471102")("Nr. 471102 vom"))
+	assert.False(t, idMatcher("471102")("Nr. 4711023"))
+	assert.False(t, idMatcher("RE-1")("RE-12"))
+}
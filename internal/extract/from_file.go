@@ -5,13 +5,20 @@
 package extract
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/MarlinKuhn/gopdfattach/internal/crypt"
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/MarlinKuhn/gopdfattach/internal/logging"
 	_ "github.com/MarlinKuhn/gopdfattach/internal/xsd"
 	"github.com/MarlinKuhn/gopdfattach/internal/xsd/fx"
 	"github.com/MarlinKuhn/gopdfattach/internal/xsd/zf"
-	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/validate"
@@ -32,27 +39,206 @@ type Output struct {
 	Version          string
 	ConformanceLevel string
 	Data             []byte
+	Signatures       []Signature
+	Warnings         []string // where the XMP metadata contradicts the XML or its file specification, or unreadable signatures
+}
+
+// ErrNoInvoiceXMP is returned when the PDF has no XMP metadata or the metadata has no Factur-X or
+// ZUGFeRD description naming the invoice.
+var ErrNoInvoiceXMP = errors.New("no Factur-X or ZUGFeRD XMP metadata")
+
+// Config holds the passwords used to open encrypted PDFs, the resource limits and the logger.
+type Config struct {
+	UserPassword  string
+	OwnerPassword string
+	Limits        limits.Limits
+	Logger        *slog.Logger // nil disables logging
+
+	// Strict turns consistency warnings into an error wrapping ErrInconsistent.
+	Strict bool
+
+	// FullRead makes FromReader read the whole PDF right away instead of trying ReadLazy first.
+	FullRead bool
 }
 
 // FromReader extracts the embedded zugferd or x-rechnung from a PDF.
-func FromReader(reader io.ReadSeeker) (*Output, error) {
-	ctx, err := api.ReadContext(reader, model.NewDefaultConfiguration())
+// Reading stops once c is cancelled or a limit is exceeded.
+//
+// Only the objects extraction needs are read with ReadLazy. If that fails, e.g. because the PDF is
+// encrypted or its cross-reference table is broken, the whole PDF is read with ReadEmbeddedFiles.
+func FromReader(c context.Context, reader io.ReadSeeker, config Config) (*Output, error) {
+	logger := logging.OrDiscard(config.Logger)
+
+	ctx, err := readForExtraction(c, reader, config)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	doc, err := catalogXMP(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc == nil {
+		return nil, ErrNoInvoiceXMP
+	}
+
+	var out Output
+	if makeModel := fx.FindModel(doc); makeModel != nil {
+		out.FileName = makeModel.DocumentFileName
+		out.DocumentType = makeModel.DocumentType
+		out.ConformanceLevel = makeModel.ConformanceLevel
+		out.Version = makeModel.Version
+		out.FileType = FacturX
+	} else if zfModel := zf.FindModel(doc); zfModel != nil {
+		out.FileName = zfModel.DocumentFileName
+		out.DocumentType = zfModel.DocumentType
+		out.ConformanceLevel = zfModel.ConformanceLevel
+		out.Version = zfModel.Version
+		out.FileType = Zugferd
+	} else {
+		return nil, ErrNoInvoiceXMP
+	}
+
+	if out.FileName == "" {
+		return nil, fmt.Errorf("could not find file name")
+	}
+
+	logging.Step(c, logger, "read xmp", start,
+		slog.String("file_name", out.FileName),
+		slog.String("version", out.Version),
+		slog.String("conformance_level", out.ConformanceLevel),
+	)
+
+	start = time.Now()
+	out.Data, err = AttachmentData(ctx, out.FileName, maxAttachmentSize(config.Limits))
 	if err != nil {
-		return nil, fmt.Errorf("could not read PDF file: %w", err)
+		return nil, fmt.Errorf("could not read attachment: %w", err)
+	}
+	logging.Step(c, logger, "extract", start, slog.Int("xml_size", len(out.Data)))
+
+	out.Warnings, err = consistencyWarnings(ctx, &out)
+	if err != nil {
+		return nil, fmt.Errorf("could not check consistency: %w", err)
+	}
+
+	if config.Strict && len(out.Warnings) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInconsistent, strings.Join(out.Warnings, "; "))
+	}
+
+	if err = c.Err(); err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	out.Signatures, err = signatures(ctx, reader, out.FileName)
+	if err != nil {
+		// The invoice is extracted already, so unreadable form fields do not fail the extraction.
+		out.Warnings = append(out.Warnings, fmt.Sprintf("could not read signatures: %v", err))
+	}
+	logging.Step(c, logger, "signatures", start, slog.Int("signatures", len(out.Signatures)))
+
+	return &out, nil
+}
+
+// readForExtraction reads the PDF with ReadLazy and, if that fails for another reason than an
+// exceeded limit or cancellation, with ReadEmbeddedFiles.
+func readForExtraction(c context.Context, reader io.ReadSeeker, config Config) (*model.Context, error) {
+	if !config.FullRead {
+		ctx, err := ReadLazy(c, reader, config)
+		if err == nil || errors.Is(err, limits.ErrExceeded) || c.Err() != nil {
+			return ctx, err
+		}
+
+		logging.OrDiscard(config.Logger).LogAttrs(c, slog.LevelDebug, "lazy read failed, reading the whole PDF",
+			slog.String("error", err.Error()))
+	}
+
+	return ReadEmbeddedFiles(c, reader, config)
+}
+
+// Read reads and validates the PDF the way all extraction functions need it: input limits are
+// checked, encrypted PDFs are opened with the configured passwords and the cross-reference table is
+// validated, which is needed for attachments to work.
+func Read(c context.Context, reader io.ReadSeeker, config Config) (*model.Context, error) {
+	ctx, err := read(c, reader, config)
+	if err != nil {
+		return nil, err
 	}
 
 	// Needs to be done for attachments to work!
+	start := time.Now()
 	if err = validate.XRefTable(ctx); err != nil {
 		return nil, err
 	}
+	logging.Step(c, logging.OrDiscard(config.Logger), "validate xref", start)
+
+	if err = c.Err(); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// ReadEmbeddedFiles is like Read but only loads the EmbeddedFiles name tree with LoadEmbeddedFiles
+// instead of validating the whole PDF. That is enough to read attachments and metadata, not to
+// write the PDF with pdfcpu.
+func ReadEmbeddedFiles(c context.Context, reader io.ReadSeeker, config Config) (*model.Context, error) {
+	ctx, err := read(c, reader, config)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if err = LoadEmbeddedFiles(ctx); err != nil {
+		return nil, fmt.Errorf("could not read embedded files: %w", err)
+	}
+	logging.Step(c, logging.OrDiscard(config.Logger), "load embedded files", start)
+
+	if err = c.Err(); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// read checks the input limits, reads the PDF, opening it with the configured passwords if it is
+// encrypted, and checks the object limit.
+func read(c context.Context, reader io.ReadSeeker, config Config) (*model.Context, error) {
+	logger := logging.OrDiscard(config.Logger)
+
+	start := time.Now()
+	if err := config.Limits.CheckInput(c, reader); err != nil {
+		return nil, err
+	}
+
+	ctx, err := pdfcpu.ReadWithContext(c, reader, crypt.Configuration(config.UserPassword, config.OwnerPassword))
+	if err != nil {
+		return nil, fmt.Errorf("could not read PDF file: %w", crypt.ReadError(err))
+	}
 
+	logging.Step(c, logger, "read", start,
+		slog.Int64("input_size", ctx.Read.FileSize),
+		slog.Int("objects", len(ctx.XRefTable.Table)),
+		slog.String("pdf_version", ctx.VersionString()),
+		slog.Bool("encrypted", crypt.Encrypted(ctx)),
+	)
+
+	if err = config.Limits.CheckObjects(ctx); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// catalogXMP parses the XMP metadata of the catalog. It returns nil if the catalog has none.
+func catalogXMP(ctx *model.Context) (*xmp.Document, error) {
 	metadata, err := pdfcpu.ExtractMetadata(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("could not extract metadata: %w", err)
 	}
 
-	var out Output
-
 	for _, meta := range metadata {
 		if meta.ParentType != "Catalog" {
 			continue
@@ -70,46 +256,20 @@ func FromReader(reader io.ReadSeeker) (*Output, error) {
 			return nil, fmt.Errorf("could not unmarshal XMP metadata: %w", err)
 		}
 
-		makeModel := fx.FindModel(&doc)
-		if makeModel == nil {
-			zfModel := zf.FindModel(&doc)
-			if zfModel == nil {
-				return nil, fmt.Errorf("could not find fx: %w", err)
-			}
-
-			out.FileName = zfModel.DocumentFileName
-			out.DocumentType = zfModel.DocumentType
-			out.ConformanceLevel = zfModel.ConformanceLevel
-			out.Version = zfModel.Version
-			out.FileType = Zugferd
-		} else {
-			out.FileName = makeModel.DocumentFileName
-			out.DocumentType = makeModel.DocumentType
-			out.ConformanceLevel = makeModel.ConformanceLevel
-			out.Version = makeModel.Version
-			out.FileType = FacturX
-		}
-
-		break
+		return &doc, nil
 	}
 
-	if out.FileName == "" {
-		return nil, fmt.Errorf("could not find file name")
-	}
-
-	embeddedFiles, err := ctx.ExtractAttachments([]string{out.FileName})
-	if err != nil {
-		return nil, fmt.Errorf("could not extract attachments: %w", err)
-	}
-
-	if len(embeddedFiles) == 0 {
-		return nil, fmt.Errorf("no %s files found", out.FileName)
-	}
+	return nil, nil
+}
 
-	out.Data, err = io.ReadAll(embeddedFiles[0].Reader)
-	if err != nil {
-		return nil, fmt.Errorf("could not read attachment: %w", err)
+// maxAttachmentSize returns the stricter of the embedded file and decompression limits, 0 if neither is set.
+func maxAttachmentSize(l limits.Limits) int64 {
+	switch {
+	case l.MaxEmbeddedFileSize <= 0:
+		return l.MaxDecompressedSize
+	case l.MaxDecompressedSize <= 0:
+		return l.MaxEmbeddedFileSize
+	default:
+		return min(l.MaxEmbeddedFileSize, l.MaxDecompressedSize)
 	}
-
-	return &out, nil
 }
@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+)
+
+// fuzzLimits keep single inputs from running the fuzzer out of memory.
+var fuzzLimits = limits.Limits{
+	MaxInputSize:        8 << 20,
+	MaxObjects:          10_000,
+	MaxEmbeddedFileSize: 1 << 20,
+	MaxDecompressedSize: 16 << 20,
+}
+
+// addCorpus seeds f with the PDFs of the testdata directory of the module.
+func addCorpus(f *testing.F) {
+	f.Helper()
+
+	samples, err := filepath.Glob("../../testdata/*/*.pdf")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, sample := range append(samples, "../../testdata/invoice.pdf") {
+		data, err := os.ReadFile(sample)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+}
+
+// FuzzFromReader checks that no PDF makes FromReader panic. Run it with
+// go test -fuzz=FuzzFromReader ./internal/extract
+func FuzzFromReader(f *testing.F) {
+	addCorpus(f)
+	f.Add([]byte("%PDF-1.7\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		out, err := FromReader(context.Background(), bytes.NewReader(data), Config{Limits: fuzzLimits})
+		if err == nil && out == nil {
+			t.Fatal("no output and no error")
+		}
+	})
+}
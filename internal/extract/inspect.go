@@ -0,0 +1,548 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/crypt"
+	"github.com/MarlinKuhn/gopdfattach/internal/xsd/fx"
+	"github.com/MarlinKuhn/gopdfattach/internal/xsd/pdfaExtension"
+	"github.com/MarlinKuhn/gopdfattach/internal/xsd/pdfaid"
+	"github.com/MarlinKuhn/gopdfattach/internal/xsd/zf"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Report describes everything that makes a PDF a hybrid invoice. It is filled from what the PDF
+// contains, so missing parts are empty rather than defaulted; Problems lists what is wrong.
+type Report struct {
+	PDFVersion      string         `json:"pdf_version"`    // effective version, the catalog /Version overrides the header
+	HeaderVersion   string         `json:"header_version"` // version of the %PDF- header
+	Encrypted       bool           `json:"encrypted"`      // PDF/A-3 forbids encryption
+	Pages           int            `json:"pages"`
+	HasXMP          bool           `json:"has_xmp"`           // the catalog has XMP metadata
+	PDFA            *PDFAID        `json:"pdfa,omitempty"`    // pdfaid values of the XMP metadata, nil if absent
+	Invoice         *XMPInvoice    `json:"invoice,omitempty"` // fx or zf values of the XMP metadata, nil if absent
+	Extensions      []XMPExtension `json:"extension_schemas"` // PDF/A extension schemas declared in the XMP metadata
+	OutputIntents   []OutputIntent `json:"output_intents"`    // catalog /OutputIntents
+	AssociatedFiles []string       `json:"associated_files"`  // file names of the file specifications in the catalog /AF
+	EmbeddedFiles   []EmbeddedFile `json:"embedded_files"`    // entries of the EmbeddedFiles name tree
+	XMPFileFound    bool           `json:"xmp_file_found"`    // the XMP DocumentFileName names an embedded file
+	Problems        []Problem      `json:"problems"`          // deviations from Factur-X / ZUGFeRD and PDF/A-3
+
+	// XMPError is why the XMP metadata of the catalog could not be read; HasXMP is false then.
+	XMPError string `json:"xmp_error,omitempty"`
+}
+
+// PDFAID holds the pdfaid XMP properties.
+type PDFAID struct {
+	Part        string `json:"part"`
+	Conformance string `json:"conformance"`
+}
+
+// XMPInvoice holds the Factur-X (fx) or ZUGFeRD (zf) XMP properties.
+type XMPInvoice struct {
+	Namespace        string `json:"namespace"` // "fx" or "zf"
+	NamespaceURI     string `json:"namespace_uri"`
+	DocumentType     string `json:"document_type"`
+	DocumentFileName string `json:"document_file_name"`
+	Version          string `json:"version"`
+	ConformanceLevel string `json:"conformance_level"`
+}
+
+// XMPExtension is a PDF/A extension schema declared in pdfaExtension:schemas.
+type XMPExtension struct {
+	Schema       string   `json:"schema"`
+	NamespaceURI string   `json:"namespace_uri"`
+	Prefix       string   `json:"prefix"`
+	Properties   []string `json:"properties"`
+}
+
+// OutputIntent is an entry of the catalog /OutputIntents.
+type OutputIntent struct {
+	Subtype                   string `json:"subtype"` // e.g. "GTS_PDFA1"
+	OutputConditionIdentifier string `json:"output_condition_identifier"`
+	Info                      string `json:"info,omitempty"`
+	HasProfile                bool   `json:"has_profile"` // a DestOutputProfile is present
+}
+
+// EmbeddedFile describes a file specification of the EmbeddedFiles name tree and its stream.
+type EmbeddedFile struct {
+	Name           string     `json:"name"`         // key in the name tree
+	FileName       string     `json:"file_name"`    // /F of the file specification
+	UnicodeName    string     `json:"unicode_name"` // /UF of the file specification
+	Description    string     `json:"description,omitempty"`
+	AFRelationship string     `json:"af_relationship"` // empty if missing
+	Subtype        string     `json:"subtype"`         // MIME type of the stream, e.g. "text/xml"
+	Size           int        `json:"size"`            // /Params /Size, -1 if missing
+	CheckSum       string     `json:"check_sum,omitempty"`
+	CreationDate   *time.Time `json:"creation_date,omitempty"`
+	ModDate        *time.Time `json:"mod_date,omitempty"`
+	Associated     bool       `json:"associated"` // referenced by the catalog /AF
+}
+
+// Problem is a deviation found by Inspect.
+type Problem struct {
+	Code    ProblemCode `json:"code"`
+	Message string      `json:"message"`
+}
+
+// ProblemCode identifies the kind of a Problem.
+type ProblemCode string
+
+const (
+	ProblemEncrypted         ProblemCode = "encrypted"
+	ProblemNoXMP             ProblemCode = "no-xmp"
+	ProblemNoPDFAID          ProblemCode = "no-pdfaid"
+	ProblemNotPDFA3          ProblemCode = "not-pdfa-3"
+	ProblemNoInvoiceXMP      ProblemCode = "no-invoice-xmp"
+	ProblemNoExtensionSchema ProblemCode = "no-extension-schema"
+	ProblemNoOutputIntent    ProblemCode = "no-output-intent"
+	ProblemFileNotFound      ProblemCode = "file-not-found"
+	ProblemNotAssociated     ProblemCode = "not-associated"
+	ProblemNoAFRelationship  ProblemCode = "no-af-relationship"
+	ProblemWrongSubtype      ProblemCode = "wrong-subtype"
+	ProblemVersionBelow17    ProblemCode = "version-below-1.7"
+)
+
+func (r *Report) problem(code ProblemCode, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// HasProblem reports whether a problem with code was found.
+func (r *Report) HasProblem(code ProblemCode) bool {
+	return slices.ContainsFunc(r.Problems, func(p Problem) bool { return p.Code == code })
+}
+
+// InvoiceFile returns the embedded file named by the XMP DocumentFileName, or nil.
+func (r *Report) InvoiceFile() *EmbeddedFile {
+	if r.Invoice == nil {
+		return nil
+	}
+
+	for i := range r.EmbeddedFiles {
+		if r.EmbeddedFiles[i].Name == r.Invoice.DocumentFileName || r.EmbeddedFiles[i].FileName == r.Invoice.DocumentFileName {
+			return &r.EmbeddedFiles[i]
+		}
+	}
+	return nil
+}
+
+// Inspect reads a PDF with Read and reports its hybrid invoice structure. Unlike FromReader it does
+// not fail on missing parts but records them as problems.
+func Inspect(c context.Context, reader io.ReadSeeker, config Config) (*Report, error) {
+	ctx, err := Read(c, reader, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return InspectContext(ctx)
+}
+
+// InspectContext reports the hybrid invoice structure of a PDF that has been read with Read.
+func InspectContext(ctx *model.Context) (*Report, error) {
+	report := &Report{
+		PDFVersion: ctx.VersionString(),
+		Encrypted:  crypt.Encrypted(ctx),
+		Pages:      ctx.PageCount,
+	}
+
+	if ctx.HeaderVersion != nil {
+		report.HeaderVersion = ctx.HeaderVersion.String()
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, fmt.Errorf("could not get catalog: %w", err)
+	}
+
+	if err = inspectXMP(ctx, report); err != nil {
+		return nil, err
+	}
+
+	if report.OutputIntents, err = outputIntents(ctx, catalog); err != nil {
+		return nil, err
+	}
+
+	associated, err := associatedFiles(ctx, catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.EmbeddedFiles, err = embeddedFiles(ctx, associated); err != nil {
+		return nil, err
+	}
+
+	for _, spec := range associated {
+		report.AssociatedFiles = append(report.AssociatedFiles, spec.name)
+	}
+
+	report.check()
+
+	return report, nil
+}
+
+func inspectXMP(ctx *model.Context, report *Report) error {
+	doc, err := catalogXMP(ctx)
+	if err != nil {
+		// Unreadable metadata is reported like missing metadata, which Repair replaces.
+		report.XMPError = err.Error()
+		return nil
+	}
+	if doc == nil {
+		return nil
+	}
+
+	report.HasXMP = true
+
+	if id := pdfaid.FindModel(doc); id != nil {
+		report.PDFA = &PDFAID{Part: id.Part, Conformance: id.Conformance}
+	}
+
+	if m := fx.FindModel(doc); m != nil {
+		report.Invoice = &XMPInvoice{
+			Namespace:        fx.NsFacturX.GetName(),
+			NamespaceURI:     fx.NsFacturX.GetURI(),
+			DocumentType:     m.DocumentType,
+			DocumentFileName: m.DocumentFileName,
+			Version:          m.Version,
+			ConformanceLevel: m.ConformanceLevel,
+		}
+	} else if m := zf.FindModel(doc); m != nil {
+		report.Invoice = &XMPInvoice{
+			Namespace:        zf.NsZugferd.GetName(),
+			NamespaceURI:     zf.NsZugferd.GetURI(),
+			DocumentType:     m.DocumentType,
+			DocumentFileName: m.DocumentFileName,
+			Version:          m.Version,
+			ConformanceLevel: m.ConformanceLevel,
+		}
+	}
+
+	if extension := pdfaExtension.FindModel(doc); extension != nil {
+		for _, schema := range extension.Schemas {
+			e := XMPExtension{Schema: schema.Schema, NamespaceURI: schema.NamespaceURI, Prefix: schema.Prefix}
+			for _, property := range schema.Property {
+				e.Properties = append(e.Properties, property.Name)
+			}
+			report.Extensions = append(report.Extensions, e)
+		}
+	}
+
+	return nil
+}
+
+func outputIntents(ctx *model.Context, catalog types.Dict) ([]OutputIntent, error) {
+	array, err := ctx.DereferenceArray(catalog["OutputIntents"])
+	if err != nil {
+		return nil, fmt.Errorf("could not get output intents: %w", err)
+	}
+
+	var intents []OutputIntent
+	for _, obj := range array {
+		d, err := ctx.DereferenceDict(obj)
+		if err != nil || d == nil {
+			continue
+		}
+
+		intent := OutputIntent{
+			OutputConditionIdentifier: text(ctx, d["OutputConditionIdentifier"]),
+			Info:                      text(ctx, d["Info"]),
+			HasProfile:                d["DestOutputProfile"] != nil,
+		}
+		if s := d.NameEntry("S"); s != nil {
+			intent.Subtype = *s
+		}
+		intents = append(intents, intent)
+	}
+
+	return intents, nil
+}
+
+// fileSpecRef is a file specification referenced by the catalog /AF.
+type fileSpecRef struct {
+	name  string
+	objNr int // object number of the file specification, 0 if direct
+}
+
+func associatedFiles(ctx *model.Context, catalog types.Dict) ([]fileSpecRef, error) {
+	array, err := ctx.DereferenceArray(catalog["AF"])
+	if err != nil {
+		return nil, fmt.Errorf("could not get associated files: %w", err)
+	}
+
+	var specs []fileSpecRef
+	for _, obj := range array {
+		d, err := ctx.DereferenceDict(obj)
+		if err != nil || d == nil {
+			continue
+		}
+
+		spec := fileSpecRef{name: text(ctx, d["UF"])}
+		if spec.name == "" {
+			spec.name = text(ctx, d["F"])
+		}
+		if ref, ok := obj.(types.IndirectRef); ok {
+			spec.objNr = ref.ObjectNumber.Value()
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+func embeddedFiles(ctx *model.Context, associated []fileSpecRef) ([]EmbeddedFile, error) {
+	if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+		return nil, fmt.Errorf("could not read embedded files: %w", err)
+	}
+
+	tree := ctx.Names["EmbeddedFiles"]
+	if tree == nil {
+		return nil, nil
+	}
+
+	var files []EmbeddedFile
+	err := tree.Process(ctx.XRefTable, func(_ *model.XRefTable, k string, v *types.Object) error {
+		d, err := ctx.DereferenceDict(*v)
+		if err != nil || d == nil {
+			return nil
+		}
+
+		file := EmbeddedFile{
+			Name:        k,
+			FileName:    text(ctx, d["F"]),
+			UnicodeName: text(ctx, d["UF"]),
+			Description: text(ctx, d["Desc"]),
+			Size:        -1,
+		}
+		if af := d.NameEntry("AFRelationship"); af != nil {
+			file.AFRelationship = *af
+		}
+
+		ref, isRef := (*v).(types.IndirectRef)
+		for _, spec := range associated {
+			if isRef && spec.objNr == ref.ObjectNumber.Value() || !isRef && spec.name == fileSpecName(file) {
+				file.Associated = true
+			}
+		}
+
+		if sd, err := EmbeddedFileStream(ctx, *v); err == nil {
+			streamProperties(ctx, sd, &file)
+		}
+
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read embedded files: %w", err)
+	}
+
+	return files, nil
+}
+
+func fileSpecName(file EmbeddedFile) string {
+	if file.UnicodeName != "" {
+		return file.UnicodeName
+	}
+	return file.FileName
+}
+
+func streamProperties(ctx *model.Context, sd *types.StreamDict, file *EmbeddedFile) {
+	if subtype := sd.NameEntry("Subtype"); subtype != nil {
+		file.Subtype = *subtype
+	}
+
+	params, err := ctx.DereferenceDict(sd.Dict["Params"])
+	if err != nil || params == nil {
+		return
+	}
+
+	if size, err := ctx.DereferenceInteger(params["Size"]); err == nil && size != nil {
+		file.Size = size.Value()
+	}
+
+	// The spec stores the MD5 as 16 bytes, some producers store it hex encoded.
+	if sum := text(ctx, params["CheckSum"]); len(sum) == 16 {
+		file.CheckSum = hex.EncodeToString([]byte(sum))
+	} else {
+		file.CheckSum = strings.ToLower(sum)
+	}
+
+	file.CreationDate = date(ctx, params["CreationDate"])
+	file.ModDate = date(ctx, params["ModDate"])
+}
+
+// text returns a string or hex literal, or "" if obj is missing or not a string.
+func text(ctx *model.Context, obj types.Object) string {
+	if obj == nil {
+		return ""
+	}
+
+	s, err := ctx.DereferenceText(obj)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+func date(ctx *model.Context, obj types.Object) *time.Time {
+	t, ok := types.DateTime(text(ctx, obj), true)
+	if !ok {
+		return nil
+	}
+	return &t
+}
+
+// check records the problems of the report.
+func (r *Report) check() {
+	if r.Encrypted {
+		r.problem(ProblemEncrypted, "PDF is encrypted, PDF/A-3 forbids encryption")
+	}
+
+	if v, err := model.PDFVersion(r.PDFVersion); err == nil && v < model.V17 {
+		r.problem(ProblemVersionBelow17, "PDF version is %s, PDF/A-3 is based on 1.7", r.PDFVersion)
+	}
+
+	switch {
+	case r.XMPError != "":
+		r.problem(ProblemNoXMP, "catalog XMP metadata cannot be read: %s", r.XMPError)
+	case !r.HasXMP:
+		r.problem(ProblemNoXMP, "catalog has no XMP metadata")
+	}
+
+	switch {
+	case r.PDFA == nil:
+		if r.HasXMP {
+			r.problem(ProblemNoPDFAID, "XMP metadata has no pdfaid identification")
+		}
+	case r.PDFA.Part != "3":
+		r.problem(ProblemNotPDFA3, "pdfaid:part is %q, hybrid invoices must be PDF/A-3", r.PDFA.Part)
+	}
+
+	if !slices.ContainsFunc(r.OutputIntents, func(o OutputIntent) bool { return o.Subtype == "GTS_PDFA1" }) {
+		r.problem(ProblemNoOutputIntent, "catalog has no GTS_PDFA1 output intent")
+	}
+
+	if r.Invoice == nil {
+		if r.HasXMP {
+			r.problem(ProblemNoInvoiceXMP, "XMP metadata has neither fx nor zf invoice properties")
+		}
+		return
+	}
+
+	if !slices.ContainsFunc(r.Extensions, func(e XMPExtension) bool { return e.NamespaceURI == r.Invoice.NamespaceURI }) {
+		r.problem(ProblemNoExtensionSchema, "no PDF/A extension schema declares the %s namespace", r.Invoice.Namespace)
+	}
+
+	file := r.InvoiceFile()
+	if file == nil {
+		r.problem(ProblemFileNotFound, "XMP DocumentFileName %q does not name an embedded file", r.Invoice.DocumentFileName)
+		return
+	}
+
+	r.XMPFileFound = true
+
+	if !file.Associated {
+		r.problem(ProblemNotAssociated, "%s is not referenced by the catalog /AF", file.Name)
+	}
+
+	if file.AFRelationship == "" {
+		r.problem(ProblemNoAFRelationship, "%s has no AFRelationship", file.Name)
+	}
+
+	if file.Subtype != "text/xml" {
+		r.problem(ProblemWrongSubtype, "%s has subtype %q instead of text/xml", file.Name, file.Subtype)
+	}
+}
+
+// WriteText writes the report in a human-readable form.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	version := r.PDFVersion
+	if r.HeaderVersion != "" && r.HeaderVersion != r.PDFVersion {
+		version += " (header " + r.HeaderVersion + ")"
+	}
+	fmt.Fprintf(tw, "PDF version:\t%s\n", version)
+	fmt.Fprintf(tw, "Pages:\t%d\n", r.Pages)
+	fmt.Fprintf(tw, "Encrypted:\t%s\n", yesNo(r.Encrypted))
+
+	switch {
+	case !r.HasXMP:
+		fmt.Fprintf(tw, "XMP metadata:\tnone\n")
+	case r.PDFA == nil:
+		fmt.Fprintf(tw, "PDF/A:\tnone\n")
+	default:
+		fmt.Fprintf(tw, "PDF/A:\tpart %s, conformance %s\n", r.PDFA.Part, r.PDFA.Conformance)
+	}
+
+	if r.Invoice != nil {
+		fmt.Fprintf(tw, "Invoice XMP:\t%s (%s)\n", r.Invoice.Namespace, r.Invoice.NamespaceURI)
+		fmt.Fprintf(tw, "  DocumentType:\t%s\n", r.Invoice.DocumentType)
+		fmt.Fprintf(tw, "  DocumentFileName:\t%s (embedded %s)\n", r.Invoice.DocumentFileName, yesNo(r.XMPFileFound))
+		fmt.Fprintf(tw, "  Version:\t%s\n", r.Invoice.Version)
+		fmt.Fprintf(tw, "  ConformanceLevel:\t%s\n", r.Invoice.ConformanceLevel)
+	} else if r.HasXMP {
+		fmt.Fprintf(tw, "Invoice XMP:\tnone\n")
+	}
+
+	fmt.Fprintf(tw, "Extension schemas:\t%d\n", len(r.Extensions))
+	for _, e := range r.Extensions {
+		fmt.Fprintf(tw, "  %s\t%s (%s)\n", e.Prefix, e.NamespaceURI, strings.Join(e.Properties, ", "))
+	}
+
+	fmt.Fprintf(tw, "Output intents:\t%d\n", len(r.OutputIntents))
+	for _, o := range r.OutputIntents {
+		profile := "no profile"
+		if o.HasProfile {
+			profile = "profile"
+		}
+		fmt.Fprintf(tw, "  %s\t%s (%s)\n", o.Subtype, o.OutputConditionIdentifier, profile)
+	}
+
+	fmt.Fprintf(tw, "Associated files:\t%s\n", strings.Join(r.AssociatedFiles, ", "))
+
+	fmt.Fprintf(tw, "Embedded files:\t%d\n", len(r.EmbeddedFiles))
+	for _, f := range r.EmbeddedFiles {
+		size := "unknown size"
+		if f.Size >= 0 {
+			size = fmt.Sprintf("%d bytes", f.Size)
+		}
+		fmt.Fprintf(tw, "  %s\t%s, AFRelationship %s, %s, associated %s\n", f.Name, orNone(f.Subtype), orNone(f.AFRelationship), size, yesNo(f.Associated))
+	}
+
+	if len(r.Problems) == 0 {
+		fmt.Fprintf(tw, "Problems:\tnone\n")
+	} else {
+		fmt.Fprintf(tw, "Problems:\t%d\n", len(r.Problems))
+		for _, p := range r.Problems {
+			fmt.Fprintf(tw, "  %s\t%s\n", p.Code, p.Message)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,766 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/crypt"
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/MarlinKuhn/gopdfattach/internal/logging"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxLazyObjects bounds the number of objects ReadLazy loads. A catalog that reaches more objects
+// through the entries extraction needs is read the usual way.
+const maxLazyObjects = 4096
+
+// lazySkipKeys are not followed when loading the objects reachable from the catalog, because they
+// lead to pages, appearances and resources extraction never looks at.
+var lazySkipKeys = map[string]bool{
+	"Annots": true,
+	"AP":     true,
+	"DR":     true,
+	"MK":     true,
+	"P":      true,
+	"Pages":  true,
+	"Parent": true,
+}
+
+var errEncrypted = errors.New("PDF is encrypted")
+
+// ReadLazy reads the cross-reference sections of the PDF and only the objects extraction needs: the
+// catalog and everything reachable from its /Metadata, /AF, /AcroForm and EmbeddedFiles name tree.
+// pdfcpu reads every object of the PDF instead, which for a scan of hundreds of pages costs far more
+// than the few objects of a hybrid invoice.
+//
+// Encrypted PDFs and cross-reference sections pdfcpu would have to repair are not supported; unless
+// a limit was exceeded, such PDFs have to be read with ReadEmbeddedFiles instead.
+func ReadLazy(c context.Context, reader io.ReadSeeker, config Config) (*model.Context, error) {
+	logger := logging.OrDiscard(config.Logger)
+
+	start := time.Now()
+	if err := config.Limits.CheckInput(c, reader); err != nil {
+		return nil, err
+	}
+
+	ctx, err := model.NewContext(reader, crypt.Configuration(config.UserPassword, config.OwnerPassword))
+	if err != nil {
+		return nil, fmt.Errorf("could not read PDF file: %w", err)
+	}
+
+	l := &lazyReader{
+		c:             c,
+		ctx:           ctx,
+		maxDecoded:    config.Limits.MaxDecompressedSize,
+		visited:       map[int]bool{},
+		objectStreams: map[int]*objectStream{},
+	}
+
+	if err = l.readXRef(); err != nil {
+		return nil, fmt.Errorf("could not read cross-reference table: %w", err)
+	}
+
+	logging.Step(c, logger, "read", start,
+		slog.Int64("input_size", ctx.Read.FileSize),
+		slog.Int("objects", len(ctx.XRefTable.Table)),
+		slog.String("pdf_version", ctx.VersionString()),
+		slog.Bool("encrypted", false),
+		slog.Bool("lazy", true),
+	)
+
+	if err = config.Limits.CheckObjects(ctx); err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	if err = l.loadCatalog(); err != nil {
+		return nil, fmt.Errorf("could not load catalog: %w", err)
+	}
+
+	if err = LoadEmbeddedFiles(ctx); err != nil {
+		return nil, fmt.Errorf("could not read embedded files: %w", err)
+	}
+	logging.Step(c, logger, "load embedded files", start, slog.Int("loaded_objects", l.loaded))
+
+	if err = c.Err(); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// lazyReader fills the cross-reference table of ctx with the offsets of all objects and loads
+// objects on demand.
+type lazyReader struct {
+	c          context.Context
+	ctx        *model.Context
+	maxDecoded int64
+
+	loaded        int
+	visited       map[int]bool
+	objectStreams map[int]*objectStream
+}
+
+// objectStream is a decoded object stream with the offsets of its objects.
+type objectStream struct {
+	content []byte
+	objNrs  []int
+	offsets []int
+}
+
+// readXRef reads the header version and all cross-reference sections, newest first, so entries of
+// later revisions take precedence.
+func (l *lazyReader) readXRef() error {
+	header, err := l.readAt(0, 16)
+	if err != nil {
+		return err
+	}
+	if len(header) < 8 || !bytes.HasPrefix(header, []byte("%PDF-")) {
+		return fmt.Errorf("no PDF header at the start of the file")
+	}
+
+	version, err := model.PDFVersion(string(header[5:8]))
+	if err != nil {
+		return err
+	}
+	l.ctx.HeaderVersion = &version
+
+	offset, err := l.startXRef()
+	if err != nil {
+		return err
+	}
+
+	sections := map[int64]bool{}
+	for offset != nil {
+		if sections[*offset] {
+			return fmt.Errorf("cross-reference section at offset %d is read twice", *offset)
+		}
+		sections[*offset] = true
+
+		if err = l.c.Err(); err != nil {
+			return err
+		}
+
+		if offset, err = l.readXRefSection(*offset); err != nil {
+			return err
+		}
+	}
+
+	if l.ctx.Root == nil {
+		return fmt.Errorf("trailer has no /Root")
+	}
+
+	if l.ctx.Size == nil {
+		size := len(l.ctx.Table)
+		l.ctx.Size = &size
+	}
+
+	return nil
+}
+
+// startXRef returns the offset following the last startxref keyword of the file.
+func (l *lazyReader) startXRef() (*int64, error) {
+	size := min(l.ctx.Read.FileSize, 1024)
+
+	tail, err := l.readAt(l.ctx.Read.FileSize-size, int(size))
+	if err != nil {
+		return nil, err
+	}
+
+	i := bytes.LastIndex(tail, []byte("startxref"))
+	if i < 0 {
+		return nil, fmt.Errorf("no startxref at the end of the file")
+	}
+
+	fields := strings.Fields(string(tail[i+len("startxref"):]))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no offset after startxref")
+	}
+
+	offset, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || offset < 0 || offset >= l.ctx.Read.FileSize {
+		return nil, fmt.Errorf("corrupt startxref offset %q", fields[0])
+	}
+
+	return &offset, nil
+}
+
+// readXRefSection reads the cross-reference table or stream at offset and returns the offset of the
+// previous section, if any.
+func (l *lazyReader) readXRefSection(offset int64) (*int64, error) {
+	head, err := l.readAt(offset, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(head, []byte("xref")) {
+		trailer, err := l.readXRefStream(offset)
+		if err != nil {
+			return nil, err
+		}
+		return l.trailer(trailer)
+	}
+
+	trailer, err := l.readXRefTable(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// A hybrid file keeps the objects of object streams in an additional cross-reference stream.
+	if stm, ok := trailer["XRefStm"].(types.Integer); ok {
+		if _, err = l.readXRefStream(int64(stm.Value())); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.trailer(trailer)
+}
+
+// readXRefTable reads the entries of the cross-reference table at offset and returns its trailer.
+func (l *lazyReader) readXRefTable(offset int64) (types.Dict, error) {
+	if _, err := l.ctx.Read.RS.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	w := &words{r: bufio.NewReader(l.ctx.Read.RS)}
+	if word, err := w.next(); err != nil || word != "xref" {
+		return nil, fmt.Errorf("no cross-reference table at offset %d", offset)
+	}
+
+	for {
+		word, err := w.next()
+		if err != nil {
+			return nil, fmt.Errorf("could not read cross-reference table: %w", err)
+		}
+		if word == "trailer" {
+			break
+		}
+
+		first, err := strconv.Atoi(word)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt cross-reference subsection %q", word)
+		}
+
+		count, err := w.int()
+		if err != nil {
+			return nil, err
+		}
+
+		for objNr := first; objNr < first+count; objNr++ {
+			entryOffset, err := w.int()
+			if err != nil {
+				return nil, err
+			}
+
+			generation, err := w.int()
+			if err != nil {
+				return nil, err
+			}
+
+			kind, err := w.next()
+			if err != nil {
+				return nil, err
+			}
+
+			switch kind {
+			case "n":
+				l.addEntry(objNr, model.XRefTableEntry{Offset: int64Ptr(int64(entryOffset)), Generation: &generation})
+			case "f":
+				l.addEntry(objNr, model.XRefTableEntry{Free: true, Offset: int64Ptr(int64(entryOffset)), Generation: &generation})
+			default:
+				return nil, fmt.Errorf("corrupt cross-reference entry of object %d", objNr)
+			}
+		}
+	}
+
+	// Trailer dictionaries are small, the parser ignores what follows the dictionary.
+	data, err := l.readAt(offset+w.n, 4096)
+	if err != nil {
+		return nil, err
+	}
+
+	s := string(data)
+	o, err := model.ParseObjectContext(l.c, &s)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse trailer: %w", err)
+	}
+
+	trailer, ok := o.(types.Dict)
+	if !ok {
+		return nil, fmt.Errorf("trailer is no dictionary")
+	}
+
+	return trailer, nil
+}
+
+// readXRefStream reads the entries of the cross-reference stream at offset and returns its
+// dictionary, which also serves as trailer.
+func (l *lazyReader) readXRefStream(offset int64) (types.Dict, error) {
+	objNr, generation, err := l.objectHeader(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := pdfcpu.ParseObjectWithContext(l.c, l.ctx, offset, objNr, generation)
+	if err != nil {
+		return nil, err
+	}
+
+	sd, ok := o.(types.StreamDict)
+	if !ok {
+		return nil, fmt.Errorf("no cross-reference stream at offset %d", offset)
+	}
+
+	if err = l.loadStreamContent(&sd); err != nil {
+		return nil, err
+	}
+
+	if sd.Content, err = limits.Decode(&sd, l.maxDecoded, "cross-reference stream"); err != nil {
+		return nil, err
+	}
+
+	xsd, err := model.ParseXRefStreamDict(&sd)
+	if err != nil {
+		return nil, err
+	}
+
+	size := xsd.W[0] + xsd.W[1] + xsd.W[2]
+	if size == 0 || len(xsd.Content) < size*len(xsd.Objects) {
+		return nil, fmt.Errorf("corrupt cross-reference stream at offset %d", offset)
+	}
+
+	field := func(b []byte) (v int64) {
+		for _, c := range b {
+			v = v<<8 | int64(c)
+		}
+		return v
+	}
+
+	for i, objNr := range xsd.Objects {
+		b := xsd.Content[i*size : (i+1)*size]
+
+		// A missing type field defaults to objects in use.
+		kind := int64(1)
+		if xsd.W[0] > 0 {
+			kind = field(b[:xsd.W[0]])
+		}
+		c2 := field(b[xsd.W[0] : xsd.W[0]+xsd.W[1]])
+		c3 := int(field(b[xsd.W[0]+xsd.W[1]:]))
+
+		switch kind {
+		case 0:
+			l.addEntry(objNr, model.XRefTableEntry{Free: true, Offset: &c2, Generation: &c3})
+		case 1:
+			l.addEntry(objNr, model.XRefTableEntry{Offset: &c2, Generation: &c3})
+		case 2:
+			objectStream := int(c2)
+			l.addEntry(objNr, model.XRefTableEntry{Compressed: true, ObjectStream: &objectStream, ObjectStreamInd: &c3})
+		}
+	}
+
+	return xsd.Dict, nil
+}
+
+// trailer takes the entries of a trailer not set by a later revision and returns the offset of the
+// previous cross-reference section, if any.
+func (l *lazyReader) trailer(d types.Dict) (*int64, error) {
+	if _, found := d.Find("Encrypt"); found {
+		return nil, errEncrypted
+	}
+
+	if ref, ok := d["Root"].(types.IndirectRef); ok && l.ctx.Root == nil {
+		l.ctx.Root = &ref
+	}
+	if ref, ok := d["Info"].(types.IndirectRef); ok && l.ctx.Info == nil {
+		l.ctx.Info = &ref
+	}
+	if id, ok := d["ID"].(types.Array); ok && l.ctx.ID == nil {
+		l.ctx.ID = id
+	}
+	if size, ok := d["Size"].(types.Integer); ok && l.ctx.Size == nil {
+		l.ctx.Size = new(int)
+		*l.ctx.Size = size.Value()
+	}
+
+	if prev, ok := d["Prev"].(types.Integer); ok {
+		return int64Ptr(int64(prev.Value())), nil
+	}
+
+	return nil, nil
+}
+
+// addEntry adds the entry of objNr unless a later revision already did.
+func (l *lazyReader) addEntry(objNr int, entry model.XRefTableEntry) {
+	if _, found := l.ctx.Table[objNr]; !found {
+		l.ctx.Table[objNr] = &entry
+	}
+}
+
+// loadCatalog loads the catalog and the objects reachable from the catalog entries extraction needs.
+func (l *lazyReader) loadCatalog() error {
+	root := l.ctx.Root.ObjectNumber.Value()
+	l.visited[root] = true
+
+	o, err := l.load(root)
+	if err != nil {
+		return err
+	}
+
+	catalog, ok := o.(types.Dict)
+	if !ok {
+		return fmt.Errorf("catalog is no dictionary")
+	}
+
+	for _, key := range []string{"Metadata", "AF", "AcroForm"} {
+		if err = l.loadAll(catalog[key]); err != nil {
+			return err
+		}
+	}
+
+	names, err := l.loadOne(catalog["Names"])
+	if err != nil {
+		return err
+	}
+
+	if d, ok := names.(types.Dict); ok {
+		return l.loadAll(d["EmbeddedFiles"])
+	}
+
+	return nil
+}
+
+// loadOne loads o if it is a reference, without the objects it refers to.
+func (l *lazyReader) loadOne(o types.Object) (types.Object, error) {
+	ref, ok := o.(types.IndirectRef)
+	if !ok {
+		return o, nil
+	}
+
+	l.visited[ref.ObjectNumber.Value()] = true
+	return l.load(ref.ObjectNumber.Value())
+}
+
+// loadAll loads o and every object it refers to, except through lazySkipKeys.
+func (l *lazyReader) loadAll(o types.Object) error {
+	switch o := o.(type) {
+	case types.IndirectRef:
+		objNr := o.ObjectNumber.Value()
+		if l.visited[objNr] {
+			return nil
+		}
+		l.visited[objNr] = true
+
+		obj, err := l.load(objNr)
+		if err != nil {
+			return err
+		}
+		return l.loadAll(obj)
+
+	case types.Dict:
+		for key, v := range o {
+			if lazySkipKeys[key] {
+				continue
+			}
+			if err := l.loadAll(v); err != nil {
+				return err
+			}
+		}
+
+	case types.StreamDict:
+		return l.loadAll(o.Dict)
+
+	case types.Array:
+		for _, v := range o {
+			if err := l.loadAll(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// load returns object objNr, reading it into its cross-reference table entry on first use. Like
+// pdfcpu it returns nil for free and unknown objects.
+func (l *lazyReader) load(objNr int) (types.Object, error) {
+	entry, found := l.ctx.Find(objNr)
+	if !found || entry.Free {
+		return nil, nil
+	}
+	if entry.Object != nil {
+		return entry.Object, nil
+	}
+
+	if err := l.c.Err(); err != nil {
+		return nil, err
+	}
+
+	l.loaded++
+	if l.loaded > maxLazyObjects {
+		return nil, fmt.Errorf("more than %d objects needed", maxLazyObjects)
+	}
+
+	if entry.Compressed {
+		o, err := l.compressedObject(objNr, *entry.ObjectStream, *entry.ObjectStreamInd)
+		if err != nil {
+			return nil, fmt.Errorf("could not load object %d: %w", objNr, err)
+		}
+
+		// Like pdfcpu, which resolves all compressed objects while reading.
+		generation := 0
+		entry.Object = o
+		entry.Generation = &generation
+		entry.Compressed = false
+		return o, nil
+	}
+
+	objectNr, generation, err := l.objectHeader(*entry.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if objectNr != objNr || generation != *entry.Generation {
+		return nil, fmt.Errorf("object %d %d found where the cross-reference table has object %d %d",
+			objectNr, generation, objNr, *entry.Generation)
+	}
+
+	o, err := pdfcpu.ParseObjectWithContext(l.c, l.ctx, *entry.Offset, objNr, generation)
+	if err != nil {
+		return nil, fmt.Errorf("could not load object %d: %w", objNr, err)
+	}
+
+	if sd, ok := o.(types.StreamDict); ok {
+		if err = l.loadStreamContent(&sd); err != nil {
+			return nil, fmt.Errorf("could not load stream %d: %w", objNr, err)
+		}
+		o = sd
+	}
+
+	entry.Object = o
+	return o, nil
+}
+
+// compressedObject parses the object at index i of the object stream streamNr.
+func (l *lazyReader) compressedObject(objNr, streamNr, i int) (types.Object, error) {
+	objStm, err := l.objectStream(streamNr)
+	if err != nil {
+		return nil, err
+	}
+
+	if i < 0 || i >= len(objStm.objNrs) || objStm.objNrs[i] != objNr {
+		return nil, fmt.Errorf("object stream %d has no object %d at index %d", streamNr, objNr, i)
+	}
+
+	end := len(objStm.content)
+	if i+1 < len(objStm.offsets) {
+		end = objStm.offsets[i+1]
+	}
+	if objStm.offsets[i] > end || end > len(objStm.content) {
+		return nil, fmt.Errorf("corrupt object stream %d", streamNr)
+	}
+
+	s := string(objStm.content[objStm.offsets[i]:end])
+	return model.ParseObjectContext(l.c, &s)
+}
+
+// objectStream returns the decoded object stream streamNr.
+func (l *lazyReader) objectStream(streamNr int) (*objectStream, error) {
+	if objStm, found := l.objectStreams[streamNr]; found {
+		return objStm, nil
+	}
+
+	entry, found := l.ctx.Find(streamNr)
+	if !found || entry.Free || entry.Compressed {
+		return nil, fmt.Errorf("no object stream %d", streamNr)
+	}
+
+	o, err := l.load(streamNr)
+	if err != nil {
+		return nil, err
+	}
+
+	sd, ok := o.(types.StreamDict)
+	if !ok {
+		return nil, fmt.Errorf("object %d is no object stream", streamNr)
+	}
+
+	osd, err := model.ObjectStreamDict(&sd)
+	if err != nil {
+		return nil, err
+	}
+
+	objStm := &objectStream{}
+	if objStm.content, err = limits.Decode(&sd, l.maxDecoded, fmt.Sprintf("object stream %d", streamNr)); err != nil {
+		return nil, err
+	}
+
+	if osd.FirstObjOffset < 0 || osd.FirstObjOffset > len(objStm.content) {
+		return nil, fmt.Errorf("corrupt object stream %d", streamNr)
+	}
+
+	// Some writers separate the numbers of the prolog with 0x00 instead of white space.
+	prolog := bytes.ReplaceAll(objStm.content[:osd.FirstObjOffset], []byte{0x00}, []byte{0x20})
+	fields := strings.Fields(string(prolog))
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("corrupt object stream %d", streamNr)
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		objNr, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("corrupt object stream %d", streamNr)
+		}
+
+		offset, err := strconv.Atoi(fields[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("corrupt object stream %d", streamNr)
+		}
+
+		objStm.objNrs = append(objStm.objNrs, objNr)
+		objStm.offsets = append(objStm.offsets, osd.FirstObjOffset+offset)
+	}
+
+	l.objectStreams[streamNr] = objStm
+	return objStm, nil
+}
+
+// loadStreamContent reads the encoded content of sd.
+func (l *lazyReader) loadStreamContent(sd *types.StreamDict) error {
+	length := sd.StreamLength
+	if length == nil && sd.StreamLengthObjNr != nil {
+		o, err := l.load(*sd.StreamLengthObjNr)
+		if err != nil {
+			return err
+		}
+		if i, ok := o.(types.Integer); ok {
+			length = int64Ptr(int64(i.Value()))
+		}
+	}
+
+	if length == nil || *length < 0 || sd.StreamOffset+*length > l.ctx.Read.FileSize {
+		return fmt.Errorf("missing or corrupt stream length")
+	}
+
+	raw, err := l.readAt(sd.StreamOffset, int(*length))
+	if err != nil {
+		return err
+	}
+	if int64(len(raw)) != *length {
+		return io.ErrUnexpectedEOF
+	}
+
+	sd.Raw = raw
+	sd.StreamLength = length
+	return nil
+}
+
+// objectHeader parses the object and generation number of the object at offset.
+func (l *lazyReader) objectHeader(offset int64) (objNr, generation int, err error) {
+	data, err := l.readAt(offset, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	i := bytes.Index(data, []byte("obj"))
+	if i < 0 {
+		return 0, 0, fmt.Errorf("no object at offset %d", offset)
+	}
+
+	fields := strings.Fields(string(data[:i]))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("no object at offset %d", offset)
+	}
+
+	if objNr, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, fmt.Errorf("no object at offset %d", offset)
+	}
+	if generation, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, fmt.Errorf("no object at offset %d", offset)
+	}
+
+	return objNr, generation, nil
+}
+
+// readAt reads up to n bytes at offset, fewer at the end of the file.
+func (l *lazyReader) readAt(offset int64, n int) ([]byte, error) {
+	if offset < 0 || offset > l.ctx.Read.FileSize {
+		return nil, fmt.Errorf("offset %d is outside of the file", offset)
+	}
+
+	if _, err := l.ctx.Read.RS.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("could not seek PDF file: %w", err)
+	}
+
+	buf := make([]byte, min(int64(n), l.ctx.Read.FileSize-offset))
+	if _, err := io.ReadFull(l.ctx.Read.RS, buf); err != nil {
+		return nil, fmt.Errorf("could not read PDF file: %w", err)
+	}
+
+	return buf, nil
+}
+
+// words splits a cross-reference table into white space separated words. A dictionary starting
+// right after a word ends it, so the trailer keyword can be followed directly by the trailer.
+type words struct {
+	r *bufio.Reader
+	n int64 // bytes consumed
+}
+
+func (w *words) next() (string, error) {
+	var word []byte
+	for {
+		c, err := w.r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(word) > 0 {
+				return string(word), nil
+			}
+			return "", err
+		}
+
+		if c == '<' && len(word) > 0 {
+			_ = w.r.UnreadByte()
+			return string(word), nil
+		}
+		w.n++
+
+		switch c {
+		case ' ', '\t', '\r', '\n', '\f', 0x00:
+			if len(word) > 0 {
+				return string(word), nil
+			}
+		default:
+			word = append(word, c)
+		}
+	}
+}
+
+func (w *words) int() (int, error) {
+	word, err := w.next()
+	if err != nil {
+		return 0, fmt.Errorf("could not read cross-reference table: %w", err)
+	}
+
+	i, err := strconv.Atoi(word)
+	if err != nil {
+		return 0, fmt.Errorf("corrupt cross-reference table entry %q", word)
+	}
+
+	return i, nil
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,315 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package extract
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/sign"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Signature describes a signature field found in a PDF and the result of verifying it.
+type Signature struct {
+	FieldName   string
+	SubFilter   string
+	Name        string
+	Reason      string
+	Location    string
+	SigningTime time.Time // from the signature dictionary's /M entry, zero if absent
+	Certificate *x509.Certificate
+	Timestamped bool
+
+	// ByteRange is the signed byte range as stored in the signature dictionary.
+	ByteRange [4]int64
+
+	// Valid is true if the ByteRange is well-formed and the CMS signature matches the signed bytes.
+	// The signer certificate is not validated against any trust anchor.
+	Valid bool
+
+	// Error describes why Valid is false.
+	Error string
+
+	// CoversWholeDocument is true if the signed revision ends at the end of the file,
+	// i.e. nothing was appended after signing.
+	CoversWholeDocument bool
+
+	// CoversAttachment is true if the current revision of the invoice's file specification and
+	// embedded file stream both lie inside the signed revision.
+	CoversAttachment bool
+}
+
+// signatures enumerates and verifies all signature fields of ctx, which was read from reader.
+// fileName is the name of the invoice attachment whose coverage by each signature is reported.
+// A signature that cannot be read is returned with its Error set; the error returned means the
+// form fields could not be walked, and out holds the signatures found until then.
+func signatures(ctx *model.Context, reader io.ReadSeeker, fileName string) ([]Signature, error) {
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, fmt.Errorf("could not get catalog: %w", err)
+	}
+
+	obj, found := catalog.Find("AcroForm")
+	if !found || obj == nil {
+		return nil, nil
+	}
+
+	form, err := ctx.DereferenceDict(obj)
+	if err != nil || form == nil {
+		return nil, err
+	}
+
+	fields, err := ctx.DereferenceArray(form["Fields"])
+	if err != nil {
+		return nil, fmt.Errorf("could not get form fields: %w", err)
+	}
+
+	// The file is read and the attachment located only once a signature is found.
+	var (
+		data       []byte
+		attachment []int
+		loadErr    error
+		loaded     bool
+	)
+	load := func() error {
+		if !loaded {
+			loaded = true
+			data, attachment, loadErr = signedData(ctx, reader, fileName)
+		}
+		return loadErr
+	}
+
+	var out []Signature
+	visited := map[int]bool{}
+	err = walkFields(ctx, fields, "", visited, func(name string, field types.Dict) error {
+		sigDict, err := ctx.DereferenceDict(field["V"])
+		if err != nil {
+			out = append(out, Signature{FieldName: name, Error: fmt.Sprintf("could not get signature dictionary: %v", err)})
+			return nil
+		}
+		if sigDict == nil {
+			return nil
+		}
+
+		if err := load(); err != nil {
+			out = append(out, Signature{FieldName: name, Error: err.Error()})
+			return nil
+		}
+
+		out = append(out, verifySignature(ctx, data, name, sigDict, attachment))
+		return nil
+	})
+
+	return out, err
+}
+
+// signedData reads the whole PDF file and the object numbers of the invoice attachment.
+func signedData(ctx *model.Context, reader io.ReadSeeker, fileName string) ([]byte, []int, error) {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, fmt.Errorf("could not seek PDF file: %w", err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read PDF file: %w", err)
+	}
+
+	attachment, err := attachmentObjects(ctx, fileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not locate attachment: %w", err)
+	}
+
+	return data, attachment, nil
+}
+
+// walkFields calls fn for every signature field in the field tree, passing the fully qualified name.
+func walkFields(ctx *model.Context, fields types.Array, parent string, visited map[int]bool, fn func(string, types.Dict) error) error {
+	for _, obj := range fields {
+		if ref, ok := obj.(types.IndirectRef); ok {
+			if visited[ref.ObjectNumber.Value()] {
+				continue
+			}
+			visited[ref.ObjectNumber.Value()] = true
+		}
+
+		field, err := ctx.DereferenceDict(obj)
+		if err != nil {
+			return fmt.Errorf("could not get form field: %w", err)
+		}
+		if field == nil {
+			continue
+		}
+
+		name := parent
+		if t := textEntry(field, "T"); t != "" {
+			if name != "" {
+				name += "."
+			}
+			name += t
+		}
+
+		if ft := field.NameEntry("FT"); ft != nil && *ft == "Sig" {
+			if err := fn(name, field); err != nil {
+				return err
+			}
+		}
+
+		if kids, found := field.Find("Kids"); found {
+			arr, err := ctx.DereferenceArray(kids)
+			if err != nil {
+				return fmt.Errorf("could not get form field kids: %w", err)
+			}
+			if err := walkFields(ctx, arr, name, visited, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func verifySignature(ctx *model.Context, data []byte, fieldName string, sigDict types.Dict, attachment []int) Signature {
+	sig := Signature{
+		FieldName: fieldName,
+		Name:      textEntry(sigDict, "Name"),
+		Reason:    textEntry(sigDict, "Reason"),
+		Location:  textEntry(sigDict, "Location"),
+	}
+
+	if subFilter := sigDict.NameEntry("SubFilter"); subFilter != nil {
+		sig.SubFilter = *subFilter
+	}
+
+	if m := textEntry(sigDict, "M"); m != "" {
+		if t, ok := types.DateTime(m, true); ok {
+			sig.SigningTime = t
+		}
+	}
+
+	byteRange, err := ctx.DereferenceArray(sigDict["ByteRange"])
+	if err != nil || len(byteRange) != 4 {
+		sig.Error = "missing or malformed /ByteRange"
+		return sig
+	}
+
+	for i, obj := range byteRange {
+		n, err := ctx.DereferenceInteger(obj)
+		if err != nil || n == nil {
+			sig.Error = "malformed /ByteRange"
+			return sig
+		}
+		sig.ByteRange[i] = int64(n.Value())
+	}
+
+	r := sig.ByteRange
+	size := int64(len(data))
+	if r[0] != 0 || r[1] <= 0 || r[2] <= r[1] || r[3] < 0 || r[2]+r[3] > size {
+		sig.Error = "/ByteRange does not describe the file"
+		return sig
+	}
+
+	// The gap must be exactly the /Contents hex string, so nothing else is left unsigned.
+	if data[r[1]] != '<' || data[r[2]-1] != '>' {
+		sig.Error = "/ByteRange gap is not the /Contents string"
+		return sig
+	}
+
+	sig.CoversWholeDocument = r[2]+r[3] == size
+	sig.CoversAttachment = len(attachment) > 0
+	for _, objNr := range attachment {
+		offset, ok := objectOffset(ctx, objNr)
+		if !ok || offset >= r[2]+r[3] {
+			sig.CoversAttachment = false
+		}
+	}
+
+	contents, err := contentsBytes(sigDict["Contents"])
+	if err != nil {
+		sig.Error = err.Error()
+		return sig
+	}
+
+	sd, err := sign.ParseSignedData(contents)
+	if err != nil {
+		sig.Error = err.Error()
+		return sig
+	}
+
+	sig.Certificate = sd.Signer
+	sig.Timestamped = sd.TimeStampToken() != nil
+
+	signed := make([]byte, 0, r[1]+r[3])
+	signed = append(signed, data[r[0]:r[0]+r[1]]...)
+	signed = append(signed, data[r[2]:r[2]+r[3]]...)
+
+	if sig.SubFilter == "adbe.pkcs7.sha1" {
+		// The SHA-1 digest of the signed bytes is the encapsulated content.
+		digest := sha1.Sum(signed)
+		if !bytes.Equal(sd.Content, digest[:]) {
+			sig.Error = "signed digest does not match the document"
+			return sig
+		}
+		signed = nil
+	}
+
+	if err := sd.Verify(signed); err != nil {
+		sig.Error = err.Error()
+		return sig
+	}
+
+	sig.Valid = true
+	return sig
+}
+
+// objectOffset returns the file offset of the current revision of an object. For objects stored
+// in an object stream the offset of the object stream is returned, also once the object was loaded
+// and the entry is no longer marked compressed.
+func objectOffset(ctx *model.Context, objNr int) (int64, bool) {
+	entry, found := ctx.XRefTable.FindTableEntryLight(objNr)
+	if !found || entry.Free {
+		return 0, false
+	}
+
+	if entry.ObjectStream != nil {
+		return objectOffset(ctx, *entry.ObjectStream)
+	}
+
+	if entry.Offset == nil {
+		return 0, false
+	}
+
+	return *entry.Offset, true
+}
+
+func contentsBytes(obj types.Object) ([]byte, error) {
+	switch o := obj.(type) {
+	case types.HexLiteral:
+		return o.Bytes()
+	case types.StringLiteral:
+		return types.Unescape(o.Value())
+	default:
+		return nil, fmt.Errorf("missing or malformed /Contents")
+	}
+}
+
+func textEntry(d types.Dict, key string) string {
+	obj, found := d.Find(key)
+	if !found {
+		return ""
+	}
+
+	s, err := types.StringOrHexLiteral(obj)
+	if err != nil || s == nil {
+		return ""
+	}
+
+	return *s
+}
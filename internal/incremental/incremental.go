@@ -0,0 +1,264 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package incremental
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Update collects new and replaced objects and appends them to the original PDF bytes as an
+// incremental update (ISO 32000-1, 7.5.6). The original bytes are never modified.
+type Update struct {
+	original []byte
+	ctx      *model.Context
+	objects  map[int]object
+	next     int
+}
+
+type object struct {
+	gen  int
+	body []byte
+}
+
+// Result is the written update.
+type Result struct {
+	// Data holds the original bytes followed by the update.
+	Data []byte
+
+	// Offsets maps the object numbers written by the update to their byte offset in Data.
+	Offsets map[int]int64
+}
+
+// New creates an Update for original, which must be the exact bytes ctx was read from.
+func New(original []byte, ctx *model.Context) (*Update, error) {
+	if ctx.XRefTable.Size == nil {
+		return nil, fmt.Errorf("missing trailer size")
+	}
+
+	if ctx.XRefTable.Root == nil {
+		return nil, fmt.Errorf("missing document catalog")
+	}
+
+	next := *ctx.XRefTable.Size
+	for objNr := range ctx.XRefTable.Table {
+		if objNr >= next {
+			next = objNr + 1
+		}
+	}
+
+	return &Update{
+		original: original,
+		ctx:      ctx,
+		objects:  map[int]object{},
+		next:     next,
+	}, nil
+}
+
+// Add appends obj as a new object and returns its reference.
+func (u *Update) Add(obj types.Object) types.IndirectRef {
+	return u.AddRaw(serialize(obj))
+}
+
+// AddRaw appends an already serialized object body as a new object and returns its reference.
+func (u *Update) AddRaw(body []byte) types.IndirectRef {
+	objNr := u.next
+	u.next++
+	u.objects[objNr] = object{body: body}
+	return *types.NewIndirectRef(objNr, 0)
+}
+
+// Replace writes obj as a new revision of the object ref points to.
+// ref may also name an object number that was free or unused in the original.
+func (u *Update) Replace(ref types.IndirectRef, obj types.Object) {
+	objNr := ref.ObjectNumber.Value()
+	u.objects[objNr] = object{
+		gen:  ref.GenerationNumber.Value(),
+		body: serialize(obj),
+	}
+
+	if objNr >= u.next {
+		u.next = objNr + 1
+	}
+}
+
+// Write appends all collected objects, a cross-reference section and a trailer to the original bytes.
+// A cross-reference stream is written if the original uses cross-reference streams, a table otherwise.
+func (u *Update) Write() (*Result, error) {
+	prev, err := lastStartXRef(u.original)
+	if err != nil {
+		return nil, err
+	}
+
+	// The buffer is sized for the objects up front, growing it would copy the original again.
+	size := len(u.original) + 4096
+	for _, obj := range u.objects {
+		size += len(obj.body) + 64
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(size)
+	buf.Write(u.original)
+	if len(u.original) > 0 && u.original[len(u.original)-1] != '\n' && u.original[len(u.original)-1] != '\r' {
+		buf.WriteByte('\n')
+	}
+
+	objNrs := make([]int, 0, len(u.objects))
+	for objNr := range u.objects {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	offsets := make(map[int]int64, len(objNrs)+1)
+	for _, objNr := range objNrs {
+		obj := u.objects[objNr]
+		offsets[objNr] = int64(buf.Len())
+		fmt.Fprintf(&buf, "%d %d obj\n", objNr, obj.gen)
+		buf.Write(obj.body)
+		buf.WriteString("\nendobj\n")
+	}
+
+	trailer := types.NewDict()
+	trailer.Insert("Root", *u.ctx.XRefTable.Root)
+	if u.ctx.XRefTable.Info != nil {
+		trailer.Insert("Info", *u.ctx.XRefTable.Info)
+	}
+	if u.ctx.XRefTable.ID != nil {
+		trailer.Insert("ID", u.ctx.XRefTable.ID)
+	}
+	trailer.Insert("Prev", types.Integer(prev))
+
+	startXRef := int64(buf.Len())
+	if u.ctx.Read != nil && u.ctx.Read.UsingXRefStreams && !u.ctx.Read.Hybrid {
+		xRefNr := u.next
+		u.next++
+		objNrs = append(objNrs, xRefNr)
+		offsets[xRefNr] = startXRef
+		u.objects[xRefNr] = object{}
+
+		u.writeXRefStream(&buf, trailer, objNrs, offsets)
+	} else {
+		u.writeXRefTable(&buf, trailer, objNrs, offsets)
+	}
+
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", startXRef)
+
+	return &Result{Data: buf.Bytes(), Offsets: offsets}, nil
+}
+
+func (u *Update) size() int {
+	size := *u.ctx.XRefTable.Size
+	if u.next > size {
+		size = u.next
+	}
+	return size
+}
+
+func (u *Update) writeXRefTable(buf *bytes.Buffer, trailer types.Dict, objNrs []int, offsets map[int]int64) {
+	buf.WriteString("xref\n")
+	for _, section := range subsections(objNrs) {
+		fmt.Fprintf(buf, "%d %d\n", section[0], len(section))
+		for _, objNr := range section {
+			fmt.Fprintf(buf, "%010d %05d n\r\n", offsets[objNr], u.objects[objNr].gen)
+		}
+	}
+
+	trailer.Insert("Size", types.Integer(u.size()))
+	buf.WriteString("trailer\n")
+	buf.WriteString(trailer.PDFString())
+	buf.WriteString("\n")
+}
+
+func (u *Update) writeXRefStream(buf *bytes.Buffer, trailer types.Dict, objNrs []int, offsets map[int]int64) {
+	var (
+		index   types.Array
+		entries bytes.Buffer
+	)
+
+	for _, section := range subsections(objNrs) {
+		index = append(index, types.Integer(section[0]), types.Integer(len(section)))
+		for _, objNr := range section {
+			entries.WriteByte(1)
+			_ = binary.Write(&entries, binary.BigEndian, uint32(offsets[objNr]))
+			_ = binary.Write(&entries, binary.BigEndian, uint16(u.objects[objNr].gen))
+		}
+	}
+
+	trailer.Insert("Type", types.Name("XRef"))
+	trailer.Insert("Size", types.Integer(u.size()))
+	trailer.Insert("Index", index)
+	trailer.Insert("W", types.Array{types.Integer(1), types.Integer(4), types.Integer(2)})
+	trailer.Insert("Length", types.Integer(entries.Len()))
+
+	xRefNr := objNrs[len(objNrs)-1]
+	fmt.Fprintf(buf, "%d 0 obj\n", xRefNr)
+	buf.WriteString(trailer.PDFString())
+	buf.WriteString("\nstream\n")
+	buf.Write(entries.Bytes())
+	buf.WriteString("\nendstream\nendobj\n")
+}
+
+// subsections splits sorted object numbers into runs of consecutive numbers.
+func subsections(objNrs []int) [][]int {
+	var sections [][]int
+	for i, objNr := range objNrs {
+		if i == 0 || objNr != objNrs[i-1]+1 {
+			sections = append(sections, nil)
+		}
+		sections[len(sections)-1] = append(sections[len(sections)-1], objNr)
+	}
+	return sections
+}
+
+func serialize(obj types.Object) []byte {
+	switch o := obj.(type) {
+	case types.StreamDict:
+		return serializeStream(o)
+	case *types.StreamDict:
+		return serializeStream(*o)
+	case types.Dict:
+		return []byte(o.PDFString())
+	default:
+		return []byte(obj.PDFString())
+	}
+}
+
+func serializeStream(sd types.StreamDict) []byte {
+	sd.Dict = sd.Dict.Clone().(types.Dict)
+	sd.Dict.Update("Length", types.Integer(len(sd.Raw)))
+
+	var buf bytes.Buffer
+	buf.WriteString(sd.Dict.PDFString())
+	buf.WriteString("\nstream\n")
+	buf.Write(sd.Raw)
+	buf.WriteString("\nendstream")
+	return buf.Bytes()
+}
+
+// lastStartXRef returns the offset recorded after the last startxref keyword of a PDF file.
+func lastStartXRef(data []byte) (int64, error) {
+	i := bytes.LastIndex(data, []byte("startxref"))
+	if i < 0 {
+		return 0, fmt.Errorf("could not find startxref")
+	}
+
+	fields := bytes.Fields(data[i+len("startxref"):])
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("missing startxref offset")
+	}
+
+	offset, err := strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid startxref offset: %w", err)
+	}
+
+	return offset, nil
+}
@@ -0,0 +1,335 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package limits guards against PDFs that would make reading them exhaust memory or CPU.
+package limits
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hhrutter/lzw"
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// ErrExceeded is wrapped by all errors reporting an exceeded limit.
+var ErrExceeded = errors.New("limit exceeded")
+
+// Limits bounds the resources spent on a PDF. Zero values disable the respective limit.
+type Limits struct {
+	MaxInputSize        int64 // size of the PDF in bytes
+	MaxObjects          int   // number of objects in the cross-reference table
+	MaxEmbeddedFileSize int64 // decoded size of the invoice XML in bytes
+	MaxDecompressedSize int64 // decoded size of any single stream in bytes
+}
+
+// CheckInput checks the size of rs and, before pdfcpu decodes any of them, the decompressed size of
+// every Flate stream. The streams are inflated into a bounded sink, so memory use is constant.
+// rs is positioned at the start afterwards.
+func (l Limits) CheckInput(ctx context.Context, rs io.ReadSeeker) error {
+	size, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("could not seek PDF file: %w", err)
+	}
+
+	if l.MaxInputSize > 0 && size > l.MaxInputSize {
+		return fmt.Errorf("%w: PDF has %d bytes, at most %d are allowed", ErrExceeded, size, l.MaxInputSize)
+	}
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("could not seek PDF file: %w", err)
+	}
+
+	if l.MaxDecompressedSize > 0 {
+		if err := l.scanStreams(ctx, bufio.NewReaderSize(rs, 64<<10)); err != nil {
+			return err
+		}
+
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("could not seek PDF file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+var streamKeyword = []byte("stream")
+
+// scanStreams inflates the data following every "stream" keyword that starts a zlib stream.
+func (l Limits) scanStreams(ctx context.Context, r *bufio.Reader) error {
+	// match is the number of bytes of streamKeyword matched so far, before the byte preceding the
+	// match and last the previous byte. The start of the file counts as delimiter.
+	var (
+		match        int
+		before, last byte = ' ', ' '
+	)
+
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read PDF file: %w", err)
+		}
+
+		switch {
+		case b == streamKeyword[match]:
+			if match == 0 {
+				before = last
+			}
+			match++
+		case b == streamKeyword[0]:
+			before = last
+			match = 1
+		default:
+			match = 0
+		}
+		last = b
+
+		if match < len(streamKeyword) {
+			continue
+		}
+		match = 0
+
+		// "endstream" and names like /Xstream are not stream starts.
+		if isRegular(before) {
+			continue
+		}
+
+		// The keyword is followed by CRLF or LF.
+		next, _ := r.Peek(2)
+		switch {
+		case len(next) == 2 && next[0] == '\r' && next[1] == '\n':
+			_, _ = r.Discard(2)
+		case len(next) >= 1 && next[0] == '\n':
+			_, _ = r.Discard(1)
+		default:
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := l.inflate(r); err != nil {
+			return err
+		}
+	}
+}
+
+// inflate decompresses a zlib stream at the position of r into a sink bounded by MaxDecompressedSize.
+// Data that is not a valid zlib stream is ignored; it is either not Flate encoded or broken, which
+// pdfcpu reports later.
+func (l Limits) inflate(r *bufio.Reader) error {
+	header, err := r.Peek(2)
+	if err != nil || !isZlibHeader(header) {
+		return nil
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+
+	n, _ := io.Copy(io.Discard, io.LimitReader(zr, l.MaxDecompressedSize+1))
+	if n > l.MaxDecompressedSize {
+		return fmt.Errorf("%w: stream decompresses to more than %d bytes", ErrExceeded, l.MaxDecompressedSize)
+	}
+
+	return nil
+}
+
+func isZlibHeader(b []byte) bool {
+	// CM must be 8 (deflate) and the header a multiple of 31, see RFC 1950.
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+func isRegular(b byte) bool {
+	switch b {
+	case 0, '\t', '\n', '\f', '\r', ' ', '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	default:
+		return true
+	}
+}
+
+// CheckObjects checks the number of objects of a context that has just been read.
+func (l Limits) CheckObjects(ctx *model.Context) error {
+	if l.MaxObjects > 0 && len(ctx.XRefTable.Table) > l.MaxObjects {
+		return fmt.Errorf("%w: PDF has %d objects, at most %d are allowed", ErrExceeded, len(ctx.XRefTable.Table), l.MaxObjects)
+	}
+	return nil
+}
+
+// ReadAll reads r up to max bytes. A max of 0 disables the limit.
+func ReadAll(r io.Reader, max int64, what string) ([]byte, error) {
+	if max <= 0 {
+		return io.ReadAll(r)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrExceeded, what, max)
+	}
+
+	return data, nil
+}
+
+// Copy copies r to w up to max bytes and returns the number of bytes copied. A max of 0 disables
+// the limit.
+func Copy(w io.Writer, r io.Reader, max int64, what string) (int64, error) {
+	if max <= 0 {
+		return io.Copy(w, r)
+	}
+
+	n, err := io.Copy(w, io.LimitReader(r, max+1))
+	if err != nil {
+		return n, err
+	}
+
+	if n > max {
+		return n, fmt.Errorf("%w: %s exceeds %d bytes", ErrExceeded, what, max)
+	}
+
+	return n, nil
+}
+
+// Decode decodes a stream, failing as soon as the decoded data exceeds max bytes. A max of 0
+// disables the limit. The filters of the pipeline are applied one by one and the output of every
+// filter is bounded by max, so chained filters cannot expand the data beyond it either. Filters
+// whose output cannot be bounded, like the image filters, are rejected when a limit is set.
+func Decode(sd *types.StreamDict, max int64, what string) ([]byte, error) {
+	if max <= 0 {
+		if err := sd.Decode(); err != nil {
+			return nil, err
+		}
+		return sd.Content, nil
+	}
+
+	data := sd.Raw
+	for _, f := range sd.FilterPipeline {
+		var err error
+		if data, err = decodeFilter(f, data, max); err != nil {
+			if errors.Is(err, ErrExceeded) {
+				return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrExceeded, what, max)
+			}
+			return nil, err
+		}
+	}
+
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrExceeded, what, max)
+	}
+
+	return data, nil
+}
+
+// decodeFilter applies a single filter to data. Its output is at most max bytes or the error wraps
+// ErrExceeded.
+func decodeFilter(f types.PDFFilter, data []byte, max int64) ([]byte, error) {
+	parms := filterParms(f.DecodeParms)
+
+	switch f.Name {
+	case filter.Flate, filter.LZW:
+		var r io.ReadCloser
+		if f.Name == filter.Flate {
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			r = zr
+		} else {
+			earlyChange, ok := parms["EarlyChange"]
+			r = lzw.NewReader(bytes.NewReader(data), !ok || earlyChange == 1)
+		}
+		defer r.Close()
+
+		// Like pdfcpu, tolerate streams that lack the zlib checksum.
+		decoded, err := io.ReadAll(io.LimitReader(r, max+1))
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+		if int64(len(decoded)) > max {
+			return nil, ErrExceeded
+		}
+
+		if parms["Predictor"] <= 1 {
+			return decoded, nil
+		}
+
+	case filter.ASCIIHex, filter.ASCII85:
+		// Both decode to less data than they read.
+
+	case filter.RunLength:
+		if int64(runLength(data)) > max {
+			return nil, ErrExceeded
+		}
+
+	default:
+		return nil, fmt.Errorf("%s filter cannot be decoded with a size limit", f.Name)
+	}
+
+	// The decoded size is known to be within max now; predictors only remove data.
+	fi, err := filter.NewFilter(f.Name, parms)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := fi.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+// filterParms converts decode parameters like pdfcpu does: integers as they are, booleans as 0 or 1.
+func filterParms(d types.Dict) map[string]int {
+	parms := map[string]int{}
+	for k, v := range d {
+		switch v := v.(type) {
+		case types.Integer:
+			parms[k] = v.Value()
+		case types.Boolean:
+			if v.Value() {
+				parms[k] = 1
+			} else {
+				parms[k] = 0
+			}
+		}
+	}
+	return parms
+}
+
+// runLength returns the decoded size of RunLengthDecode data without decoding it.
+func runLength(data []byte) int {
+	n := 0
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b == 0x80:
+			return n
+		case b < 0x80:
+			n += int(b) + 1
+			i += int(b) + 2
+		default:
+			n += 257 - int(b)
+			i += 2
+		}
+	}
+	return n
+}
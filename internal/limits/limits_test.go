@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package limits
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
+
+func stream(raw []byte, filters ...types.PDFFilter) *types.StreamDict {
+	return &types.StreamDict{Dict: types.Dict{}, Raw: raw, FilterPipeline: filters}
+}
+
+func TestDecode(t *testing.T) {
+	content := []byte("BT /F1 12 Tf (Hello) Tj ET")
+	predictor := types.Dict{"Predictor": types.Integer(12), "Columns": types.Integer(4)}
+
+	for name, sd := range map[string]*types.StreamDict{
+		"Flate":           stream(deflate(t, content), types.PDFFilter{Name: filter.Flate}),
+		"Flate and Flate": stream(deflate(t, deflate(t, content)), types.PDFFilter{Name: filter.Flate}, types.PDFFilter{Name: filter.Flate}),
+		"ASCIIHex and Flate": stream([]byte(hex.EncodeToString(deflate(t, content))+">"),
+			types.PDFFilter{Name: filter.ASCIIHex}, types.PDFFilter{Name: filter.Flate}),
+		"Flate with predictor": stream(deflate(t, []byte{2, 'B', 'T', ' ', '/', 2, 0, 0, 0, 0}),
+			types.PDFFilter{Name: filter.Flate, DecodeParms: predictor}),
+		"RunLength": stream([]byte{4, 'B', 'T', ' ', '/', 'F', 0x80}, types.PDFFilter{Name: filter.RunLength}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			unlimited := *sd
+			expected, err := Decode(&unlimited, 0, "stream")
+			require.NoError(t, err)
+
+			data, err := Decode(sd, 1024, "stream")
+			require.NoError(t, err)
+			assert.Equal(t, expected, data)
+		})
+	}
+}
+
+func TestDecode_Exceeded(t *testing.T) {
+	zeros := make([]byte, 16<<20)
+	predictor := types.Dict{"Predictor": types.Integer(12), "Columns": types.Integer(4)}
+
+	for name, sd := range map[string]*types.StreamDict{
+		"Flate":                stream(deflate(t, zeros), types.PDFFilter{Name: filter.Flate}),
+		"Flate and Flate":      stream(deflate(t, deflate(t, zeros)), types.PDFFilter{Name: filter.Flate}, types.PDFFilter{Name: filter.Flate}),
+		"Flate with predictor": stream(deflate(t, zeros), types.PDFFilter{Name: filter.Flate, DecodeParms: predictor}),
+		"RunLength":            stream(bytes.Repeat([]byte{0x81, 0}, 1<<16), types.PDFFilter{Name: filter.RunLength}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(sd, 1<<20, "stream")
+			assert.ErrorIs(t, err, ErrExceeded)
+		})
+	}
+}
+
+func TestDecode_UnsupportedFilter(t *testing.T) {
+	_, err := Decode(stream([]byte{0xff, 0xd8}, types.PDFFilter{Name: filter.DCT}), 1<<20, "stream")
+	assert.EqualError(t, err, "DCTDecode filter cannot be decoded with a size limit")
+}
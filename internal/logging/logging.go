@@ -0,0 +1,38 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package logging provides the structured logging helpers shared by attach and extract.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// discardHandler drops all records without formatting them.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// OrDiscard returns logger, or a logger dropping everything if logger is nil.
+func OrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(discardHandler{})
+	}
+	return logger
+}
+
+// Step logs the completion of a processing step at debug level with its duration since start.
+func Step(c context.Context, logger *slog.Logger, step string, start time.Time, attrs ...slog.Attr) {
+	if !logger.Enabled(c, slog.LevelDebug) {
+		return
+	}
+
+	attrs = append([]slog.Attr{slog.String("step", step), slog.Duration("duration", time.Since(start))}, attrs...)
+	logger.LogAttrs(c, slog.LevelDebug, "step done", attrs...)
+}
@@ -0,0 +1,432 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package pdftext
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// maxCMapEntries bounds the code mappings read from a ToUnicode CMap, so a hostile range cannot
+// exhaust memory.
+const maxCMapEntries = 1 << 17
+
+// font decodes the strings shown with a font to text and glyph widths.
+type font struct {
+	codespace    []codeRange       // byte sequences that form a code, by length
+	toUnicode    map[string]string // code bytes to text, from the ToUnicode CMap
+	encoding     *[256]rune        // simple fonts only
+	widths       map[int]float64   // glyph widths in 1/1000 of text space
+	defaultWidth float64
+}
+
+// codeRange is a codespace range of a CMap.
+type codeRange struct {
+	low, high []byte
+}
+
+// glyph is a decoded character code.
+type glyph struct {
+	text  string
+	width float64 // in 1/1000 of text space
+	space bool    // the single-byte code 32, which word spacing applies to
+}
+
+// fallbackFont is used when a font is missing or unreadable. Most producers use WinAnsiEncoding.
+var fallbackFont = &font{
+	codespace:    []codeRange{{low: []byte{0}, high: []byte{0xff}}},
+	encoding:     encodingTable(charmap.Windows1252),
+	defaultWidth: 500,
+}
+
+// decode splits s into codes and decodes them.
+func (f *font) decode(s []byte) []glyph {
+	var glyphs []glyph
+	for len(s) > 0 {
+		n := f.codeLength(s)
+		code := s[:n]
+		s = s[n:]
+
+		g := glyph{width: f.defaultWidth, space: n == 1 && code[0] == ' '}
+		if w, ok := f.widths[codeValue(code)]; ok {
+			g.width = w
+		}
+
+		if text, ok := f.toUnicode[string(code)]; ok {
+			g.text = text
+		} else if f.encoding != nil && n == 1 {
+			if r := f.encoding[code[0]]; r != 0 {
+				g.text = string(r)
+			}
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs
+}
+
+// codeLength returns the length of the code at the start of s according to the codespace ranges.
+func (f *font) codeLength(s []byte) int {
+	for _, r := range f.codespace {
+		n := len(r.low)
+		if n == 0 || n > len(s) {
+			continue
+		}
+
+		inside := true
+		for i := 0; i < n; i++ {
+			if s[i] < r.low[i] || s[i] > r.high[i] {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			return n
+		}
+	}
+
+	if len(f.codespace) > 0 && len(f.codespace[0].low) > 0 {
+		return min(len(f.codespace[0].low), len(s))
+	}
+	return 1
+}
+
+func codeValue(code []byte) int {
+	var v int
+	for _, b := range code {
+		v = v<<8 | int(b)
+	}
+	return v
+}
+
+// loadFont reads a font dictionary. Parts that cannot be read fall back to defaults, so text of
+// a damaged font is still found where possible. The ToUnicode CMap is decoded with at most
+// maxDecoded bytes.
+func loadFont(ctx *model.Context, obj types.Object, maxDecoded int64) *font {
+	d, err := ctx.DereferenceDict(obj)
+	if err != nil || d == nil {
+		return fallbackFont
+	}
+
+	f := &font{defaultWidth: 500}
+
+	subtype := ""
+	if s := d.NameEntry("Subtype"); s != nil {
+		subtype = *s
+	}
+
+	if subtype == "Type0" {
+		f.codespace = []codeRange{{low: []byte{0, 0}, high: []byte{0xff, 0xff}}}
+		f.defaultWidth = 1000
+		if descendants, err := ctx.DereferenceArray(d["DescendantFonts"]); err == nil && len(descendants) > 0 {
+			if cid, err := ctx.DereferenceDict(descendants[0]); err == nil && cid != nil {
+				f.cidWidths(ctx, cid)
+			}
+		}
+	} else {
+		f.codespace = fallbackFont.codespace
+		f.encoding = simpleEncoding(ctx, d)
+		f.simpleWidths(ctx, d, subtype)
+	}
+
+	if sd, _, err := ctx.DereferenceStreamDict(d["ToUnicode"]); err == nil && sd != nil {
+		if content, err := limits.Decode(sd, maxDecoded, "ToUnicode CMap"); err == nil {
+			toUnicode, codespace := parseCMap(content)
+			f.toUnicode = toUnicode
+			if len(codespace) > 0 {
+				f.codespace = codespace
+			}
+		}
+	}
+
+	return f
+}
+
+func (f *font) simpleWidths(ctx *model.Context, d types.Dict, subtype string) {
+	// Type 3 glyph widths are in glyph space, which the font matrix maps to text space.
+	scale := 1.0
+	if subtype == "Type3" {
+		if m, err := ctx.DereferenceArray(d["FontMatrix"]); err == nil && len(m) == 6 {
+			scale = number(ctx, m[0]) * 1000
+		}
+	}
+
+	if descriptor, err := ctx.DereferenceDict(d["FontDescriptor"]); err == nil && descriptor != nil {
+		if w := number(ctx, descriptor["MissingWidth"]); w > 0 {
+			f.defaultWidth = w * scale
+		}
+	}
+
+	widths, err := ctx.DereferenceArray(d["Widths"])
+	if err != nil || widths == nil {
+		return
+	}
+
+	first := int(number(ctx, d["FirstChar"]))
+	f.widths = make(map[int]float64, len(widths))
+	for i, w := range widths {
+		f.widths[first+i] = number(ctx, w) * scale
+	}
+}
+
+// cidWidths reads the /DW and /W entries of a CIDFont. Codes are taken as CIDs, which holds for
+// the Identity-H encoding that producers embedding subsets use.
+func (f *font) cidWidths(ctx *model.Context, cid types.Dict) {
+	if dw, ok := cid["DW"]; ok {
+		f.defaultWidth = number(ctx, dw)
+	}
+
+	w, err := ctx.DereferenceArray(cid["W"])
+	if err != nil {
+		return
+	}
+
+	f.widths = make(map[int]float64)
+	for i := 0; i+1 < len(w); {
+		first := int(number(ctx, w[i]))
+
+		if list, err := ctx.DereferenceArray(w[i+1]); err == nil && list != nil {
+			for j, width := range list {
+				f.widths[first+j] = number(ctx, width)
+			}
+			i += 2
+			continue
+		}
+
+		if i+2 >= len(w) {
+			return
+		}
+		last, width := int(number(ctx, w[i+1])), number(ctx, w[i+2])
+		for c := first; c <= last && c-first < maxCMapEntries; c++ {
+			f.widths[c] = width
+		}
+		i += 3
+	}
+}
+
+func number(ctx *model.Context, obj types.Object) float64 {
+	obj, err := ctx.Dereference(obj)
+	if err != nil {
+		return 0
+	}
+
+	switch n := obj.(type) {
+	case types.Integer:
+		return float64(n.Value())
+	case types.Float:
+		return n.Value()
+	}
+	return 0
+}
+
+// simpleEncoding returns the code to character table of a simple font from its /Encoding name or
+// dictionary with /Differences. StandardEncoding is approximated by WinAnsiEncoding, which agrees
+// with it on letters, digits and common punctuation.
+func simpleEncoding(ctx *model.Context, d types.Dict) *[256]rune {
+	obj, err := ctx.Dereference(d["Encoding"])
+	if err != nil {
+		return fallbackFont.encoding
+	}
+
+	switch e := obj.(type) {
+	case types.Name:
+		return namedEncoding(e.Value())
+	case types.Dict:
+		table := *fallbackFont.encoding
+		if base := e.NameEntry("BaseEncoding"); base != nil {
+			table = *namedEncoding(*base)
+		}
+
+		differences, err := ctx.DereferenceArray(e["Differences"])
+		if err != nil {
+			return &table
+		}
+
+		code := 0
+		for _, obj := range differences {
+			switch v := obj.(type) {
+			case types.Integer:
+				code = v.Value()
+			case types.Name:
+				if code >= 0 && code < len(table) {
+					table[code] = glyphRune(v.Value())
+				}
+				code++
+			}
+		}
+		return &table
+	}
+
+	return fallbackFont.encoding
+}
+
+var macRomanEncoding = encodingTable(charmap.Macintosh)
+
+func namedEncoding(name string) *[256]rune {
+	if name == "MacRomanEncoding" {
+		return macRomanEncoding
+	}
+	return fallbackFont.encoding
+}
+
+func encodingTable(c *charmap.Charmap) *[256]rune {
+	var table [256]rune
+	for i := range table {
+		if r := c.DecodeByte(byte(i)); r != '�' {
+			table[i] = r
+		}
+	}
+	return &table
+}
+
+// glyphNames maps the Adobe glyph names found in /Differences of invoice fonts to characters.
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$', "percent": '%',
+	"ampersand": '&', "quotesingle": '\'', "quoteright": '’', "parenleft": '(', "parenright": ')',
+	"asterisk": '*', "plus": '+', "comma": ',', "hyphen": '-', "minus": '−', "period": '.',
+	"slash": '/', "zero": '0', "one": '1', "two": '2', "three": '3', "four": '4', "five": '5',
+	"six": '6', "seven": '7', "eight": '8', "nine": '9', "colon": ':', "semicolon": ';',
+	"less": '<', "equal": '=', "greater": '>', "question": '?', "at": '@', "bracketleft": '[',
+	"backslash": '\\', "bracketright": ']', "asciicircum": '^', "underscore": '_', "grave": '`',
+	"quoteleft": '‘', "braceleft": '{', "bar": '|', "braceright": '}', "asciitilde": '~',
+	"Euro": '€', "sterling": '£', "yen": '¥', "cent": '¢', "currency": '¤', "section": '§',
+	"paragraph": '¶', "degree": '°', "copyright": '©', "registered": '®', "trademark": '™',
+	"multiply": '×', "divide": '÷', "plusminus": '±', "periodcentered": '·', "bullet": '•',
+	"ellipsis": '…', "endash": '–', "emdash": '—', "quotedblleft": '“', "quotedblright": '”',
+	"quotedblbase": '„', "quotesinglbase": '‚', "guillemotleft": '«', "guillemotright": '»',
+	"nbspace": ' ', "nonbreakingspace": ' ', "germandbls": 'ß', "mu": 'µ',
+	"Adieresis": 'Ä', "Odieresis": 'Ö', "Udieresis": 'Ü', "adieresis": 'ä', "odieresis": 'ö',
+	"udieresis": 'ü', "Aacute": 'Á', "Agrave": 'À', "Acircumflex": 'Â', "aacute": 'á',
+	"agrave": 'à', "acircumflex": 'â', "Ccedilla": 'Ç', "ccedilla": 'ç', "Eacute": 'É',
+	"Egrave": 'È', "Ecircumflex": 'Ê', "Edieresis": 'Ë', "eacute": 'é', "egrave": 'è',
+	"ecircumflex": 'ê', "edieresis": 'ë', "Iacute": 'Í', "Icircumflex": 'Î', "Idieresis": 'Ï',
+	"iacute": 'í', "icircumflex": 'î', "idieresis": 'ï', "Ntilde": 'Ñ', "ntilde": 'ñ',
+	"Oacute": 'Ó', "Ocircumflex": 'Ô', "oacute": 'ó', "ocircumflex": 'ô', "Oslash": 'Ø',
+	"oslash": 'ø', "Uacute": 'Ú', "Ugrave": 'Ù', "Ucircumflex": 'Û', "uacute": 'ú', "ugrave": 'ù',
+	"ucircumflex": 'û', "Aring": 'Å', "aring": 'å', "AE": 'Æ', "ae": 'æ', "OE": 'Œ', "oe": 'œ',
+	"ordfeminine": 'ª', "ordmasculine": 'º', "fi": 'ﬁ', "fl": 'ﬂ',
+}
+
+// glyphRune maps a glyph name to its character: single letters and digits stand for themselves,
+// uniXXXX and uXXXX[XX] name code points, other names are looked up in glyphNames.
+func glyphRune(name string) rune {
+	if r, ok := glyphNames[name]; ok {
+		return r
+	}
+	if len(name) == 1 {
+		return rune(name[0])
+	}
+
+	name, _, _ = strings.Cut(name, ".") // variants like "one.oldstyle"
+	if r, ok := glyphNames[name]; ok {
+		return r
+	}
+
+	for _, prefix := range []string{"uni", "u"} {
+		if hex, ok := strings.CutPrefix(name, prefix); ok && len(hex) >= 4 && len(hex) <= 6 {
+			if v, err := strconv.ParseUint(hex[:4+len(hex)%4], 16, 32); err == nil {
+				return rune(v)
+			}
+		}
+	}
+	return 0
+}
+
+// parseCMap reads the bfchar and bfrange mappings and the codespace ranges of a ToUnicode CMap.
+func parseCMap(data []byte) (map[string]string, []codeRange) {
+	var (
+		l         = lexer{data: data}
+		operands  []object
+		mapping   = make(map[string]string)
+		codespace []codeRange
+	)
+
+	for {
+		obj := l.next()
+		switch obj.kind {
+		case kindEOF:
+			return mapping, codespace
+		case kindOperator:
+		default:
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch string(obj.bytes) {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				low, high := operands[i].bytes, operands[i+1].bytes
+				if len(low) > 0 && len(low) == len(high) {
+					codespace = append(codespace, codeRange{low: low, high: high})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands) && len(mapping) < maxCMapEntries; i += 2 {
+				mapping[string(operands[i].bytes)] = cmapText(operands[i+1])
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				bfRange(mapping, operands[i].bytes, operands[i+1].bytes, operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// bfRange adds the codes low to high, which map to consecutive characters starting at dst or to
+// the elements of the dst array.
+func bfRange(mapping map[string]string, low, high []byte, dst object) {
+	if len(low) == 0 || len(low) != len(high) || len(low) > 4 {
+		return
+	}
+
+	first, last := codeValue(low), codeValue(high)
+	for code := first; code <= last && len(mapping) < maxCMapEntries; code++ {
+		key := make([]byte, len(low))
+		for i, v := len(key)-1, code; i >= 0; i, v = i-1, v>>8 {
+			key[i] = byte(v)
+		}
+
+		switch {
+		case dst.kind == kindArray:
+			if code-first < len(dst.array) {
+				mapping[string(key)] = cmapText(dst.array[code-first])
+			}
+		case len(dst.bytes) >= 2:
+			units := utf16Units(dst.bytes)
+			units[len(units)-1] += uint16(code - first)
+			mapping[string(key)] = string(utf16.Decode(units))
+		default:
+			mapping[string(key)] = string(rune(codeValue(dst.bytes) + code - first))
+		}
+	}
+}
+
+// cmapText decodes the UTF-16BE destination of a mapping, which can also be a glyph name.
+func cmapText(obj object) string {
+	if obj.kind == kindName {
+		if r := glyphRune(string(obj.bytes)); r != 0 {
+			return string(r)
+		}
+		return ""
+	}
+	if len(obj.bytes) == 1 {
+		return string(rune(obj.bytes[0]))
+	}
+	return string(utf16.Decode(utf16Units(obj.bytes)))
+}
+
+func utf16Units(b []byte) []uint16 {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return units
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package pdftext
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// kind is the type of a content stream token.
+type kind int
+
+const (
+	kindEOF kind = iota
+	kindNumber
+	kindString // literal and hex strings, as raw bytes
+	kindName
+	kindArray
+	kindDict // dictionaries are only skipped, their entries are dropped
+	kindOperator
+)
+
+// object is an operand or operator of a content stream.
+type object struct {
+	kind   kind
+	number float64
+	bytes  []byte // string bytes, name or operator
+	array  []object
+}
+
+// lexer reads the objects of a content stream or CMap. It is lenient: malformed input ends the
+// stream or yields operators that the interpreter ignores, it never fails.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isWhitespace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case isWhitespace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next object, reading arrays and dictionaries as a whole.
+func (l *lexer) next() object {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return object{kind: kindEOF}
+	}
+
+	switch c := l.data[l.pos]; c {
+	case '(':
+		l.pos++
+		return object{kind: kindString, bytes: l.literal()}
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			l.dict()
+			return object{kind: kindDict}
+		}
+		l.pos++
+		return object{kind: kindString, bytes: l.hex()}
+	case '[':
+		l.pos++
+		var array []object
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return object{kind: kindArray, array: array}
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return object{kind: kindArray, array: array}
+			}
+			array = append(array, l.next())
+		}
+	case '/':
+		l.pos++
+		return object{kind: kindName, bytes: l.regular()}
+	case ')', '>', ']', '{', '}':
+		// Stray delimiters carry no text.
+		l.pos++
+		return object{kind: kindOperator, bytes: []byte{c}}
+	}
+
+	word := l.regular()
+	if n, err := strconv.ParseFloat(string(word), 64); err == nil {
+		return object{kind: kindNumber, number: n}
+	}
+	return object{kind: kindOperator, bytes: word}
+}
+
+// regular reads the characters up to the next whitespace or delimiter.
+func (l *lexer) regular() []byte {
+	start := l.pos
+	for l.pos < len(l.data) && !isWhitespace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start && l.pos < len(l.data) {
+		l.pos++
+	}
+	return l.data[start:l.pos]
+}
+
+// literal reads a literal string after its opening parenthesis.
+func (l *lexer) literal() []byte {
+	var (
+		out   []byte
+		depth = 1
+	)
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			c = l.data[l.pos]
+			l.pos++
+
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				n := int(c - '0')
+				for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+					n = n*8 + int(l.data[l.pos]-'0')
+					l.pos++
+				}
+				c = byte(n)
+			}
+		}
+		out = append(out, c)
+	}
+
+	return out
+}
+
+// hex reads a hex string after its opening angle bracket. A missing last digit counts as zero.
+func (l *lexer) hex() []byte {
+	end := bytes.IndexByte(l.data[l.pos:], '>')
+	if end < 0 {
+		end = len(l.data) - l.pos
+	}
+
+	var (
+		out  []byte
+		half = -1
+	)
+	for _, c := range l.data[l.pos : l.pos+end] {
+		var v int
+		switch {
+		case c >= '0' && c <= '9':
+			v = int(c - '0')
+		case c >= 'a' && c <= 'f':
+			v = int(c-'a') + 10
+		case c >= 'A' && c <= 'F':
+			v = int(c-'A') + 10
+		default:
+			continue
+		}
+
+		if half < 0 {
+			half = v
+		} else {
+			out = append(out, byte(half<<4|v))
+			half = -1
+		}
+	}
+	if half >= 0 {
+		out = append(out, byte(half<<4))
+	}
+
+	l.pos = min(l.pos+end+1, len(l.data))
+	return out
+}
+
+// dict skips a dictionary after its opening brackets.
+func (l *lexer) dict() {
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return
+		}
+		if bytes.HasPrefix(l.data[l.pos:], []byte(">>")) {
+			l.pos += 2
+			return
+		}
+		l.next()
+	}
+}
+
+// skipInlineImage moves past the data of an inline image, which starts after the ID operator and
+// ends with EI surrounded by whitespace.
+func (l *lexer) skipInlineImage() {
+	if l.pos < len(l.data) && isWhitespace(l.data[l.pos]) {
+		l.pos++
+	}
+
+	for i := l.pos; i+1 < len(l.data); i++ {
+		if l.data[i] == 'E' && l.data[i+1] == 'I' &&
+			(i == 0 || isWhitespace(l.data[i-1])) &&
+			(i+2 == len(l.data) || isWhitespace(l.data[i+2])) {
+			l.pos = i + 2
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
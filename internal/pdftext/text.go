@@ -0,0 +1,502 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package pdftext extracts the visible text of PDF pages from their content streams. It decodes
+// the strings of the text showing operators with the font encodings and ToUnicode CMaps and orders
+// them into lines by their position on the page, which is enough to look up values such as
+// invoice numbers and amounts. It does not aim at a faithful reading order for complex layouts.
+package pdftext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxFormDepth bounds the nesting of form XObjects, which also stops reference cycles.
+const maxFormDepth = 8
+
+// maxPageObjects bounds the operands and operators interpreted per page, including those of the
+// forms it paints. Forms painting the same form many times would otherwise multiply the work with
+// every nesting level.
+const maxPageObjects = 1 << 20
+
+// Pages returns the text of every page, the first page at index 0. Lines are separated by "\n".
+// ctx must have been validated, which resolves object streams. Form XObjects and ToUnicode CMaps
+// are decoded with at most maxDecoded bytes each, 0 disables the limit.
+func Pages(c context.Context, ctx *model.Context, maxDecoded int64) ([]string, error) {
+	i := &interpreter{ctx: ctx, maxDecoded: maxDecoded, fonts: make(map[int]*font), forms: make(map[int]*form)}
+
+	pages := make([]string, 0, ctx.PageCount)
+	for nr := 1; nr <= ctx.PageCount; nr++ {
+		if err := c.Err(); err != nil {
+			return nil, err
+		}
+
+		text, err := i.page(nr)
+		if err != nil {
+			return nil, fmt.Errorf("could not extract text of page %d: %w", nr, err)
+		}
+		pages = append(pages, text)
+	}
+
+	return pages, nil
+}
+
+type interpreter struct {
+	ctx        *model.Context
+	maxDecoded int64
+	fonts      map[int]*font // by object number of the font dictionary
+	forms      map[int]*form // by object number of the form XObject, nil if it is not a form
+	objects    int           // operands and operators interpreted on the current page
+	runs       []run
+}
+
+// form is a decoded form XObject.
+type form struct {
+	content   []byte
+	matrix    *matrix
+	resources types.Dict
+}
+
+// run is a string shown in one piece.
+type run struct {
+	x, y, endX float64 // baseline start and end in device space
+	size       float64 // font size in device space
+	text       string
+}
+
+func (i *interpreter) page(nr int) (string, error) {
+	d, _, _, err := i.ctx.PageDict(nr, false)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := i.ctx.PageContent(d)
+	if errors.Is(err, model.ErrNoContent) || err == nil && content == nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	i.runs = i.runs[:0]
+	i.objects = 0
+	if err = i.execute(content, i.pageResources(d), newState(identity), 0); err != nil {
+		return "", err
+	}
+
+	return lines(i.runs), nil
+}
+
+// pageResources returns the resources of a page, which it can inherit from the page tree.
+func (i *interpreter) pageResources(d types.Dict) types.Dict {
+	for depth := 0; d != nil && depth < 64; depth++ {
+		if res, err := i.ctx.DereferenceDict(d["Resources"]); err == nil && res != nil {
+			return res
+		}
+
+		parent, err := i.ctx.DereferenceDict(d["Parent"])
+		if err != nil {
+			return nil
+		}
+		d = parent
+	}
+	return nil
+}
+
+// matrix is a PDF transformation matrix [a b c d e f].
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n, which applies m first.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return x*m[0] + y*m[2] + m[4], x*m[1] + y*m[3] + m[5]
+}
+
+func translate(x, y float64) matrix {
+	return matrix{1, 0, 0, 1, x, y}
+}
+
+// state is the part of the graphics state that affects where text appears.
+type state struct {
+	ctm       matrix
+	font      *font
+	size      float64
+	charSpace float64
+	wordSpace float64
+	scale     float64 // horizontal scaling, 1 is 100 %
+	leading   float64
+	rise      float64
+}
+
+func newState(ctm matrix) state {
+	return state{ctm: ctm, font: fallbackFont, scale: 1}
+}
+
+// execute interprets a content stream. Unknown operators and malformed operands are skipped.
+// It fails once the page exceeds maxPageObjects or a form exceeds the decode limit.
+func (i *interpreter) execute(content []byte, resources types.Dict, st state, depth int) error {
+	var (
+		l        = lexer{data: content}
+		operands []object
+		saved    []state
+		tm, tlm  = identity, identity
+	)
+
+	nums := func(n int) ([]float64, bool) {
+		if len(operands) < n {
+			return nil, false
+		}
+		out := make([]float64, n)
+		for k, o := range operands[len(operands)-n:] {
+			if o.kind != kindNumber {
+				return nil, false
+			}
+			out[k] = o.number
+		}
+		return out, true
+	}
+
+	last := func(k kind) (object, bool) {
+		if len(operands) == 0 || operands[len(operands)-1].kind != k {
+			return object{}, false
+		}
+		return operands[len(operands)-1], true
+	}
+
+	nextLine := func(tx, ty float64) {
+		tlm = translate(tx, ty).mul(tlm)
+		tm = tlm
+	}
+
+	for {
+		obj := l.next()
+		if obj.kind == kindEOF {
+			return nil
+		}
+
+		if i.objects++; i.objects > maxPageObjects {
+			return fmt.Errorf("%w: page has more than %d content stream objects", limits.ErrExceeded, maxPageObjects)
+		}
+		if obj.kind != kindOperator {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch string(obj.bytes) {
+		case "q":
+			saved = append(saved, st)
+		case "Q":
+			if len(saved) > 0 {
+				st = saved[len(saved)-1]
+				saved = saved[:len(saved)-1]
+			}
+		case "cm":
+			if n, ok := nums(6); ok {
+				st.ctm = matrix(n).mul(st.ctm)
+			}
+		case "BT":
+			tm, tlm = identity, identity
+		case "Tf":
+			if n, ok := nums(1); ok && len(operands) >= 2 && operands[len(operands)-2].kind == kindName {
+				st.font = i.font(resources, string(operands[len(operands)-2].bytes))
+				st.size = n[0]
+			}
+		case "Tc":
+			if n, ok := nums(1); ok {
+				st.charSpace = n[0]
+			}
+		case "Tw":
+			if n, ok := nums(1); ok {
+				st.wordSpace = n[0]
+			}
+		case "Tz":
+			if n, ok := nums(1); ok {
+				st.scale = n[0] / 100
+			}
+		case "TL":
+			if n, ok := nums(1); ok {
+				st.leading = n[0]
+			}
+		case "Ts":
+			if n, ok := nums(1); ok {
+				st.rise = n[0]
+			}
+		case "Td":
+			if n, ok := nums(2); ok {
+				nextLine(n[0], n[1])
+			}
+		case "TD":
+			if n, ok := nums(2); ok {
+				st.leading = -n[1]
+				nextLine(n[0], n[1])
+			}
+		case "Tm":
+			if n, ok := nums(6); ok {
+				tm, tlm = matrix(n), matrix(n)
+			}
+		case "T*":
+			nextLine(0, -st.leading)
+		case "Tj":
+			if s, ok := last(kindString); ok {
+				tm = i.show(st, tm, s.bytes)
+			}
+		case "'":
+			if s, ok := last(kindString); ok {
+				nextLine(0, -st.leading)
+				tm = i.show(st, tm, s.bytes)
+			}
+		case "\"":
+			if s, ok := last(kindString); ok {
+				if n := len(operands); n >= 3 && operands[n-3].kind == kindNumber && operands[n-2].kind == kindNumber {
+					st.wordSpace, st.charSpace = operands[n-3].number, operands[n-2].number
+				}
+				nextLine(0, -st.leading)
+				tm = i.show(st, tm, s.bytes)
+			}
+		case "TJ":
+			if a, ok := last(kindArray); ok {
+				for _, e := range a.array {
+					switch e.kind {
+					case kindString:
+						tm = i.show(st, tm, e.bytes)
+					case kindNumber:
+						tm = translate(-e.number/1000*st.size*st.scale, 0).mul(tm)
+					}
+				}
+			}
+		case "Do":
+			if name, ok := last(kindName); ok && depth < maxFormDepth {
+				if err := i.paint(resources, string(name.bytes), st, depth); err != nil {
+					return err
+				}
+			}
+		case "ID":
+			l.skipInlineImage()
+		}
+
+		operands = operands[:0]
+	}
+}
+
+// show records s as a run and returns the text matrix advanced past it.
+func (i *interpreter) show(st state, tm matrix, s []byte) matrix {
+	var text strings.Builder
+	start := tm
+	for _, g := range st.font.decode(s) {
+		text.WriteString(g.text)
+
+		tx := g.width/1000*st.size + st.charSpace
+		if g.space {
+			tx += st.wordSpace
+		}
+		tm = translate(tx*st.scale, 0).mul(tm)
+	}
+
+	if text.Len() == 0 {
+		return tm
+	}
+
+	rise := translate(0, st.rise)
+	begin, end := rise.mul(start).mul(st.ctm), rise.mul(tm).mul(st.ctm)
+
+	r := run{size: math.Abs(st.size) * math.Hypot(begin[2], begin[3]), text: text.String()}
+	r.x, r.y = begin.apply(0, 0)
+	r.endX, _ = end.apply(0, 0)
+	i.runs = append(i.runs, r)
+
+	return tm
+}
+
+// font returns the font resource name, reading each font dictionary once.
+func (i *interpreter) font(resources types.Dict, name string) *font {
+	fonts, err := i.ctx.DereferenceDict(resources["Font"])
+	if err != nil || fonts == nil {
+		return fallbackFont
+	}
+
+	obj, ok := fonts[name]
+	if !ok {
+		return fallbackFont
+	}
+
+	ref, isRef := obj.(types.IndirectRef)
+	if isRef {
+		if f, ok := i.fonts[ref.ObjectNumber.Value()]; ok {
+			return f
+		}
+	}
+
+	f := loadFont(i.ctx, obj, i.maxDecoded)
+	if isRef {
+		i.fonts[ref.ObjectNumber.Value()] = f
+	}
+	return f
+}
+
+// paint interprets the form XObject resource name with its own matrix and resources.
+func (i *interpreter) paint(resources types.Dict, name string, st state, depth int) error {
+	xObjects, err := i.ctx.DereferenceDict(resources["XObject"])
+	if err != nil || xObjects == nil {
+		return nil
+	}
+
+	f, err := i.form(xObjects[name])
+	if err != nil || f == nil {
+		return err
+	}
+
+	if f.matrix != nil {
+		st.ctm = f.matrix.mul(st.ctm)
+	}
+
+	if f.resources != nil {
+		resources = f.resources
+	}
+
+	return i.execute(f.content, resources, st, depth+1)
+}
+
+// form returns the form XObject obj, decoding each one once. It returns nil for other XObjects and
+// forms that cannot be decoded, and an error only if a form exceeds the decode limit.
+func (i *interpreter) form(obj types.Object) (*form, error) {
+	ref, isRef := obj.(types.IndirectRef)
+	if isRef {
+		if f, ok := i.forms[ref.ObjectNumber.Value()]; ok {
+			return f, nil
+		}
+	}
+
+	f, err := i.loadForm(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if isRef {
+		i.forms[ref.ObjectNumber.Value()] = f
+	}
+	return f, nil
+}
+
+func (i *interpreter) loadForm(obj types.Object) (*form, error) {
+	sd, _, err := i.ctx.DereferenceStreamDict(obj)
+	if err != nil || sd == nil {
+		return nil, nil
+	}
+	if subtype := sd.NameEntry("Subtype"); subtype == nil || *subtype != "Form" {
+		return nil, nil
+	}
+
+	// Decoding without a limit replaces the content of the shared stream dict, so work on a copy.
+	stream := *sd
+	content, err := limits.Decode(&stream, i.maxDecoded, "form XObject")
+	if errors.Is(err, limits.ErrExceeded) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, nil
+	}
+
+	f := &form{content: content}
+
+	if m, err := i.ctx.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(m) == 6 {
+		var n matrix
+		for k := range n {
+			n[k] = number(i.ctx, m[k])
+		}
+		f.matrix = &n
+	}
+
+	if res, err := i.ctx.DereferenceDict(sd.Dict["Resources"]); err == nil && res != nil {
+		f.resources = res
+	}
+
+	return f, nil
+}
+
+// line is a run of text on the same baseline.
+type line struct {
+	y, size float64
+	runs    []run
+}
+
+// lines groups runs whose baselines are close into lines, top to bottom, and joins the runs of a
+// line left to right. A space is inserted where a gap separates two runs.
+func lines(runs []run) string {
+	var out []*line
+	for _, r := range runs {
+		var target *line
+		for _, l := range out {
+			if math.Abs(l.y-r.y) < max(min(l.size, r.size), 1)/2 {
+				target = l
+				break
+			}
+		}
+
+		if target == nil {
+			target = &line{y: r.y, size: r.size}
+			out = append(out, target)
+		}
+		target.runs = append(target.runs, r)
+	}
+
+	slices.SortStableFunc(out, func(a, b *line) int {
+		switch {
+		case a.y > b.y:
+			return -1
+		case a.y < b.y:
+			return 1
+		}
+		return 0
+	})
+
+	text := make([]string, 0, len(out))
+	for _, l := range out {
+		slices.SortStableFunc(l.runs, func(a, b run) int {
+			switch {
+			case a.x < b.x:
+				return -1
+			case a.x > b.x:
+				return 1
+			}
+			return 0
+		})
+
+		var s strings.Builder
+		for k, r := range l.runs {
+			if k > 0 {
+				prev := l.runs[k-1]
+				if r.x-prev.endX > r.size*0.15 && !strings.HasSuffix(s.String(), " ") && !strings.HasPrefix(r.text, " ") {
+					s.WriteByte(' ')
+				}
+			}
+			s.WriteString(r.text)
+		}
+		if t := strings.TrimRight(s.String(), " "); strings.TrimSpace(t) != "" {
+			text = append(text, t)
+		}
+	}
+
+	return strings.Join(text, "\n")
+}
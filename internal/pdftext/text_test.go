@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package pdftext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCMap(t *testing.T) {
+	mapping, codespace := parseCMap([]byte(`
+/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0003> <0020>
+<0011> <00540065>
+endbfchar
+2 beginbfrange
+<0024> <0026> <0041>
+<0030> <0031> [<20AC> <D83DDE00>]
+endbfrange
+endcmap`))
+
+	require.Len(t, codespace, 1)
+	f := &font{codespace: codespace, toUnicode: mapping, defaultWidth: 1000}
+
+	var text string
+	for _, g := range f.decode([]byte{0, 0x24, 0, 0x25, 0, 0x26, 0, 3, 0, 0x11, 0, 0x30, 0, 0x31}) {
+		text += g.text
+	}
+	assert.Equal(t, "ABC Te€😀", text)
+}
+
+func TestExecute(t *testing.T) {
+	ctx, err := pdfcpu.CreateContextWithXRefTable(nil, &types.Dim{Width: 595, Height: 842})
+	require.NoError(t, err)
+
+	i := &interpreter{ctx: ctx, fonts: make(map[int]*font), forms: make(map[int]*form)}
+	err = i.execute([]byte(`
+BT /F1 10 Tf 1 0 0 1 300 700 Tm (1.190,00) Tj ET
+BT /F1 10 Tf 50 700 Td [(T) 120 (otal)] TJ ET
+q 1 0 0 1 0 -20 cm BT /F1 10 Tf 50 700 Td (Due \(net\)) Tj 0 -12 Td <4556> Tj ET Q
+BI /W 1 /H 1 /CS /G /BPC 8 ID x EI
+`), nil, newState(identity), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Total 1.190,00\nDue (net)\nEV", lines(i.runs))
+}
+
+// TestExecute_FormBomb checks that nested forms painting each other many times are stopped.
+func TestExecute_FormBomb(t *testing.T) {
+	ctx, err := pdfcpu.CreateContextWithXRefTable(nil, &types.Dim{Width: 595, Height: 842})
+	require.NoError(t, err)
+
+	// Each of the forms paints the next one 16 times, 16^8 paints in total.
+	var next types.Object
+	for range maxFormDepth + 1 {
+		d := types.Dict{"Subtype": types.Name("Form")}
+		content := "BT /F1 10 Tf (x) Tj ET"
+		if next != nil {
+			d["Resources"] = types.Dict{"XObject": types.Dict{"X": next}}
+			content = strings.Repeat("/X Do ", 16)
+		}
+		ref, err := ctx.IndRefForNewObject(types.StreamDict{Dict: d, Raw: []byte(content)})
+		require.NoError(t, err)
+		next = *ref
+	}
+
+	i := &interpreter{ctx: ctx, fonts: make(map[int]*font), forms: make(map[int]*form)}
+	err = i.execute([]byte("/X Do"), types.Dict{"XObject": types.Dict{"X": next}}, newState(identity), 0)
+	assert.ErrorIs(t, err, limits.ErrExceeded)
+	assert.Len(t, i.forms, maxFormDepth)
+}
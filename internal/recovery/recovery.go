@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package recovery turns panics deep inside pdfcpu or go-xmp into errors, so a crafted PDF cannot
+// take down the process that reads it.
+package recovery
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Error is returned instead of a panic raised while processing a PDF or XML.
+type Error struct {
+	Op    string // the function that panicked, like "extract"
+	Value any    // the value passed to panic
+	Stack []byte // the stack of the panicking goroutine
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: recovered from panic: %v", e.Op, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, like a runtime.Error.
+func (e *Error) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Recover stores a panic of the surrounding function as an *Error in err. It must be deferred
+// directly, as in defer recovery.Recover("extract", &err).
+func Recover(op string, err *error) {
+	if value := recover(); value != nil {
+		*err = &Error{Op: op, Value: value, Stack: debug.Stack()}
+	}
+}
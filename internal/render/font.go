@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// firstChar and lastChar bound the WinAnsiEncoding codes that are used.
+const (
+	firstChar = 32
+	lastChar  = 255
+)
+
+// fontFace is an embedded TrueType font with WinAnsiEncoding, which covers the Latin-1 names and
+// addresses of European invoices.
+type fontFace struct {
+	resource string
+	name     string
+	data     []byte
+	widths   [lastChar + 1]float64 // in 1/1000 em
+	bbox     [4]float64
+	ascent   float64
+	descent  float64
+	capHigh  float64
+}
+
+func newFontFace(resource string, data []byte) (*fontFace, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse font: %w", err)
+	}
+
+	var (
+		b    sfnt.Buffer
+		ppem = fixed.I(1000)
+	)
+
+	name, err := f.Name(&b, sfnt.NameIDPostScript)
+	if err != nil {
+		return nil, fmt.Errorf("could not read font name: %w", err)
+	}
+
+	face := &fontFace{resource: resource, name: name, data: data}
+
+	for c := firstChar; c <= lastChar; c++ {
+		r := charmap.Windows1252.DecodeByte(byte(c))
+		index, err := f.GlyphIndex(&b, r)
+		if err != nil || index == 0 {
+			continue
+		}
+
+		advance, err := f.GlyphAdvance(&b, index, ppem, font.HintingNone)
+		if err != nil {
+			return nil, fmt.Errorf("could not read advance of %q: %w", r, err)
+		}
+		face.widths[c] = float64(advance) / 64
+	}
+
+	bounds, err := f.Bounds(&b, ppem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("could not read font bounds: %w", err)
+	}
+	// sfnt uses a y axis pointing down.
+	face.bbox = [4]float64{
+		float64(bounds.Min.X) / 64, -float64(bounds.Max.Y) / 64,
+		float64(bounds.Max.X) / 64, -float64(bounds.Min.Y) / 64,
+	}
+
+	metrics, err := f.Metrics(&b, ppem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("could not read font metrics: %w", err)
+	}
+	face.ascent = float64(metrics.Ascent) / 64
+	face.descent = -float64(metrics.Descent) / 64
+	face.capHigh = float64(metrics.CapHeight) / 64
+
+	return face, nil
+}
+
+// encode converts s to WinAnsiEncoding, replacing characters the encoding lacks with '?'.
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch r {
+		case '\t', '\n', '\r':
+			r = ' '
+		}
+
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok || c < firstChar {
+			c = '?'
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// width returns the width of s in points at size.
+func (f *fontFace) width(s string, size float64) float64 {
+	var w float64
+	for _, c := range encode(s) {
+		w += f.widths[c]
+	}
+	return w * size / 1000
+}
+
+// embed adds the font dictionary with its descriptor and the complete font program to the
+// document and returns a reference to it.
+func (f *fontFace) embed(xRefTable *model.XRefTable) (*types.IndirectRef, error) {
+	program, err := xRefTable.NewStreamDictForBuf(f.data)
+	if err != nil {
+		return nil, err
+	}
+	program.InsertInt("Length1", len(f.data))
+	if err = program.Encode(); err != nil {
+		return nil, fmt.Errorf("could not encode font: %w", err)
+	}
+
+	programRef, err := xRefTable.IndRefForNewObject(*program)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor := types.Dict{
+		"Type":        types.Name("FontDescriptor"),
+		"FontName":    types.Name(f.name),
+		"Flags":       types.Integer(32), // nonsymbolic
+		"FontBBox":    types.NewNumberArray(f.bbox[:]...),
+		"ItalicAngle": types.Integer(0),
+		"Ascent":      types.Float(f.ascent),
+		"Descent":     types.Float(f.descent),
+		"CapHeight":   types.Float(f.capHigh),
+		"StemV":       types.Integer(80),
+		"FontFile2":   *programRef,
+	}
+	if strings.Contains(f.name, "Bold") {
+		descriptor["StemV"] = types.Integer(140)
+	}
+
+	descriptorRef, err := xRefTable.IndRefForNewObject(descriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	widths := make(types.Array, 0, lastChar-firstChar+1)
+	for c := firstChar; c <= lastChar; c++ {
+		widths = append(widths, types.Float(f.widths[c]))
+	}
+
+	return xRefTable.IndRefForNewObject(types.Dict{
+		"Type":           types.Name("Font"),
+		"Subtype":        types.Name("TrueType"),
+		"BaseFont":       types.Name(f.name),
+		"FirstChar":      types.Integer(firstChar),
+		"LastChar":       types.Integer(lastChar),
+		"Widths":         widths,
+		"Encoding":       types.Name("WinAnsiEncoding"),
+		"FontDescriptor": *descriptorRef,
+	})
+}
+
+func loadFonts() (regular, bold *fontFace, err error) {
+	if regular, err = newFontFace("F1", goregular.TTF); err != nil {
+		return nil, nil, err
+	}
+	if bold, err = newFontFace("F2", gobold.TTF); err != nil {
+		return nil, nil, err
+	}
+	return regular, bold, nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package render
+
+import (
+	"strings"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+)
+
+// documentTitle names the UNTDID 1001 document types used with EN 16931.
+func documentTitle(typeCode string) string {
+	switch typeCode {
+	case "381":
+		return "Credit note"
+	case "384":
+		return "Corrected invoice"
+	case "389":
+		return "Self-billed invoice"
+	case "326":
+		return "Partial invoice"
+	case "386":
+		return "Prepayment invoice"
+	case "875", "876", "877":
+		return "Partial construction invoice"
+	default:
+		return "Invoice"
+	}
+}
+
+// unit names common UN/ECE Recommendation 20 codes and returns others unchanged.
+func unit(code string) string {
+	switch code {
+	case "C62", "H87", "XPP", "EA":
+		return "pcs"
+	case "HUR":
+		return "h"
+	case "MIN":
+		return "min"
+	case "DAY":
+		return "days"
+	case "WEE":
+		return "weeks"
+	case "MON":
+		return "months"
+	case "ANN":
+		return "years"
+	case "KGM":
+		return "kg"
+	case "TNE":
+		return "t"
+	case "MTR":
+		return "m"
+	case "MTK":
+		return "m²"
+	case "MTQ":
+		return "m³"
+	case "LTR":
+		return "l"
+	case "KMT":
+		return "km"
+	case "KWH":
+		return "kWh"
+	case "LS":
+		return "lump sum"
+	case "P1":
+		return "%"
+	default:
+		return code
+	}
+}
+
+func vatCategory(code string) string {
+	switch code {
+	case "S":
+		return "Standard rate"
+	case "Z":
+		return "Zero rated"
+	case "E":
+		return "Exempt"
+	case "AE":
+		return "Reverse charge"
+	case "K":
+		return "Intra-community supply"
+	case "G":
+		return "Export outside the EU"
+	case "O":
+		return "Not subject to VAT"
+	case "L":
+		return "Canary Islands IGIC"
+	case "M":
+		return "Ceuta and Melilla IPSI"
+	default:
+		return code
+	}
+}
+
+func paymentMeans(code string) string {
+	switch code {
+	case "10":
+		return "Cash"
+	case "30":
+		return "Credit transfer"
+	case "42":
+		return "Payment to bank account"
+	case "48", "54", "55":
+		return "Card payment"
+	case "49":
+		return "Direct debit"
+	case "57":
+		return "Standing agreement"
+	case "58":
+		return "SEPA credit transfer"
+	case "59":
+		return "SEPA direct debit"
+	case "97":
+		return "Clearing between partners"
+	default:
+		return "Payment means " + code
+	}
+}
+
+// amount shows a monetary amount with at least two decimals.
+func amount(d cii.Decimal) string {
+	return decimals(d, 2)
+}
+
+func quantity(d cii.Decimal) string {
+	return decimals(d, 0)
+}
+
+func percent(d cii.Decimal) string {
+	if d == "" {
+		return ""
+	}
+	return decimals(d, 0) + " %"
+}
+
+// price shows the net price, per base quantity if that is not one.
+func price(d, base cii.Decimal) string {
+	p := amount(d)
+	if b := quantity(base); b != "" && b != "1" {
+		p += " / " + b
+	}
+	return p
+}
+
+// decimals trims trailing zeros of d down to at least places decimals.
+func decimals(d cii.Decimal, places int) string {
+	s := strings.TrimSpace(string(d))
+	if s == "" {
+		return ""
+	}
+
+	integer, fraction, _ := strings.Cut(s, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	for len(fraction) < places {
+		fraction += "0"
+	}
+
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
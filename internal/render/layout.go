@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page geometry in points, A4 portrait.
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	marginLeft   = 50.0
+	marginRight  = pageWidth - 50.0
+	marginTop    = pageHeight - 50.0
+	marginBottom = 70.0
+	footerY      = 35.0
+)
+
+// layout writes text and rules top to bottom and starts a new page when the current one is full.
+type layout struct {
+	regular, bold *fontFace
+	pages         []*bytes.Buffer
+	page          *bytes.Buffer
+	y             float64
+
+	// onNewPage is called after a page break, e.g. to repeat a table header.
+	onNewPage func()
+}
+
+func newLayout(regular, bold *fontFace) *layout {
+	l := &layout{regular: regular, bold: bold}
+	l.newPage()
+	return l
+}
+
+func (l *layout) newPage() {
+	l.page = new(bytes.Buffer)
+	l.pages = append(l.pages, l.page)
+	l.y = marginTop
+
+	if l.onNewPage != nil {
+		l.onNewPage()
+	}
+}
+
+// ensure starts a new page unless height points fit above the bottom margin.
+func (l *layout) ensure(height float64) {
+	if l.y-height < marginBottom {
+		l.newPage()
+	}
+}
+
+func (l *layout) space(height float64) {
+	l.y -= height
+}
+
+// text writes s with its baseline at y, left aligned at x.
+func (l *layout) text(f *fontFace, size, x, y float64, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(l.page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", f.resource, num(size), num(x), num(y), escape(encode(s)))
+}
+
+// textRight writes s right aligned at x.
+func (l *layout) textRight(f *fontFace, size, x, y float64, s string) {
+	l.text(f, size, x-f.width(s, size), y, s)
+}
+
+// rule draws a horizontal line at y from x1 to x2.
+func (l *layout) rule(x1, x2, y, width float64) {
+	fmt.Fprintf(l.page, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y), num(x2), num(y))
+}
+
+// paragraph writes s wrapped to width, breaking pages between lines.
+func (l *layout) paragraph(f *fontFace, size, x, width float64, s string) {
+	for _, line := range wrap(f, size, width, s) {
+		l.ensure(leading(size))
+		l.y -= leading(size)
+		l.text(f, size, x, l.y, line)
+	}
+}
+
+func leading(size float64) float64 {
+	return size * 1.35
+}
+
+// wrap breaks s into lines no wider than width. Line breaks in s are kept; words longer than a line
+// are split.
+func wrap(f *fontFace, size, width float64, s string) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		var line string
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+
+			if f.width(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			for f.width(word, size) > width {
+				cut := 1
+				for cut < len([]rune(word)) && f.width(string([]rune(word)[:cut+1]), size) <= width {
+					cut++
+				}
+				lines = append(lines, string([]rune(word)[:cut]))
+				word = string([]rune(word)[cut:])
+			}
+			line = word
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// escape quotes a PDF literal string.
+func escape(s []byte) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// num formats a coordinate with at most two decimals.
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,423 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package render lays out a parsed CII invoice as a PDF with embedded fonts.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Font sizes in points.
+const (
+	sizeTitle  = 18.0
+	sizeHeader = 12.0
+	sizeText   = 9.0
+	sizeSmall  = 7.5
+)
+
+// column is a column of the line item table.
+type column struct {
+	title string
+	x     float64 // left edge, or right edge if right is set
+	right bool
+}
+
+var lineColumns = []column{
+	{title: "Pos.", x: marginLeft},
+	{title: "Description", x: marginLeft + 30},
+	{title: "Quantity", x: 355, right: true},
+	{title: "Unit", x: 362},
+	{title: "Unit price", x: 445, right: true},
+	{title: "VAT", x: 485, right: true},
+	{title: "Amount", x: marginRight, right: true},
+}
+
+// descriptionWidth is the room of the description column up to the quantity column.
+const descriptionWidth = 230.0
+
+// Render returns a PDF showing the parties, lines, VAT breakdown, totals and payment terms of
+// invoice. The fonts are embedded, as PDF/A requires.
+func Render(invoice *cii.Invoice) ([]byte, error) {
+	regular, bold, err := loadFonts()
+	if err != nil {
+		return nil, err
+	}
+
+	l := newLayout(regular, bold)
+	writeHeader(l, invoice)
+	writeNotes(l, invoice)
+	writeLines(l, invoice)
+	writeAllowanceCharges(l, invoice)
+	writeTotals(l, invoice)
+	writeVAT(l, invoice)
+	writePayment(l, invoice)
+	writeFooters(l, invoice)
+
+	return assemble(l, invoice)
+}
+
+func writeHeader(l *layout, inv *cii.Invoice) {
+	top := l.y
+
+	l.y -= sizeHeader
+	l.text(l.bold, sizeHeader, marginLeft, l.y, inv.Seller.Name)
+	for _, line := range addressLines(inv.Seller.Address) {
+		l.y -= leading(sizeText)
+		l.text(l.regular, sizeText, marginLeft, l.y, line)
+	}
+	sellerBottom := l.y
+
+	l.textRight(l.bold, sizeTitle, marginRight, top-sizeTitle, documentTitle(inv.TypeCode))
+
+	// Recipient on the left, document data on the right.
+	l.y = min(sellerBottom, top-sizeTitle) - 30
+	top = l.y
+
+	l.y -= sizeSmall
+	l.text(l.regular, sizeSmall, marginLeft, l.y, "Bill to")
+	l.y -= leading(sizeText)
+	l.text(l.bold, sizeText, marginLeft, l.y, inv.Buyer.Name)
+	for _, line := range addressLines(inv.Buyer.Address) {
+		l.y -= leading(sizeText)
+		l.text(l.regular, sizeText, marginLeft, l.y, line)
+	}
+	if inv.Buyer.VATID != "" {
+		l.y -= leading(sizeText)
+		l.text(l.regular, sizeText, marginLeft, l.y, "VAT ID: "+inv.Buyer.VATID)
+	}
+	buyerBottom := l.y
+
+	var preceding []string
+	for _, ref := range inv.Preceding {
+		preceding = append(preceding, strings.TrimSpace(ref.ID+" "+date(ref.IssueDate)))
+	}
+
+	l.y = top
+	for _, field := range [][2]string{
+		{"Number", inv.ID},
+		{"Date", date(inv.IssueDate)},
+		{"Delivery date", date(inv.DeliveryDate)},
+		{"Due date", date(inv.DueDate)},
+		{"Customer number", inv.Buyer.ID},
+		{"Buyer reference", inv.BuyerReference},
+		{"Order", inv.OrderReference},
+		{"Preceding invoice", strings.Join(preceding, ", ")},
+		{"Currency", inv.Currency},
+	} {
+		if field[1] == "" {
+			continue
+		}
+		l.y -= leading(sizeText)
+		l.text(l.regular, sizeText, 350, l.y, field[0])
+		l.textRight(l.regular, sizeText, marginRight, l.y, field[1])
+	}
+
+	l.y = min(l.y, buyerBottom) - 25
+}
+
+func writeNotes(l *layout, inv *cii.Invoice) {
+	for _, note := range inv.Notes {
+		l.paragraph(l.regular, sizeText, marginLeft, marginRight-marginLeft, note)
+		l.space(6)
+	}
+}
+
+func writeLines(l *layout, inv *cii.Invoice) {
+	header := func() {
+		l.y -= leading(sizeText)
+		for _, c := range lineColumns {
+			if c.right {
+				l.textRight(l.bold, sizeText, c.x, l.y, c.title)
+			} else {
+				l.text(l.bold, sizeText, c.x, l.y, c.title)
+			}
+		}
+		l.y -= 4
+		l.rule(marginLeft, marginRight, l.y, 0.5)
+	}
+
+	l.ensure(4 * leading(sizeText))
+	l.space(6)
+	header()
+	l.onNewPage = header
+	defer func() { l.onNewPage = nil }()
+
+	for _, line := range inv.Lines {
+		description := wrap(l.regular, sizeText, descriptionWidth, line.Name)
+		details := line.Description
+		if line.SellerID != "" {
+			details = strings.TrimSpace("Item " + line.SellerID + "\n" + details)
+		}
+		small := wrap(l.regular, sizeSmall, descriptionWidth, details)
+
+		l.ensure(float64(len(description))*leading(sizeText) + float64(len(small))*leading(sizeSmall) + 4)
+
+		l.y -= leading(sizeText) + 2
+		values := []string{
+			line.ID,
+			"",
+			quantity(line.Quantity),
+			unit(line.UnitCode),
+			price(line.NetPrice, line.BaseQty),
+			percent(line.VATRate),
+			amount(line.Total),
+		}
+		for i, c := range lineColumns {
+			if c.right {
+				l.textRight(l.regular, sizeText, c.x, l.y, values[i])
+			} else {
+				l.text(l.regular, sizeText, c.x, l.y, values[i])
+			}
+		}
+
+		x := lineColumns[1].x
+		for i, text := range description {
+			if i > 0 {
+				l.y -= leading(sizeText)
+			}
+			l.text(l.regular, sizeText, x, l.y, text)
+		}
+		for _, text := range small {
+			l.y -= leading(sizeSmall)
+			l.text(l.regular, sizeSmall, x, l.y, text)
+		}
+	}
+
+	l.y -= 4
+	l.rule(marginLeft, marginRight, l.y, 0.5)
+}
+
+func writeAllowanceCharges(l *layout, inv *cii.Invoice) {
+	for _, ac := range inv.AllowanceCharge {
+		kind := "Allowance"
+		value := "-" + amount(ac.Amount)
+		if ac.Charge {
+			kind = "Charge"
+			value = amount(ac.Amount)
+		}
+		if ac.Reason != "" {
+			kind += ": " + ac.Reason
+		}
+
+		l.ensure(leading(sizeText))
+		l.y -= leading(sizeText)
+		l.text(l.regular, sizeText, lineColumns[1].x, l.y, kind)
+		l.textRight(l.regular, sizeText, lineColumns[5].x, l.y, percent(ac.VATRate))
+		l.textRight(l.regular, sizeText, marginRight, l.y, value)
+	}
+}
+
+func writeTotals(l *layout, inv *cii.Invoice) {
+	t := inv.Totals
+	rows := []struct {
+		label string
+		value cii.Decimal
+		bold  bool
+		skip  bool
+	}{
+		{label: "Sum of lines", value: t.LineTotal},
+		{label: "Allowances", value: t.AllowanceTotal, skip: t.AllowanceTotal.IsZero()},
+		{label: "Charges", value: t.ChargeTotal, skip: t.ChargeTotal.IsZero()},
+		{label: "Total without VAT", value: t.TaxBasisTotal},
+		{label: "VAT", value: t.TaxTotal},
+		{label: "Total", value: t.GrandTotal, bold: true},
+		{label: "Prepaid", value: t.Prepaid, skip: t.Prepaid.IsZero()},
+		{label: "Amount due", value: t.DuePayable, bold: true, skip: t.DuePayable == t.GrandTotal},
+	}
+
+	l.ensure(float64(len(rows))*leading(sizeText) + 10)
+	l.space(8)
+	for _, row := range rows {
+		if row.skip {
+			continue
+		}
+
+		f := l.regular
+		if row.bold {
+			f = l.bold
+		}
+		l.y -= leading(sizeText)
+		l.text(f, sizeText, 350, l.y, row.label)
+		l.textRight(f, sizeText, marginRight, l.y, strings.TrimSpace(amount(row.value)+" "+inv.Currency))
+	}
+}
+
+func writeVAT(l *layout, inv *cii.Invoice) {
+	if len(inv.VAT) == 0 {
+		return
+	}
+
+	l.ensure(3 * leading(sizeText))
+	l.space(14)
+	l.y -= leading(sizeText)
+	l.text(l.bold, sizeText, marginLeft, l.y, "VAT breakdown")
+	l.textRight(l.bold, sizeText, 300, l.y, "Rate")
+	l.textRight(l.bold, sizeText, 420, l.y, "Taxable amount")
+	l.textRight(l.bold, sizeText, marginRight, l.y, "VAT")
+
+	for _, vat := range inv.VAT {
+		l.ensure(leading(sizeText))
+		l.y -= leading(sizeText)
+		l.text(l.regular, sizeText, marginLeft, l.y, vatCategory(vat.Category))
+		l.textRight(l.regular, sizeText, 300, l.y, percent(vat.Rate))
+		l.textRight(l.regular, sizeText, 420, l.y, amount(vat.BasisAmount))
+		l.textRight(l.regular, sizeText, marginRight, l.y, amount(vat.TaxAmount))
+		if vat.ExemptionReason != "" {
+			l.paragraph(l.regular, sizeSmall, marginLeft+10, 400, vat.ExemptionReason)
+		}
+	}
+}
+
+func writePayment(l *layout, inv *cii.Invoice) {
+	if inv.PaymentTerms == "" && len(inv.PaymentMeans) == 0 && inv.PaymentRef == "" {
+		return
+	}
+
+	l.ensure(3 * leading(sizeText))
+	l.space(14)
+	l.y -= leading(sizeText)
+	l.text(l.bold, sizeText, marginLeft, l.y, "Payment")
+
+	width := marginRight - marginLeft
+	if inv.PaymentTerms != "" {
+		l.paragraph(l.regular, sizeText, marginLeft, width, inv.PaymentTerms)
+	}
+
+	for _, means := range inv.PaymentMeans {
+		parts := []string{paymentMeans(means.TypeCode)}
+		for _, field := range [][2]string{
+			{"", means.Information},
+			{"Account holder: ", means.AccountName},
+			{"IBAN: ", means.IBAN},
+			{"BIC: ", means.BIC},
+		} {
+			if field[1] != "" {
+				parts = append(parts, field[0]+field[1])
+			}
+		}
+		l.paragraph(l.regular, sizeText, marginLeft, width, strings.Join(parts, ", "))
+	}
+
+	if inv.PaymentRef != "" {
+		l.paragraph(l.regular, sizeText, marginLeft, width, "Reference: "+inv.PaymentRef)
+	}
+}
+
+// writeFooters adds the seller identification and page numbers once the page count is known.
+func writeFooters(l *layout, inv *cii.Invoice) {
+	var ids []string
+	if inv.Seller.VATID != "" {
+		ids = append(ids, "VAT ID "+inv.Seller.VATID)
+	}
+	if inv.Seller.TaxID != "" {
+		ids = append(ids, "Tax number "+inv.Seller.TaxID)
+	}
+	footer := strings.Join(append([]string{inv.Seller.Name}, ids...), " · ")
+
+	for i, page := range l.pages {
+		l.page = page
+		l.rule(marginLeft, marginRight, footerY+12, 0.25)
+		l.text(l.regular, sizeSmall, marginLeft, footerY, footer)
+		l.textRight(l.regular, sizeSmall, marginRight, footerY, fmt.Sprintf("Page %d of %d", i+1, len(l.pages)))
+	}
+}
+
+// assemble creates a document from the page contents of l.
+func assemble(l *layout, inv *cii.Invoice) ([]byte, error) {
+	ctx, err := pdfcpu.CreateContextWithXRefTable(nil, &types.Dim{Width: pageWidth, Height: pageHeight})
+	if err != nil {
+		return nil, fmt.Errorf("could not create PDF: %w", err)
+	}
+	xRefTable := ctx.XRefTable
+
+	fonts := types.Dict{}
+	for _, f := range []*fontFace{l.regular, l.bold} {
+		ref, err := f.embed(xRefTable)
+		if err != nil {
+			return nil, err
+		}
+		fonts[f.resource] = *ref
+	}
+
+	catalog, err := xRefTable.Catalog()
+	if err != nil {
+		return nil, fmt.Errorf("could not get catalog: %w", err)
+	}
+
+	pagesRef, ok := catalog["Pages"].(types.IndirectRef)
+	if !ok {
+		return nil, fmt.Errorf("could not get page tree")
+	}
+
+	pages, err := xRefTable.DereferenceDict(pagesRef)
+	if err != nil {
+		return nil, fmt.Errorf("could not get page tree: %w", err)
+	}
+
+	var kids types.Array
+	for _, content := range l.pages {
+		sd, err := xRefTable.NewStreamDictForBuf(content.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		if err = sd.Encode(); err != nil {
+			return nil, fmt.Errorf("could not encode page content: %w", err)
+		}
+
+		contentRef, err := xRefTable.IndRefForNewObject(*sd)
+		if err != nil {
+			return nil, err
+		}
+
+		pageRef, err := xRefTable.IndRefForNewObject(types.Dict{
+			"Type":      types.Name("Page"),
+			"Parent":    pagesRef,
+			"Resources": types.Dict{"Font": fonts},
+			"Contents":  *contentRef,
+		})
+		if err != nil {
+			return nil, err
+		}
+		kids = append(kids, *pageRef)
+	}
+
+	pages.Update("Kids", kids)
+	pages.Update("Count", types.Integer(len(kids)))
+	xRefTable.PageCount = len(kids)
+	xRefTable.Title = strings.TrimSpace(documentTitle(inv.TypeCode) + " " + inv.ID)
+	xRefTable.Author = inv.Seller.Name
+
+	var out bytes.Buffer
+	if err = api.Write(ctx, &out, nil); err != nil {
+		return nil, fmt.Errorf("could not write PDF: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func addressLines(a cii.Address) []string {
+	lines := append([]string{}, a.Lines...)
+	if city := strings.TrimSpace(a.PostCode + " " + a.City); city != "" {
+		lines = append(lines, city)
+	}
+	if a.Country != "" {
+		lines = append(lines, a.Country)
+	}
+	return lines
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
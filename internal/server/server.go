@@ -0,0 +1,545 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+// Package server exposes the attach, extract and validate functions of gopdfattach over HTTP.
+package server
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach"
+)
+
+//go:embed openapi.yaml
+var openAPI []byte
+
+// Options configures the HTTP handler.
+type Options struct {
+	MaxRequestSize int64         // maximum request body size in bytes, defaults to 32 MiB
+	Timeout        time.Duration // maximum processing time per request, defaults to 60 seconds
+	// Limits bounds the resources spent on each PDF and XML, nil uses gopdfattach.DefaultLimits.
+	Limits *gopdfattach.Limits
+}
+
+func (o *Options) setDefaults() {
+	if o.MaxRequestSize <= 0 {
+		o.MaxRequestSize = 32 << 20
+	}
+
+	if o.Timeout <= 0 {
+		o.Timeout = 60 * time.Second
+	}
+
+	if o.Limits == nil {
+		limits := gopdfattach.DefaultLimits
+		o.Limits = &limits
+	}
+}
+
+// attachConfig is the JSON and form representation of gopdfattach.AttachConfig.
+type attachConfig struct {
+	DocumentType     string `json:"document_type,omitempty"`
+	FileName         string `json:"file_name,omitempty"`
+	Version          string `json:"version,omitempty"`
+	ConformanceLevel string `json:"conformance_level,omitempty"`
+	Creator          string `json:"creator,omitempty"`
+	AFRelationship   string `json:"af_relationship,omitempty"`
+	Incremental      bool   `json:"incremental,omitempty"`
+	UserPassword     string `json:"user_password,omitempty"`
+	OwnerPassword    string `json:"owner_password,omitempty"`
+}
+
+func (a *attachConfig) toConfig() *gopdfattach.AttachConfig {
+	return &gopdfattach.AttachConfig{
+		DocumentType:     a.DocumentType,
+		FileName:         a.FileName,
+		Version:          a.Version,
+		ConformanceLevel: a.ConformanceLevel,
+		Creator:          a.Creator,
+		AFRelationship:   gopdfattach.AF(a.AFRelationship),
+		Incremental:      a.Incremental,
+		UserPassword:     a.UserPassword,
+		OwnerPassword:    a.OwnerPassword,
+	}
+}
+
+// attachRequest is the raw JSON body of an attach request. PDF and XML are base64 encoded.
+type attachRequest struct {
+	PDF    []byte       `json:"pdf"`
+	XML    []byte       `json:"xml"`
+	Config attachConfig `json:"config"`
+}
+
+type validateRequest struct {
+	Config attachConfig `json:"config"`
+}
+
+type signatureInfo struct {
+	FieldName           string     `json:"field_name"`
+	SubFilter           string     `json:"sub_filter,omitempty"`
+	Name                string     `json:"name,omitempty"`
+	Reason              string     `json:"reason,omitempty"`
+	Location            string     `json:"location,omitempty"`
+	SigningTime         *time.Time `json:"signing_time,omitempty"`
+	Signer              string     `json:"signer,omitempty"`
+	Timestamped         bool       `json:"timestamped"`
+	Valid               bool       `json:"valid"`
+	Error               string     `json:"error,omitempty"`
+	CoversWholeDocument bool       `json:"covers_whole_document"`
+	CoversAttachment    bool       `json:"covers_attachment"`
+}
+
+type xmlInfo struct {
+	FileType         string          `json:"file_type"`
+	DocumentType     string          `json:"document_type"`
+	FileName         string          `json:"file_name"`
+	Version          string          `json:"version"`
+	ConformanceLevel string          `json:"conformance_level"`
+	AttachmentSigned bool            `json:"attachment_signed"`
+	Signatures       []signatureInfo `json:"signatures,omitempty"`
+	Warnings         []string        `json:"warnings,omitempty"`
+}
+
+type extractResponse struct {
+	XML  string  `json:"xml"`
+	Info xmlInfo `json:"info"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// httpError carries the status code an error is reported with.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func (e *httpError) Unwrap() error {
+	return e.err
+}
+
+func badRequest(format string, args ...any) error {
+	return &httpError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+// New returns the HTTP handler of the server.
+//
+//	POST /v1/attach/{type}    attach XML to a PDF, type is "facturx" or "zugferd"
+//	POST /v1/extract          extract the XML of a hybrid PDF
+//	POST /v1/validate/{type}  validate an attach config
+//	GET  /healthz             liveness check
+//	GET  /readyz              readiness check
+//	GET  /openapi.yaml        OpenAPI description
+func New(options *Options) http.Handler {
+	var o Options
+	if options != nil {
+		o = *options
+	}
+	o.setDefaults()
+
+	s := &server{options: o}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /v1/attach/{type}", s.limit(s.attach))
+	mux.Handle("POST /v1/extract", s.limit(s.extract))
+	mux.Handle("POST /v1/validate/{type}", s.limit(s.validate))
+	mux.HandleFunc("GET /healthz", health)
+	mux.HandleFunc("GET /readyz", health)
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(openAPI)
+	})
+
+	return mux
+}
+
+type server struct {
+	options Options
+}
+
+// limit applies the request size limit and processing timeout to h.
+func (s *server) limit(h func(http.ResponseWriter, *http.Request) error) http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, s.options.MaxRequestSize)
+		// TimeoutHandler passes a copy of the request, so the server never sees its MultipartForm and
+		// cannot remove the temporary files of large parts itself.
+		defer func() {
+			if r.MultipartForm != nil {
+				_ = r.MultipartForm.RemoveAll()
+			}
+		}()
+		if err := h(w, r); err != nil {
+			writeError(w, err)
+		}
+	})
+
+	body, _ := json.Marshal(errorResponse{Error: "request timed out"})
+	return http.TimeoutHandler(handler, s.options.Timeout, string(body))
+}
+
+func health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) attach(w http.ResponseWriter, r *http.Request) error {
+	fileType, err := pathFileType(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		pdf    io.ReadSeeker
+		xml    io.Reader
+		config attachConfig
+	)
+
+	switch mediaType(r) {
+	case "multipart/form-data":
+		form, err := s.parseMultipart(r)
+		if err != nil {
+			return err
+		}
+
+		pdfFile, err := formFile(form, "pdf")
+		if err != nil {
+			return err
+		}
+		defer pdfFile.Close()
+
+		xmlFile, err := formFile(form, "xml")
+		if err != nil {
+			return err
+		}
+		defer xmlFile.Close()
+
+		if err := formConfig(form, &config); err != nil {
+			return err
+		}
+
+		pdf, xml = pdfFile, xmlFile
+
+	case "application/json":
+		var req attachRequest
+		if err := decodeJSON(r, &req); err != nil {
+			return err
+		}
+
+		if len(req.PDF) == 0 || len(req.XML) == 0 {
+			return badRequest("pdf and xml are required")
+		}
+
+		pdf, xml, config = bytes.NewReader(req.PDF), bytes.NewReader(req.XML), req.Config
+
+	default:
+		return &httpError{status: http.StatusUnsupportedMediaType, err: errors.New("expected multipart/form-data or application/json")}
+	}
+
+	attachConfig := config.toConfig()
+	attachConfig.Limits = *s.options.Limits
+
+	var out []byte
+	switch fileType {
+	case gopdfattach.FileTypeFacturX:
+		out, err = gopdfattach.AttachFacturXContext(r.Context(), xml, pdf, attachConfig)
+	case gopdfattach.FileTypeZugferd:
+		out, err = gopdfattach.AttachZUGFeRDContext(r.Context(), xml, pdf, attachConfig)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", strconv.Itoa(len(out)))
+	_, _ = w.Write(out)
+	return nil
+}
+
+func (s *server) extract(w http.ResponseWriter, r *http.Request) error {
+	var (
+		pdf    io.ReadSeeker
+		config = gopdfattach.ExtractConfig{Limits: *s.options.Limits}
+	)
+
+	switch mediaType(r) {
+	case "multipart/form-data":
+		form, err := s.parseMultipart(r)
+		if err != nil {
+			return err
+		}
+
+		pdfFile, err := formFile(form, "pdf")
+		if err != nil {
+			return err
+		}
+		defer pdfFile.Close()
+
+		pdf = pdfFile
+		config.UserPassword = formValue(form, "user_password")
+		config.OwnerPassword = formValue(form, "owner_password")
+
+	default:
+		// Any other content type is treated as the raw PDF.
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return readError(err)
+		}
+
+		pdf = bytes.NewReader(data)
+		config.UserPassword = r.Header.Get("X-User-Password")
+		config.OwnerPassword = r.Header.Get("X-Owner-Password")
+	}
+
+	xml, infos, err := gopdfattach.ExtractContext(r.Context(), pdf, &config)
+	if err != nil {
+		return err
+	}
+
+	info := toXMLInfo(infos)
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, extractResponse{XML: string(xml), Info: info})
+		return nil
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "application/xml")
+	h.Set("X-File-Type", info.FileType)
+	h.Set("X-Document-Type", info.DocumentType)
+	h.Set("X-File-Name", info.FileName)
+	h.Set("X-Version", info.Version)
+	h.Set("X-Conformance-Level", info.ConformanceLevel)
+	h.Set("X-Signatures", strconv.Itoa(len(info.Signatures)))
+	h.Set("X-Attachment-Signed", strconv.FormatBool(info.AttachmentSigned))
+	h.Set("X-Warnings", strconv.Itoa(len(info.Warnings)))
+	_, _ = w.Write(xml)
+	return nil
+}
+
+func (s *server) validate(w http.ResponseWriter, r *http.Request) error {
+	fileType, err := pathFileType(r)
+	if err != nil {
+		return err
+	}
+
+	var config attachConfig
+	switch mediaType(r) {
+	case "multipart/form-data":
+		form, err := s.parseMultipart(r)
+		if err != nil {
+			return err
+		}
+
+		if err := formConfig(form, &config); err != nil {
+			return err
+		}
+
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return readError(err)
+		}
+
+		if err := formConfig(&multipart.Form{Value: r.PostForm}, &config); err != nil {
+			return err
+		}
+
+	case "application/json":
+		var req validateRequest
+		if err := decodeJSON(r, &req); err != nil {
+			return err
+		}
+		config = req.Config
+
+	default:
+		return &httpError{status: http.StatusUnsupportedMediaType, err: errors.New("expected multipart/form-data, application/x-www-form-urlencoded or application/json")}
+	}
+
+	if err := config.toConfig().Validate(fileType); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"valid": false, "error": err.Error()})
+		return nil
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"valid": true})
+	return nil
+}
+
+func (s *server) parseMultipart(r *http.Request) (*multipart.Form, error) {
+	// Parts beyond 8 MiB are stored in temporary files; the total is bounded by MaxBytesReader.
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		return nil, readError(err)
+	}
+	return r.MultipartForm, nil
+}
+
+func pathFileType(r *http.Request) (string, error) {
+	switch r.PathValue("type") {
+	case "facturx", "factur-x":
+		return gopdfattach.FileTypeFacturX, nil
+	case "zugferd":
+		return gopdfattach.FileTypeZugferd, nil
+	default:
+		return "", &httpError{status: http.StatusNotFound, err: fmt.Errorf("unknown file type %q", r.PathValue("type"))}
+	}
+}
+
+func mediaType(r *http.Request) string {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mt
+}
+
+func wantsJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func formFile(form *multipart.Form, name string) (multipart.File, error) {
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil, badRequest("missing form file %q", name)
+	}
+
+	f, err := files[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("could not open form file %q: %w", name, err)
+	}
+	return f, nil
+}
+
+func formValue(form *multipart.Form, name string) string {
+	if values := form.Value[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// formConfig reads the attach config either from a "config" field holding JSON or from one field per option.
+func formConfig(form *multipart.Form, config *attachConfig) error {
+	if raw := formValue(form, "config"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), config); err != nil {
+			return badRequest("could not parse config: %v", err)
+		}
+		return nil
+	}
+
+	config.DocumentType = formValue(form, "document_type")
+	config.FileName = formValue(form, "file_name")
+	config.Version = formValue(form, "version")
+	config.ConformanceLevel = formValue(form, "conformance_level")
+	config.Creator = formValue(form, "creator")
+	config.AFRelationship = formValue(form, "af_relationship")
+	config.UserPassword = formValue(form, "user_password")
+	config.OwnerPassword = formValue(form, "owner_password")
+
+	if v := formValue(form, "incremental"); v != "" {
+		incremental, err := strconv.ParseBool(v)
+		if err != nil {
+			return badRequest("could not parse incremental: %v", err)
+		}
+		config.Incremental = incremental
+	}
+
+	return nil
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return readError(err)
+	}
+	return nil
+}
+
+// readError maps errors of reading the request body, reporting an exceeded size limit as 413.
+func readError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &httpError{status: http.StatusRequestEntityTooLarge, err: fmt.Errorf("request exceeds %d bytes", maxBytesErr.Limit)}
+	}
+	// ParseMultipartForm hides the MaxBytesError behind its own message.
+	if strings.Contains(err.Error(), "request body too large") {
+		return &httpError{status: http.StatusRequestEntityTooLarge, err: err}
+	}
+	return &httpError{status: http.StatusBadRequest, err: fmt.Errorf("could not read request: %w", err)}
+}
+
+func toXMLInfo(infos *gopdfattach.XMLInfo) xmlInfo {
+	info := xmlInfo{
+		FileType:         infos.FileType,
+		DocumentType:     infos.DocumentType,
+		FileName:         infos.FileName,
+		Version:          infos.Version,
+		ConformanceLevel: infos.ConformanceLevel,
+		AttachmentSigned: infos.AttachmentSigned(),
+		Warnings:         infos.Warnings,
+	}
+
+	for _, sig := range infos.Signatures {
+		s := signatureInfo{
+			FieldName:           sig.FieldName,
+			SubFilter:           sig.SubFilter,
+			Name:                sig.Name,
+			Reason:              sig.Reason,
+			Location:            sig.Location,
+			Timestamped:         sig.Timestamped,
+			Valid:               sig.Valid,
+			Error:               sig.Error,
+			CoversWholeDocument: sig.CoversWholeDocument,
+			CoversAttachment:    sig.CoversAttachment,
+		}
+		if !sig.SigningTime.IsZero() {
+			s.SigningTime = &sig.SigningTime
+		}
+		if sig.Certificate != nil {
+			s.Signer = sig.Certificate.Subject.String()
+		}
+		info.Signatures = append(info.Signatures, s)
+	}
+
+	return info
+}
+
+// statusCode maps errors of the library to HTTP status codes.
+func statusCode(err error) int {
+	var httpErr *httpError
+	var panicErr *gopdfattach.PanicError
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.status
+	case errors.As(err, &panicErr):
+		return http.StatusInternalServerError
+	case errors.Is(err, gopdfattach.ErrInvalidConfig):
+		return http.StatusBadRequest
+	case errors.Is(err, gopdfattach.ErrLimitExceeded):
+		return http.StatusRequestEntityTooLarge
+	default:
+		// Everything else, including an *gopdfattach.EncryptedError, is caused by a PDF or XML
+		// the library could not process.
+		return http.StatusUnprocessableEntity
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusCode(err), errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
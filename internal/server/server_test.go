@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func multipartBody(t *testing.T, files map[string]string, fields map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, path := range files {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		part, err := w.CreateFormFile(name, path)
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+	}
+	for name, value := range fields {
+		require.NoError(t, w.WriteField(name, value))
+	}
+	require.NoError(t, w.Close())
+	return &body, w.FormDataContentType()
+}
+
+func serve(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAttach_Multipart(t *testing.T) {
+	handler := New(nil)
+
+	body, contentType := multipartBody(t,
+		map[string]string{"pdf": "../../testdata/invoice.pdf", "xml": "../../testdata/factur-x.xml"},
+		map[string]string{"conformance_level": "EN 16931", "file_name": "factur-x.xml"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/attach/facturx", body)
+	req.Header.Set("Content-Type", contentType)
+
+	rec := serve(handler, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+
+	// Extract the result again as raw body.
+	req = httptest.NewRequest(http.MethodPost, "/v1/extract", bytes.NewReader(rec.Body.Bytes()))
+	req.Header.Set("Content-Type", "application/pdf")
+
+	rec = serve(handler, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "Factur-X", rec.Header().Get("X-File-Type"))
+	assert.Equal(t, "factur-x.xml", rec.Header().Get("X-File-Name"))
+	assert.Equal(t, "EN 16931", rec.Header().Get("X-Conformance-Level"))
+
+	expected, _ := os.ReadFile("../../testdata/factur-x.xml")
+	assert.Equal(t, expected, rec.Body.Bytes())
+}
+
+// TestAttach_MultipartTempFiles checks that parts stored in temporary files are removed after the request.
+func TestAttach_MultipartTempFiles(t *testing.T) {
+	padding := filepath.Join(t.TempDir(), "padding.bin")
+	require.NoError(t, os.WriteFile(padding, make([]byte, 9<<20), 0o600))
+
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	body, contentType := multipartBody(t,
+		map[string]string{"pdf": "../../testdata/invoice.pdf", "xml": "../../testdata/factur-x.xml", "padding": padding}, nil)
+	req := httptest.NewRequest(http.MethodPost, "/v1/attach/facturx", body)
+	req.Header.Set("Content-Type", contentType)
+
+	rec := serve(New(nil), req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAttach_JSON(t *testing.T) {
+	pdf, err := os.ReadFile("../../testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("../../testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	body, err := json.Marshal(attachRequest{PDF: pdf, XML: xml, Config: attachConfig{FileName: "zugferd-invoice.xml"}})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/attach/zugferd", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := serve(New(nil), req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/extract?format=json", bytes.NewReader(rec.Body.Bytes()))
+	rec = serve(New(nil), req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp extractResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "ZUGFeRD", resp.Info.FileType)
+	assert.Equal(t, "zugferd-invoice.xml", resp.Info.FileName)
+	assert.Equal(t, string(xml), resp.XML)
+}
+
+func TestAttach_InvalidConfig(t *testing.T) {
+	body, contentType := multipartBody(t,
+		map[string]string{"pdf": "../../testdata/invoice.pdf", "xml": "../../testdata/factur-x.xml"},
+		map[string]string{"config": `{"conformance_level": "EN16931"}`})
+	req := httptest.NewRequest(http.MethodPost, "/v1/attach/facturx", body)
+	req.Header.Set("Content-Type", contentType)
+
+	rec := serve(New(nil), req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "EN16931")
+}
+
+func TestAttach_MissingFile(t *testing.T) {
+	body, contentType := multipartBody(t, map[string]string{"pdf": "../../testdata/invoice.pdf"}, nil)
+	req := httptest.NewRequest(http.MethodPost, "/v1/attach/facturx", body)
+	req.Header.Set("Content-Type", contentType)
+
+	rec := serve(New(nil), req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAttach_UnknownType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/attach/ubl", bytes.NewReader(nil))
+	rec := serve(New(nil), req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestExtract_TooLarge(t *testing.T) {
+	pdf, err := os.ReadFile("../../testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/extract", bytes.NewReader(pdf))
+	rec := serve(New(&Options{MaxRequestSize: 1024}), req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestExtract_Limits(t *testing.T) {
+	pdf, err := os.ReadFile("../../testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/extract", bytes.NewReader(pdf))
+	rec := serve(New(&Options{Limits: &gopdfattach.Limits{MaxObjects: 5}}), req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/extract", bytes.NewReader(pdf))
+	rec = serve(New(&Options{Limits: &gopdfattach.Limits{}}), req)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestExtract_NotAPDF(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/extract", bytes.NewReader([]byte("not a pdf")))
+	rec := serve(New(nil), req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestValidate(t *testing.T) {
+	handler := New(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/validate/facturx",
+		bytes.NewReader([]byte(`{"config": {"conformance_level": "XRECHNUNG", "version": "3.0.2"}}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(handler, req)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/validate/zugferd",
+		bytes.NewReader([]byte("conformance_level=XRECHNUNG")))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = serve(handler, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"valid":false`)
+}
+
+func TestHealthAndOpenAPI(t *testing.T) {
+	handler := New(nil)
+
+	rec := serve(handler, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(handler, httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/v1/attach/{type}")
+}
@@ -0,0 +1,507 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var (
+	oidData               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidAttrContentType    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttrMessageDigest  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttrSigningCertV2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidAttrTimeStampToken = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+	oidSHA1               = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidRSAEncryption      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA1WithRSA        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 5}
+	oidSHA256WithRSA      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidECDSAWithSHA256    = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384    = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512    = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	oidECPublicKey        = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+)
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                issuerAndSerialNumber
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// SignedData is a parsed CMS SignedData structure with a single signer.
+type SignedData struct {
+	Content      []byte
+	ContentType  asn1.ObjectIdentifier
+	Certificates []*x509.Certificate
+	Signer       *x509.Certificate
+	Hash         crypto.Hash
+
+	signerInfo signerInfo
+	attrs      []attribute
+}
+
+// CreateSignedData signs content with signer and returns a DER encoded CMS ContentInfo.
+// If detached is true, content is not embedded and only its digest is signed.
+// certs must start with the signer certificate. If timestamp is not nil it is called with the
+// signature value and the returned RFC 3161 token is added as signature-time-stamp attribute.
+func CreateSignedData(content []byte, detached bool, contentType asn1.ObjectIdentifier, signer crypto.Signer,
+	certs []*x509.Certificate, hash crypto.Hash, timestamp func(signature []byte) ([]byte, error)) ([]byte, error) {
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("missing signer certificate")
+	}
+
+	digestAlgorithm, err := digestAlgorithmFor(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureAlgorithm, err := signatureAlgorithmFor(signer.Public(), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hash.New()
+	h.Write(content)
+	digest := h.Sum(nil)
+
+	certHash := crypto.SHA256.New()
+	certHash.Write(certs[0].Raw)
+
+	attrs := []attribute{
+		mustAttribute(oidAttrContentType, contentType),
+		mustAttribute(oidAttrMessageDigest, digest),
+		mustAttribute(oidAttrSigningCertV2, signingCertificateV2{Certs: []essCertIDv2{{CertHash: certHash.Sum(nil)}}}),
+	}
+
+	signedAttrs, err := marshalAttributes(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature is computed over the DER encoding of the attributes as a SET OF.
+	attrsHash := hash.New()
+	attrsHash.Write(signedAttrs)
+	signature, err := signer.Sign(rand.Reader, attrsHash.Sum(nil), hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign: %w", err)
+	}
+
+	info := signerInfo{
+		Version: 1,
+		SID: issuerAndSerialNumber{
+			Issuer:       asn1.RawValue{FullBytes: certs[0].RawIssuer},
+			SerialNumber: certs[0].SerialNumber,
+		},
+		DigestAlgorithm:    digestAlgorithm,
+		SignedAttrs:        implicit(0, signedAttrs),
+		SignatureAlgorithm: signatureAlgorithm,
+		Signature:          signature,
+	}
+
+	if timestamp != nil {
+		token, err := timestamp(signature)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := marshalAttributes([]attribute{rawAttribute(oidAttrTimeStampToken, token)})
+		if err != nil {
+			return nil, err
+		}
+		info.UnsignedAttrs = implicit(1, raw)
+	}
+
+	var rawCerts []byte
+	for _, cert := range certs {
+		rawCerts = append(rawCerts, cert.Raw...)
+	}
+
+	sd := signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{digestAlgorithm},
+		EncapContentInfo: encapsulatedContentInfo{EContentType: contentType},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: rawCerts},
+		SignerInfos:      []signerInfo{info},
+	}
+	if !detached {
+		sd.EncapContentInfo.EContent = content
+	}
+	if !contentType.Equal(oidData) {
+		sd.Version = 3
+	}
+
+	inner, err := asn1.Marshal(sd)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal signed data: %w", err)
+	}
+
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: inner},
+	})
+}
+
+// ParseSignedData parses a DER encoded CMS ContentInfo holding SignedData with at least one signer.
+// Trailing zero padding, as found in PDF /Contents entries, is ignored.
+func ParseSignedData(der []byte) (*SignedData, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, fmt.Errorf("could not parse content info: %w", err)
+	}
+
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("unexpected content type %s", ci.ContentType)
+	}
+
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("could not parse signed data: %w", err)
+	}
+
+	if len(sd.SignerInfos) == 0 {
+		return nil, fmt.Errorf("signed data has no signer")
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse certificates: %w", err)
+	}
+
+	info := sd.SignerInfos[0]
+	hash, err := hashFor(info.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &SignedData{
+		Content:      sd.EncapContentInfo.EContent,
+		ContentType:  sd.EncapContentInfo.EContentType,
+		Certificates: certs,
+		Hash:         hash,
+		signerInfo:   info,
+	}
+
+	if len(info.SignedAttrs.Bytes) > 0 {
+		// Re-tag the implicit [0] as SET OF to parse the attributes.
+		if _, err := asn1.UnmarshalWithParams(info.SignedAttrs.FullBytes, &out.attrs, "set,tag:0"); err != nil {
+			return nil, fmt.Errorf("could not parse signed attributes: %w", err)
+		}
+	}
+
+	for _, cert := range certs {
+		if cert.SerialNumber.Cmp(info.SID.SerialNumber) == 0 && bytes.Equal(cert.RawIssuer, info.SID.Issuer.FullBytes) {
+			out.Signer = cert
+			break
+		}
+	}
+
+	if out.Signer == nil {
+		return nil, fmt.Errorf("signer certificate not included")
+	}
+
+	return out, nil
+}
+
+// MessageDigest returns the digest of the signed content as recorded in the signed attributes.
+func (s *SignedData) MessageDigest() ([]byte, error) {
+	var digest []byte
+	if err := s.attribute(oidAttrMessageDigest, &digest); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// TimeStampToken returns the DER encoded RFC 3161 token in the unsigned attributes, or nil if absent.
+func (s *SignedData) TimeStampToken() []byte {
+	if len(s.signerInfo.UnsignedAttrs.Bytes) == 0 {
+		return nil
+	}
+
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(s.signerInfo.UnsignedAttrs.FullBytes, &attrs, "set,tag:1"); err != nil {
+		return nil
+	}
+
+	for _, attr := range attrs {
+		if attr.Type.Equal(oidAttrTimeStampToken) {
+			var token asn1.RawValue
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &token); err != nil {
+				return nil
+			}
+			return token.FullBytes
+		}
+	}
+
+	return nil
+}
+
+// Signature returns the raw signature value of the signer.
+func (s *SignedData) Signature() []byte {
+	return s.signerInfo.Signature
+}
+
+// Verify checks that the signer's signature covers content (or the embedded content if content is nil).
+// The certificate chain is not validated against trust anchors.
+func (s *SignedData) Verify(content []byte) error {
+	if content == nil {
+		content = s.Content
+	}
+
+	h := s.Hash.New()
+	h.Write(content)
+	digest := h.Sum(nil)
+
+	if len(s.signerInfo.SignedAttrs.Bytes) == 0 {
+		return s.checkSignature(content)
+	}
+
+	expected, err := s.MessageDigest()
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(expected, digest) {
+		return fmt.Errorf("message digest mismatch")
+	}
+
+	// The signature covers the attributes encoded as SET OF, not with the implicit [0] tag.
+	signedAttrs := append([]byte{0x31}, s.signerInfo.SignedAttrs.FullBytes[1:]...)
+	return s.checkSignature(signedAttrs)
+}
+
+func (s *SignedData) checkSignature(signed []byte) error {
+	algorithm, err := x509SignatureAlgorithm(s.signerInfo.SignatureAlgorithm.Algorithm, s.signerInfo.DigestAlgorithm.Algorithm, s.Signer)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Signer.CheckSignature(algorithm, signed, s.signerInfo.Signature); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SignedData) attribute(oid asn1.ObjectIdentifier, out any) error {
+	for _, attr := range s.attrs {
+		if attr.Type.Equal(oid) {
+			var value asn1.RawValue
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &value); err != nil {
+				return err
+			}
+			_, err := asn1.Unmarshal(value.FullBytes, out)
+			return err
+		}
+	}
+	return fmt.Errorf("missing attribute %s", oid)
+}
+
+func mustAttribute(oid asn1.ObjectIdentifier, value any) attribute {
+	raw, err := asn1.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return rawAttribute(oid, raw)
+}
+
+func rawAttribute(oid asn1.ObjectIdentifier, value []byte) attribute {
+	return attribute{
+		Type:   oid,
+		Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: value},
+	}
+}
+
+// marshalAttributes encodes attrs as a DER SET OF, which requires the elements to be sorted.
+func marshalAttributes(attrs []attribute) ([]byte, error) {
+	encoded := make([][]byte, len(attrs))
+	for i, attr := range attrs {
+		raw, err := asn1.Marshal(attr)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal attribute: %w", err)
+		}
+		encoded[i] = raw
+	}
+
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+
+	return asn1.Marshal(asn1.RawValue{
+		Class:      asn1.ClassUniversal,
+		Tag:        asn1.TagSet,
+		IsCompound: true,
+		Bytes:      bytes.Join(encoded, nil),
+	})
+}
+
+// implicit re-tags a DER encoded SET as the context specific tag.
+func implicit(tag int, der []byte) asn1.RawValue {
+	var set asn1.RawValue
+	_, _ = asn1.Unmarshal(der, &set)
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: tag, IsCompound: true, Bytes: set.Bytes}
+}
+
+func digestAlgorithmFor(hash crypto.Hash) (pkix.AlgorithmIdentifier, error) {
+	switch hash {
+	case crypto.SHA256:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSHA256}, nil
+	case crypto.SHA384:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSHA384}, nil
+	case crypto.SHA512:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSHA512}, nil
+	default:
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("unsupported hash %s", hash)
+	}
+}
+
+func hashFor(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, nil
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported digest algorithm %s", oid)
+	}
+}
+
+func signatureAlgorithmFor(pub crypto.PublicKey, hash crypto.Hash) (pkix.AlgorithmIdentifier, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		switch hash {
+		case crypto.SHA256:
+			return pkix.AlgorithmIdentifier{Algorithm: oidSHA256WithRSA, Parameters: asn1.NullRawValue}, nil
+		case crypto.SHA384:
+			return pkix.AlgorithmIdentifier{Algorithm: oidSHA384WithRSA, Parameters: asn1.NullRawValue}, nil
+		case crypto.SHA512:
+			return pkix.AlgorithmIdentifier{Algorithm: oidSHA512WithRSA, Parameters: asn1.NullRawValue}, nil
+		}
+	case *ecdsa.PublicKey:
+		switch hash {
+		case crypto.SHA256:
+			return pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256}, nil
+		case crypto.SHA384:
+			return pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA384}, nil
+		case crypto.SHA512:
+			return pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA512}, nil
+		}
+	default:
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("unsupported key type %T", pub)
+	}
+	return pkix.AlgorithmIdentifier{}, fmt.Errorf("unsupported hash %s", hash)
+}
+
+// x509SignatureAlgorithm maps the CMS signature and digest algorithms to the x509 equivalent.
+// CMS allows the bare key algorithm (e.g. rsaEncryption) with the hash taken from the digest algorithm.
+func x509SignatureAlgorithm(sigOID, digestOID asn1.ObjectIdentifier, cert *x509.Certificate) (x509.SignatureAlgorithm, error) {
+	switch {
+	case sigOID.Equal(oidSHA1WithRSA):
+		return x509.SHA1WithRSA, nil
+	case sigOID.Equal(oidSHA256WithRSA):
+		return x509.SHA256WithRSA, nil
+	case sigOID.Equal(oidSHA384WithRSA):
+		return x509.SHA384WithRSA, nil
+	case sigOID.Equal(oidSHA512WithRSA):
+		return x509.SHA512WithRSA, nil
+	case sigOID.Equal(oidECDSAWithSHA256):
+		return x509.ECDSAWithSHA256, nil
+	case sigOID.Equal(oidECDSAWithSHA384):
+		return x509.ECDSAWithSHA384, nil
+	case sigOID.Equal(oidECDSAWithSHA512):
+		return x509.ECDSAWithSHA512, nil
+	case sigOID.Equal(oidRSAEncryption), sigOID.Equal(oidECPublicKey):
+		hash, err := hashFor(digestOID)
+		if err != nil {
+			return 0, err
+		}
+
+		rsaKey := cert.PublicKeyAlgorithm == x509.RSA
+		switch hash {
+		case crypto.SHA1:
+			if rsaKey {
+				return x509.SHA1WithRSA, nil
+			}
+			return x509.ECDSAWithSHA1, nil
+		case crypto.SHA256:
+			if rsaKey {
+				return x509.SHA256WithRSA, nil
+			}
+			return x509.ECDSAWithSHA256, nil
+		case crypto.SHA384:
+			if rsaKey {
+				return x509.SHA384WithRSA, nil
+			}
+			return x509.ECDSAWithSHA384, nil
+		case crypto.SHA512:
+			if rsaKey {
+				return x509.SHA512WithRSA, nil
+			}
+			return x509.ECDSAWithSHA512, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported signature algorithm %s", sigOID)
+}
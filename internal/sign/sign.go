@@ -0,0 +1,364 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package sign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/incremental"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const (
+	// byteRangePlaceholder reserves room for four offsets of up to ten digits.
+	byteRangePlaceholder = "[0 0000000000 0000000000 0000000000]"
+
+	// defaultContentsSize is the space reserved for the CMS structure without certificates.
+	defaultContentsSize = 4096
+
+	// timestampContentsSize is the additional space reserved for a time-stamp token.
+	timestampContentsSize = 8192
+)
+
+type Config struct {
+	Signer       crypto.Signer
+	Certificates []*x509.Certificate // signer certificate first, followed by the chain
+	Hash         crypto.Hash         // defaults to SHA-256
+	Name         string
+	Reason       string
+	Location     string
+	ContactInfo  string
+	SigningTime  time.Time // defaults to the current time
+	FieldName    string    // defaults to "Signature1"
+	Timestamp    *TimestampClient
+	Context      context.Context
+}
+
+func (c *Config) setDefaults() {
+	if c.Hash == 0 {
+		c.Hash = crypto.SHA256
+	}
+
+	if c.SigningTime.IsZero() {
+		c.SigningTime = time.Now()
+	}
+
+	if c.FieldName == "" {
+		c.FieldName = "Signature1"
+	}
+
+	if c.Context == nil {
+		c.Context = context.Background()
+	}
+}
+
+// Sign adds an invisible PAdES signature (ETSI.CAdES.detached) to pdf as an incremental update.
+// The original bytes are kept unchanged, so PDF/A conformance and earlier signatures are preserved.
+// Without a TimestampClient the signature is PAdES B-B, with one it is PAdES B-T.
+func Sign(pdf io.ReadSeeker, config Config) ([]byte, error) {
+	if pdf == nil {
+		return nil, fmt.Errorf("missing PDF file")
+	}
+
+	if config.Signer == nil {
+		return nil, fmt.Errorf("missing signer")
+	}
+
+	if len(config.Certificates) == 0 {
+		return nil, fmt.Errorf("missing signer certificate")
+	}
+
+	config.setDefaults()
+
+	if _, err := pdf.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("could not seek PDF file: %w", err)
+	}
+
+	original, err := io.ReadAll(pdf)
+	if err != nil {
+		return nil, fmt.Errorf("could not read PDF file: %w", err)
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(original), model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, fmt.Errorf("could not read PDF file: %w", err)
+	}
+
+	update, err := incremental.New(original, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare incremental update: %w", err)
+	}
+
+	contentsSize := defaultContentsSize
+	for _, cert := range config.Certificates {
+		contentsSize += len(cert.Raw)
+	}
+	if config.Timestamp != nil {
+		contentsSize += timestampContentsSize
+	}
+
+	sigRef := update.AddRaw(signatureDict(config, contentsSize))
+	if err := addSignatureField(ctx, update, sigRef, config.FieldName); err != nil {
+		return nil, err
+	}
+
+	result, err := update.Write()
+	if err != nil {
+		return nil, fmt.Errorf("could not write incremental update: %w", err)
+	}
+
+	data := result.Data
+	sigOffset := result.Offsets[sigRef.ObjectNumber.Value()]
+
+	contentsStart := bytes.Index(data[sigOffset:], []byte("/Contents <"))
+	byteRangeStart := bytes.Index(data[sigOffset:], []byte("/ByteRange "+byteRangePlaceholder))
+	if contentsStart < 0 || byteRangeStart < 0 {
+		return nil, fmt.Errorf("could not locate signature placeholders")
+	}
+
+	// The byte range excludes the hex string including its angle brackets.
+	contentsStart += int(sigOffset) + len("/Contents ")
+	contentsEnd := contentsStart + 2*contentsSize + 2
+	byteRange := fmt.Sprintf("[0 %d %d %d]", contentsStart, contentsEnd, len(data)-contentsEnd)
+	if len(byteRange) > len(byteRangePlaceholder) {
+		return nil, fmt.Errorf("PDF file too large to sign")
+	}
+
+	byteRangeStart += int(sigOffset) + len("/ByteRange ")
+	copy(data[byteRangeStart:], byteRange+strings.Repeat(" ", len(byteRangePlaceholder)-len(byteRange)))
+
+	signed := make([]byte, 0, len(data)-(contentsEnd-contentsStart))
+	signed = append(signed, data[:contentsStart]...)
+	signed = append(signed, data[contentsEnd:]...)
+
+	var timestamp func(signature []byte) ([]byte, error)
+	if config.Timestamp != nil {
+		timestamp = func(signature []byte) ([]byte, error) {
+			token, err := config.Timestamp.Timestamp(config.Context, signature, config.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("could not timestamp signature: %w", err)
+			}
+			return token, nil
+		}
+	}
+
+	cms, err := CreateSignedData(signed, true, oidData, config.Signer, config.Certificates, config.Hash, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cms) > contentsSize {
+		return nil, fmt.Errorf("signature of %d bytes exceeds reserved space of %d bytes", len(cms), contentsSize)
+	}
+
+	hex.Encode(data[contentsStart+1:], cms)
+
+	return data, nil
+}
+
+// signatureDict serializes the signature dictionary with placeholders for /ByteRange and /Contents.
+func signatureDict(config Config, contentsSize int) []byte {
+	var b strings.Builder
+	b.WriteString("<</Type /Sig /Filter /Adobe.PPKLite /SubFilter /ETSI.CAdES.detached")
+	b.WriteString("/ByteRange " + byteRangePlaceholder)
+	b.WriteString("/Contents <" + strings.Repeat("0", 2*contentsSize) + ">")
+	b.WriteString("/M " + types.StringLiteral(types.DateString(config.SigningTime)).PDFString())
+
+	for _, entry := range []struct{ key, value string }{
+		{"Name", config.Name},
+		{"Reason", config.Reason},
+		{"Location", config.Location},
+		{"ContactInfo", config.ContactInfo},
+	} {
+		if entry.value != "" {
+			b.WriteString("/" + entry.key + " " + encodeText(entry.value).PDFString())
+		}
+	}
+
+	b.WriteString(">>")
+	return []byte(b.String())
+}
+
+// addSignatureField adds an invisible signature widget to the first page and registers it in the AcroForm.
+func addSignatureField(ctx *model.Context, update *incremental.Update, sigRef types.IndirectRef, fieldName string) error {
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return fmt.Errorf("could not get catalog: %w", err)
+	}
+
+	pageRef, err := ctx.PageDictIndRef(1)
+	if err != nil {
+		return fmt.Errorf("could not get first page: %w", err)
+	}
+	if pageRef == nil {
+		return fmt.Errorf("could not get first page: PDF has no pages")
+	}
+
+	page, err := ctx.DereferenceDict(*pageRef)
+	if err != nil {
+		return fmt.Errorf("could not get first page: %w", err)
+	}
+
+	// PDF/A requires an appearance stream for every widget, even an invisible one.
+	appearance := update.Add(types.StreamDict{
+		Dict: types.Dict{
+			"Type":    types.Name("XObject"),
+			"Subtype": types.Name("Form"),
+			"BBox":    types.Array{types.Integer(0), types.Integer(0), types.Integer(0), types.Integer(0)},
+		},
+	})
+
+	form, formRef, err := acroForm(ctx, catalog)
+	if err != nil {
+		return err
+	}
+
+	fields, fieldsRef, err := arrayEntry(ctx, form, "Fields")
+	if err != nil {
+		return err
+	}
+
+	fieldName, err = uniqueFieldName(ctx, fields, fieldName)
+	if err != nil {
+		return err
+	}
+
+	widget := update.Add(types.Dict{
+		"Type":    types.Name("Annot"),
+		"Subtype": types.Name("Widget"),
+		"FT":      types.Name("Sig"),
+		"Rect":    types.Array{types.Integer(0), types.Integer(0), types.Integer(0), types.Integer(0)},
+		"F":       types.Integer(132), // Print and Locked
+		"T":       encodeText(fieldName),
+		"V":       sigRef,
+		"P":       *pageRef,
+		"AP":      types.Dict{"N": appearance},
+	})
+
+	fields = append(fields, widget)
+	if fieldsRef != nil {
+		update.Replace(*fieldsRef, fields)
+	} else {
+		form["Fields"] = fields
+	}
+	form["SigFlags"] = types.Integer(3) // SignaturesExist and AppendOnly
+
+	if formRef != nil {
+		update.Replace(*formRef, form)
+	} else {
+		catalog["AcroForm"] = form
+	}
+	update.Replace(*ctx.XRefTable.Root, catalog)
+
+	annots, annotsRef, err := arrayEntry(ctx, page, "Annots")
+	if err != nil {
+		return err
+	}
+
+	annots = append(annots, widget)
+	if annotsRef != nil {
+		update.Replace(*annotsRef, annots)
+	} else {
+		page["Annots"] = annots
+		update.Replace(*pageRef, page)
+	}
+
+	return nil
+}
+
+// acroForm returns the catalog's AcroForm dict and its reference if it is an indirect object.
+func acroForm(ctx *model.Context, catalog types.Dict) (types.Dict, *types.IndirectRef, error) {
+	obj, found := catalog.Find("AcroForm")
+	if !found || obj == nil {
+		return types.Dict{}, nil, nil
+	}
+
+	form, err := ctx.DereferenceDict(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get AcroForm: %w", err)
+	}
+
+	if form == nil {
+		form = types.Dict{}
+	}
+
+	if ref, ok := obj.(types.IndirectRef); ok {
+		return form, &ref, nil
+	}
+
+	return form, nil, nil
+}
+
+// arrayEntry returns the array stored under key and its reference if it is an indirect object.
+func arrayEntry(ctx *model.Context, d types.Dict, key string) (types.Array, *types.IndirectRef, error) {
+	obj, found := d.Find(key)
+	if !found || obj == nil {
+		return nil, nil, nil
+	}
+
+	arr, err := ctx.DereferenceArray(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get %s: %w", key, err)
+	}
+
+	if ref, ok := obj.(types.IndirectRef); ok {
+		return arr, &ref, nil
+	}
+
+	return arr, nil, nil
+}
+
+// uniqueFieldName appends a counter to name until no top level field uses it.
+func uniqueFieldName(ctx *model.Context, fields types.Array, name string) (string, error) {
+	used := map[string]bool{}
+	for _, obj := range fields {
+		field, err := ctx.DereferenceDict(obj)
+		if err != nil {
+			return "", fmt.Errorf("could not get form field: %w", err)
+		}
+
+		if t, found := field.Find("T"); found {
+			if s, err := types.StringOrHexLiteral(t); err == nil && s != nil {
+				used[*s] = true
+			}
+		}
+	}
+
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), i)
+	}
+
+	return candidate, nil
+}
+
+// encodeText encodes s as PDF text string, using UTF-16 only when s is not plain ASCII.
+func encodeText(s string) types.StringLiteral {
+	for _, r := range s {
+		if r > 0x7e {
+			escaped, err := types.EscapedUTF16String(s)
+			if err == nil {
+				return types.StringLiteral(*escaped)
+			}
+			break
+		}
+	}
+
+	escaped, err := types.Escape(s)
+	if err != nil {
+		return types.StringLiteral(s)
+	}
+	return types.StringLiteral(*escaped)
+}
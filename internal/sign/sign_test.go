@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCertificate(t *testing.T, cn string) (crypto.Signer, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return key, cert
+}
+
+// newTimestampAuthority starts a minimal RFC 3161 TSA that signs every request.
+func newTimestampAuthority(t *testing.T) *httptest.Server {
+	key, cert := newCertificate(t, "test TSA")
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var req timeStampReq
+		if _, err := asn1.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		info, err := asn1.Marshal(TSTInfo{
+			Version:        1,
+			Policy:         asn1.ObjectIdentifier{1, 2, 3, 4},
+			MessageImprint: req.MessageImprint,
+			SerialNumber:   big.NewInt(1),
+			GenTime:        time.Now().UTC().Truncate(time.Second),
+			Nonce:          req.Nonce,
+		})
+		require.NoError(t, err)
+
+		token, err := CreateSignedData(info, false, oidTSTInfo, key, []*x509.Certificate{cert}, crypto.SHA256, nil)
+		require.NoError(t, err)
+
+		resp, err := asn1.Marshal(timeStampResp{TimeStampToken: asn1.RawValue{FullBytes: token}})
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, _ = w.Write(resp)
+	}))
+}
+
+func TestSign_WithTimestamp(t *testing.T) {
+	tsa := newTimestampAuthority(t)
+	defer tsa.Close()
+
+	original, err := os.ReadFile("../../testdata/invoice.pdf")
+	require.NoError(t, err)
+
+	key, cert := newCertificate(t, "test signer")
+	signed, err := Sign(bytes.NewReader(original), Config{
+		Signer:       key,
+		Certificates: []*x509.Certificate{cert},
+		Timestamp:    &TimestampClient{URL: tsa.URL},
+	})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(signed, original))
+
+	start := bytes.LastIndex(signed, []byte("/Contents <")) + len("/Contents <")
+	end := start + bytes.IndexByte(signed[start:], '>')
+
+	der := make([]byte, (end-start)/2)
+	_, err = hex.Decode(der, signed[start:end])
+	require.NoError(t, err)
+
+	sd, err := ParseSignedData(der)
+	require.NoError(t, err)
+
+	token := sd.TimeStampToken()
+	require.NotNil(t, token)
+
+	info, err := ParseTimestampToken(token)
+	require.NoError(t, err)
+	assert.True(t, info.MatchesSignature(sd.Signature()))
+}
+
+func TestSign_TimestampAuthorityUnavailable(t *testing.T) {
+	tsa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer tsa.Close()
+
+	original, err := os.ReadFile("../../testdata/invoice.pdf")
+	require.NoError(t, err)
+
+	key, cert := newCertificate(t, "test signer")
+	signed, err := Sign(bytes.NewReader(original), Config{
+		Signer:       key,
+		Certificates: []*x509.Certificate{cert},
+		Timestamp:    &TimestampClient{URL: tsa.URL},
+	})
+	assert.Error(t, err)
+	assert.Nil(t, signed)
+}
+
+func TestSign_NoPages(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := []int{buf.Len()}
+	buf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	offsets = append(offsets, buf.Len())
+	buf.WriteString("2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n")
+	xref := buf.Len()
+	buf.WriteString("xref\n0 3\n0000000000 65535 f \n")
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size 3 /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", xref)
+
+	key, cert := newCertificate(t, "test signer")
+	_, err := Sign(bytes.NewReader(buf.Bytes()), Config{Signer: key, Certificates: []*x509.Certificate{cert}})
+	require.Error(t, err)
+	assert.EqualError(t, err, "could not get first page: PDF has no pages")
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package sign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TimestampClient requests RFC 3161 time-stamp tokens from a time-stamping authority.
+type TimestampClient struct {
+	URL        string
+	HTTPClient *http.Client // defaults to a client with a 30 second timeout
+	Username   string       // optional HTTP basic auth
+	Password   string
+}
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional,utf8"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+// TSTInfo is the content of a time-stamp token.
+type TSTInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       accuracy  `asn1:"optional"`
+	Ordering       bool      `asn1:"optional"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+// Timestamp requests a token over data and returns the DER encoded token.
+// The token is checked to belong to the request before it is returned.
+func (c *TimestampClient) Timestamp(ctx context.Context, data []byte, hash crypto.Hash) ([]byte, error) {
+	algorithm, err := digestAlgorithmFor(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hash.New()
+	h.Write(data)
+
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+
+	req := timeStampReq{
+		Version:        1,
+		MessageImprint: messageImprint{HashAlgorithm: algorithm, HashedMessage: h.Sum(nil)},
+		Nonce:          nonce,
+		CertReq:        true,
+	}
+
+	body, err := asn1.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal timestamp request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/timestamp-query")
+	if c.Username != "" {
+		httpReq.SetBasicAuth(c.Username, c.Password)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("could not request timestamp: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("timestamp authority returned %s", resp.Status)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("could not read timestamp response: %w", err)
+	}
+
+	var tsResp timeStampResp
+	if _, err := asn1.Unmarshal(raw, &tsResp); err != nil {
+		return nil, fmt.Errorf("could not parse timestamp response: %w", err)
+	}
+
+	// 0 is granted, 1 is granted with modifications.
+	if tsResp.Status.Status > 1 {
+		return nil, fmt.Errorf("timestamp request rejected with status %d: %s",
+			tsResp.Status.Status, strings.Join(tsResp.Status.StatusString, "; "))
+	}
+
+	token := tsResp.TimeStampToken.FullBytes
+	info, err := ParseTimestampToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(info.MessageImprint.HashedMessage, req.MessageImprint.HashedMessage) {
+		return nil, fmt.Errorf("timestamp token does not match the request")
+	}
+
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return nil, fmt.Errorf("timestamp token nonce does not match the request")
+	}
+
+	return token, nil
+}
+
+// ParseTimestampToken verifies the CMS signature of a time-stamp token and returns its TSTInfo.
+func ParseTimestampToken(token []byte) (*TSTInfo, error) {
+	sd, err := ParseSignedData(token)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse timestamp token: %w", err)
+	}
+
+	if !sd.ContentType.Equal(oidTSTInfo) {
+		return nil, fmt.Errorf("timestamp token has content type %s", sd.ContentType)
+	}
+
+	if err := sd.Verify(nil); err != nil {
+		return nil, fmt.Errorf("could not verify timestamp token: %w", err)
+	}
+
+	var info TSTInfo
+	if _, err := asn1.Unmarshal(sd.Content, &info); err != nil {
+		return nil, fmt.Errorf("could not parse timestamp info: %w", err)
+	}
+
+	return &info, nil
+}
+
+// MatchesSignature reports whether the token's message imprint is the digest of signature.
+func (t *TSTInfo) MatchesSignature(signature []byte) bool {
+	hash, err := hashFor(t.MessageImprint.HashAlgorithm.Algorithm)
+	if err != nil {
+		return false
+	}
+
+	h := hash.New()
+	h.Write(signature)
+	return bytes.Equal(h.Sum(nil), t.MessageImprint.HashedMessage)
+}
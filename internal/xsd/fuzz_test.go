@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package xsd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/xsd/fx"
+	"github.com/MarlinKuhn/gopdfattach/internal/xsd/pdfaExtension"
+	"github.com/MarlinKuhn/gopdfattach/internal/xsd/pdfaid"
+	"github.com/MarlinKuhn/gopdfattach/internal/xsd/zf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/trimmer-io/go-xmp/xmp"
+)
+
+// FuzzXMP checks that no XMP packet makes reading or updating the fx, zf, pdfaid and pdfaExtension
+// models panic. Run it with go test -fuzz=FuzzXMP ./internal/xsd
+func FuzzXMP(f *testing.F) {
+	samples, err := filepath.Glob("../../testdata/*/*.pdf")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	// The seeds are the catalog XMP packets of the testdata PDFs.
+	for _, sample := range append(samples, "../../testdata/invoice.pdf") {
+		file, err := os.Open(sample)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		ctx, err := api.ReadContext(file, model.NewDefaultConfiguration())
+		file.Close()
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		metadata, err := pdfcpu.ExtractMetadata(ctx)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		for _, meta := range metadata {
+			if meta.ParentType != "Catalog" {
+				continue
+			}
+
+			data, err := io.ReadAll(meta)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(data)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		doc := xmp.NewDocument()
+		if err := xmp.Unmarshal(data, doc); err != nil {
+			return
+		}
+
+		// Like extract reads the metadata.
+		fx.FindModel(doc)
+		zf.FindModel(doc)
+		pdfaid.FindModel(doc)
+		pdfaExtension.FindModel(doc)
+
+		// Like attach updates it.
+		if _, err := pdfaid.MakeModel(doc); err != nil {
+			return
+		}
+		extension, err := pdfaExtension.MakeModel(doc)
+		if err != nil {
+			return
+		}
+		extension.RemoveSchema(zf.NsZugferd.URI)
+		extension.AddFx()
+		if _, err := fx.MakeModel(doc); err != nil {
+			return
+		}
+
+		_, _ = xmp.Marshal(doc)
+	})
+}
@@ -6,6 +6,7 @@ package pdfaExtension
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/MarlinKuhn/gopdfattach/internal/xsd/fx"
 	"github.com/MarlinKuhn/gopdfattach/internal/xsd/zf"
@@ -57,13 +58,17 @@ type PdfaExtension struct {
 	Schemas SchemaList `xmp:"pdfaExtension:schemas"`
 }
 
+// RemoveSchema removes the schemas declaring the namespace uri.
+func (x *PdfaExtension) RemoveSchema(uri string) {
+	x.Schemas = slices.DeleteFunc(x.Schemas, func(schema Schema) bool {
+		return schema.NamespaceURI == uri
+	})
+}
+
+// AddFx declares the Factur-X namespace, replacing an existing declaration.
 func (x *PdfaExtension) AddFx() {
 	uri := fx.NsFacturX.URI
-	for _, schema := range x.Schemas {
-		if schema.NamespaceURI == uri {
-			return
-		}
-	}
+	x.RemoveSchema(uri)
 
 	x.Schemas = append(x.Schemas, Schema{
 		Schema:       "Factur-X PDFA Extension Schema",
@@ -98,13 +103,10 @@ func (x *PdfaExtension) AddFx() {
 	})
 }
 
+// AddZf declares the ZUGFeRD 2.0 namespace, replacing an existing declaration.
 func (x *PdfaExtension) AddZf() {
 	uri := zf.NsZugferd.URI
-	for _, schema := range x.Schemas {
-		if schema.NamespaceURI == uri {
-			return
-		}
-	}
+	x.RemoveSchema(uri)
 
 	x.Schemas = append(x.Schemas, Schema{
 		Schema:       "Factur-X PDFA Extension Schema",
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExtract_Lazy checks that reading only the objects extraction needs gives the same result as
+// reading the whole PDF, for the samples as they are, rewritten with object streams and a
+// cross-reference stream, updated incrementally on top of that and signed.
+func TestExtract_Lazy(t *testing.T) {
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signConfig := SignConfig{Signer: key, Certificates: []*x509.Certificate{newTestCertificate(t, key)}}
+
+	samples, err := filepath.Glob("testdata/*/*.pdf")
+	require.NoError(t, err)
+
+	for _, sample := range append(samples, "testdata/invoice.pdf") {
+		original, err := os.ReadFile(sample)
+		require.NoError(t, err)
+
+		rewritten, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(original), nil)
+		require.NoError(t, err)
+
+		incremental, err := AttachZUGFeRD(bytes.NewReader(xml), bytes.NewReader(rewritten), &AttachConfig{Incremental: true})
+		require.NoError(t, err)
+
+		signed, err := Sign(bytes.NewReader(incremental), signConfig)
+		require.NoError(t, err)
+
+		for _, variant := range []struct {
+			name string
+			data []byte
+		}{
+			{"original", original},
+			{"rewritten", rewritten},
+			{"incremental", incremental},
+			{"signed", signed},
+		} {
+			t.Run(filepath.Base(sample)+"/"+variant.name, func(t *testing.T) {
+				_, err := extract.ReadLazy(context.Background(), bytes.NewReader(variant.data), extract.Config{})
+				require.NoError(t, err)
+
+				lazy, lazyErr := extract.FromReader(context.Background(), bytes.NewReader(variant.data), extract.Config{})
+				full, fullErr := extract.FromReader(context.Background(), bytes.NewReader(variant.data), extract.Config{FullRead: true})
+				if fullErr != nil {
+					assert.EqualError(t, lazyErr, fullErr.Error())
+					return
+				}
+
+				require.NoError(t, lazyErr)
+				assert.Equal(t, full, lazy)
+			})
+		}
+	}
+}
+
+func TestExtract_LazyFallback(t *testing.T) {
+	hybrid := attachedTestPDF(t)
+
+	// The last startxref points at the line break before the xref keyword, which pdfcpu tolerates.
+	original, err := os.ReadFile("testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+	matches := regexp.MustCompile(`startxref\s+(\d+)`).FindAllSubmatchIndex(original, -1)
+	last := matches[len(matches)-1]
+	offset, err := strconv.Atoi(string(original[last[2]:last[3]]))
+	require.NoError(t, err)
+	shifted := append(append(append([]byte{}, original[:last[2]]...), strconv.Itoa(offset-1)...), original[last[3]:]...)
+
+	for name, pdf := range map[string][]byte{
+		"encrypted":          encryptedTestPDF(t, "testdata/EN16931/EN16931_Einfach.pdf", "", "owner"),
+		"shifted startxref":  shifted,
+		"garbage before PDF": append([]byte("garbage\n"), hybrid...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := extract.ReadLazy(context.Background(), bytes.NewReader(pdf), extract.Config{})
+			require.Error(t, err)
+
+			xml, infos, err := Extract(bytes.NewReader(pdf))
+			require.NoError(t, err)
+			assert.NotEmpty(t, xml)
+			assert.Equal(t, FileTypeFacturX, infos.FileType)
+		})
+	}
+}
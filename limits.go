@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+)
+
+// ErrLimitExceeded is wrapped by the error returned when a PDF or XML exceeds one of the Limits.
+var ErrLimitExceeded = limits.ErrExceeded
+
+// Limits bounds the resources spent on a PDF, so a crafted file cannot tie up a worker. Zero values
+// disable the respective limit. The limits are enforced before pdfcpu reads the PDF, right after the
+// cross-reference table is built and while the attachment is decoded.
+type Limits struct {
+	MaxInputSize        int64 // size of the PDF in bytes
+	MaxObjects          int   // number of objects of the PDF
+	MaxEmbeddedFileSize int64 // size of the attached or extracted XML in bytes
+	MaxDecompressedSize int64 // decoded size of any single Flate stream of the PDF in bytes
+}
+
+// DefaultLimits are generous limits for invoices from untrusted sources.
+var DefaultLimits = Limits{
+	MaxInputSize:        64 << 20,
+	MaxObjects:          100_000,
+	MaxEmbeddedFileSize: 16 << 20,
+	MaxDecompressedSize: 256 << 20,
+}
+
+func (l Limits) toLimits() limits.Limits {
+	return limits.Limits{
+		MaxInputSize:        l.MaxInputSize,
+		MaxObjects:          l.MaxObjects,
+		MaxEmbeddedFileSize: l.MaxEmbeddedFileSize,
+		MaxDecompressedSize: l.MaxDecompressedSize,
+	}
+}
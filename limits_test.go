@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract_WithLimits(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+
+	xml, _, err := ExtractWithConfig(bytes.NewReader(pdf), &ExtractConfig{Limits: DefaultLimits})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, xml)
+
+	for name, limits := range map[string]Limits{
+		"input size":         {MaxInputSize: 1024},
+		"objects":            {MaxObjects: 5},
+		"embedded file size": {MaxEmbeddedFileSize: 100},
+		"decompressed size":  {MaxDecompressedSize: 100},
+	} {
+		t.Run(name, func(t *testing.T) {
+			xml, infos, err := ExtractWithConfig(bytes.NewReader(pdf), &ExtractConfig{Limits: limits})
+			assert.ErrorIs(t, err, ErrLimitExceeded)
+			assert.Nil(t, xml)
+			assert.Nil(t, infos)
+		})
+	}
+}
+
+func TestExtract_DecompressionBomb(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+
+	var bomb bytes.Buffer
+	w := zlib.NewWriter(&bomb)
+	_, err = w.Write(make([]byte, 64<<20))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	var crafted bytes.Buffer
+	crafted.Write(pdf)
+	fmt.Fprintf(&crafted, "\n9999 0 obj\n<</Length %d /Filter /FlateDecode>>stream\n", bomb.Len())
+	crafted.Write(bomb.Bytes())
+	crafted.WriteString("\nendstream\nendobj\n")
+
+	_, _, err = ExtractWithConfig(bytes.NewReader(crafted.Bytes()), &ExtractConfig{Limits: Limits{MaxDecompressedSize: 16 << 20}})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+func TestExtractContext_Cancelled(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = ExtractContext(ctx, bytes.NewReader(pdf), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAttach_WithLimits(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	pdfData, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), &AttachConfig{Limits: DefaultLimits})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pdfData)
+
+	pdfData, err = AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), &AttachConfig{Limits: Limits{MaxEmbeddedFileSize: 100}})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Nil(t, pdfData)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pdfData, err = AttachFacturXContext(ctx, bytes.NewReader(xml), bytes.NewReader(pdf), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, pdfData)
+}
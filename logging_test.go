@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logSteps returns the step attribute of every "step done" record written by a JSON handler.
+func logSteps(t *testing.T, logs *bytes.Buffer) []string {
+	t.Helper()
+
+	var steps []string
+	for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(line, &record))
+		if record["msg"] != "step done" {
+			continue
+		}
+
+		assert.Contains(t, record, "duration")
+		steps = append(steps, record["step"].(string))
+	}
+	return steps
+}
+
+func TestAttach_Logger(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err = AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), &AttachConfig{Logger: logger})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "validate xref", "xmp merge", "attach", "write"}, logSteps(t, &logs))
+	assert.Contains(t, logs.String(), `"xml_size":`+jsonNumber(len(xml)))
+
+	logs.Reset()
+	logger = slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	_, err = AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), &AttachConfig{Logger: logger})
+	require.NoError(t, err)
+	assert.Empty(t, logs.String())
+}
+
+func TestExtract_Logger(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/EN16931/EN16931_Einfach.pdf")
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	xml, _, err := ExtractWithConfig(bytes.NewReader(pdf), &ExtractConfig{Logger: logger})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "load embedded files", "read xmp", "extract", "signatures"}, logSteps(t, &logs))
+	assert.Contains(t, logs.String(), `"xml_size":`+jsonNumber(len(xml)))
+}
+
+func jsonNumber(n int) string {
+	data, _ := json.Marshal(n)
+	return string(data)
+}
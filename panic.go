@@ -0,0 +1,14 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
+)
+
+// PanicError is returned by the functions of this package instead of a panic raised while
+// processing a PDF or XML, typically deep inside pdfcpu or go-xmp on a malformed file. Op names the
+// failing operation, Value is the value passed to panic and Stack the stack trace at that point.
+type PanicError = recovery.Error
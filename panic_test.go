@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panicReader stands in for a bug deep inside pdfcpu or go-xmp.
+type panicReader struct {
+	value any
+}
+
+func (r panicReader) Read([]byte) (int, error)       { panic(r.value) }
+func (r panicReader) Seek(int64, int) (int64, error) { panic(r.value) }
+
+func TestPanicError(t *testing.T) {
+	_, _, err := Extract(panicReader{"boom"})
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "extract", panicErr.Op)
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.EqualError(t, err, "extract: recovered from panic: boom")
+
+	_, err = Inspect(panicReader{"boom"}, nil)
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "inspect", panicErr.Op)
+
+	pdf, err := os.Open("testdata/invoice.pdf")
+	require.NoError(t, err)
+	defer pdf.Close()
+
+	_, err = AttachFacturX(panicReader{"boom"}, pdf, nil)
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "attach", panicErr.Op)
+
+	// An error passed to panic, like a runtime.Error, is wrapped.
+	_, err = RenderFacturX(panicReader{io.ErrUnexpectedEOF}, nil)
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "render", panicErr.Op)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
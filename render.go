@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/MarlinKuhn/gopdfattach/internal/attach"
+	"github.com/MarlinKuhn/gopdfattach/internal/extract"
+	"github.com/MarlinKuhn/gopdfattach/internal/limits"
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
+	"github.com/MarlinKuhn/gopdfattach/internal/render"
+)
+
+// RenderFacturX builds a complete hybrid invoice from a CII XML alone: the parties, lines, VAT
+// breakdown, totals and payment terms are laid out as a PDF with embedded fonts, and the XML is
+// attached to it like AttachFacturX does. Config fields left empty are derived from the XML: the
+// conformance level and XRechnung version from the guideline ID, the document type from the type
+// code and the AFRelationship from the conformance level.
+func RenderFacturX(xml io.Reader, config *AttachConfig) ([]byte, error) {
+	return RenderFacturXContext(context.Background(), xml, config)
+}
+
+// RenderFacturXContext is like RenderFacturX but stops once ctx is cancelled.
+func RenderFacturXContext(ctx context.Context, xml io.Reader, config *AttachConfig) (_ []byte, err error) {
+	defer recovery.Recover("render", &err)
+
+	if xml == nil {
+		return nil, fmt.Errorf("missing XML file")
+	}
+
+	if err := config.Validate(FileTypeFacturX); err != nil {
+		return nil, err
+	}
+
+	c := config.toConfig()
+	c.XmlType = attach.TypeFacturX
+
+	data, err := limits.ReadAll(xml, c.Limits.MaxEmbeddedFileSize, "XML file")
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := cii.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ConformanceLevel == "" {
+		level, version, ok := extract.GuidelineLevel(invoice.GuidelineID)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown guideline ID %q, set the conformance level", ErrInvalidConfig, invoice.GuidelineID)
+		}
+
+		c.ConformanceLevel = level
+		if c.Version == "" {
+			c.Version = version
+		}
+		if c.FileName == "" && level == string(ConformanceXRechnung) {
+			c.FileName = "xrechnung.xml"
+		}
+	}
+
+	if c.DocumentType == "" {
+		c.DocumentType = extract.TypeCodeDocumentType(invoice.TypeCode)
+	}
+
+	if c.AFRelationship == "" {
+		c.AFRelationship = extract.AFRelationships(c.ConformanceLevel)[0]
+	}
+
+	pdf, err := render.Render(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return attach.Attach(ctx, bytes.NewReader(data), bytes.NewReader(pdf), c)
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/MarlinKuhn/gopdfattach/cii"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderFacturX(t *testing.T) {
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	pdf, err := RenderFacturX(bytes.NewReader(xml), nil)
+	require.NoError(t, err)
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationStrict
+	require.NoError(t, api.Validate(bytes.NewReader(pdf), conf))
+
+	// The conformance level and document type are derived from the XML.
+	extracted, infos, err := ExtractWithConfig(bytes.NewReader(pdf), &ExtractConfig{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted)
+	assert.Equal(t, string(ConformanceBasic), infos.ConformanceLevel)
+	assert.Equal(t, string(DocumentTypeInvoice), infos.DocumentType)
+
+	report, err := Inspect(bytes.NewReader(pdf), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Pages)
+	assert.Equal(t, []ProblemCode{ProblemNoOutputIntent}, problemCodes(report))
+
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	require.NoError(t, api.ValidateContext(ctx))
+
+	// Both fonts carry their program.
+	var descriptors int
+	for _, entry := range ctx.XRefTable.Table {
+		if entry == nil {
+			continue
+		}
+		if d, ok := entry.Object.(types.Dict); ok && d.Type() != nil && *d.Type() == "FontDescriptor" {
+			descriptors++
+			assert.Contains(t, d, "FontFile2")
+		}
+	}
+	assert.Equal(t, 2, descriptors)
+}
+
+func TestRenderFacturX_MultiPage(t *testing.T) {
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	const end = "</ram:IncludedSupplyChainTradeLineItem>"
+	s := string(xml)
+	item := s[strings.Index(s, "<ram:IncludedSupplyChainTradeLineItem>") : strings.Index(s, end)+len(end)]
+	many := strings.Replace(s, item, strings.Repeat(item, 60), 1)
+
+	pdf, err := RenderFacturX(strings.NewReader(many), &AttachConfig{ConformanceLevel: ConformanceBasic})
+	require.NoError(t, err)
+
+	report, err := Inspect(bytes.NewReader(pdf), nil)
+	require.NoError(t, err)
+	assert.Greater(t, report.Pages, 1)
+}
+
+func TestRenderFacturX_Testdata(t *testing.T) {
+	for _, file := range []string{
+		"testdata/EN16931/EN16931_Rabatte.pdf",
+		"testdata/EN16931/EN16931_Gutschrift.pdf",
+		"testdata/EXTENDED/EXTENDED_Warenrechnung.pdf",
+		"testdata/XRECHNUNG/XRECHNUNG_Einfach.pdf",
+	} {
+		t.Run(file, func(t *testing.T) {
+			original, err := os.Open(file)
+			require.NoError(t, err)
+			defer original.Close()
+
+			xml, before, err := Extract(original)
+			require.NoError(t, err)
+
+			pdf, err := RenderFacturX(bytes.NewReader(xml), nil)
+			require.NoError(t, err)
+
+			extracted, after, err := ExtractWithConfig(bytes.NewReader(pdf), &ExtractConfig{Strict: true})
+			require.NoError(t, err)
+			assert.Equal(t, xml, extracted)
+			assert.Equal(t, before.ConformanceLevel, after.ConformanceLevel)
+			assert.Equal(t, before.FileName, after.FileName)
+		})
+	}
+}
+
+func TestRenderFacturX_NotCII(t *testing.T) {
+	_, err := RenderFacturX(strings.NewReader(`<Invoice xmlns="urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"/>`), nil)
+	assert.ErrorIs(t, err, cii.ErrNotCII)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/attach"
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
+)
+
+// RepairConfig holds the passwords, resource limits and logger used by Repair.
+type RepairConfig struct {
+	Creator       string // written to the XMP metadata if it is rewritten, defaults to "gopdfattach"
+	UserPassword  string
+	OwnerPassword string
+	Limits        Limits       // see DefaultLimits
+	Logger        *slog.Logger // receives a debug event per step, nil disables logging
+}
+
+func (r *RepairConfig) toConfig() attach.RepairConfig {
+	if r == nil {
+		return attach.RepairConfig{}
+	}
+
+	return attach.RepairConfig{
+		Creator:       r.Creator,
+		UserPassword:  r.UserPassword,
+		OwnerPassword: r.OwnerPassword,
+		Limits:        r.Limits.toLimits(),
+		Logger:        r.Logger,
+	}
+}
+
+// Fix is a defect corrected by Repair. Problem is the code Inspect reports for it.
+type Fix = attach.Fix
+
+// Repair fixes almost-correct hybrid invoices: a missing AFRelationship, a /Subtype other than
+// text/xml, a missing catalog /AF entry, an XMP DocumentFileName naming a file that does not exist,
+// a missing PDF/A extension schema, missing pdfaid or fx XMP values and encryption. The defects are
+// detected like Inspect does and the PDF is rewritten with the embedded XML unchanged. The returned
+// fixes list what was changed; problems Repair cannot fix remain and are reported by Inspect.
+func Repair(pdf io.ReadSeeker, config *RepairConfig) ([]byte, []Fix, error) {
+	return RepairContext(context.Background(), pdf, config)
+}
+
+// RepairContext is like Repair but stops once ctx is cancelled.
+func RepairContext(ctx context.Context, pdf io.ReadSeeker, config *RepairConfig) (_ []byte, _ []Fix, err error) {
+	defer recovery.Recover("repair", &err)
+
+	return attach.Repair(ctx, pdf, config.toConfig())
+}
+
+// Upgrade rewrites the metadata of an older hybrid invoice, like a ZUGFeRD 2.0 one produced by
+// AttachZUGFeRD with the zf namespace and version 2p0, as current Factur-X/ZUGFeRD metadata: fx XMP
+// values with the conformance level of the XML guideline ID, a refreshed extension schema, the
+// attachment renamed to factur-x.xml (xrechnung.xml for XRECHNUNG) and an AFRelationship the level
+// allows. The embedded XML is kept byte-identical. The configuration is the one of Repair.
+func Upgrade(pdf io.ReadSeeker, config *RepairConfig) ([]byte, error) {
+	return UpgradeContext(context.Background(), pdf, config)
+}
+
+// UpgradeContext is like Upgrade but stops once ctx is cancelled.
+func UpgradeContext(ctx context.Context, pdf io.ReadSeeker, config *RepairConfig) (_ []byte, err error) {
+	defer recovery.Recover("upgrade", &err)
+
+	return attach.Upgrade(ctx, pdf, config.toConfig())
+}
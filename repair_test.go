@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepair_BrokenFileSpec(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	hybrid, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), &AttachConfig{ConformanceLevel: ConformanceBasic})
+	require.NoError(t, err)
+
+	ctx, err := api.ReadContext(bytes.NewReader(hybrid), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	catalog, err := ctx.Catalog()
+	require.NoError(t, err)
+
+	spec, err := ctx.DereferenceDict(catalog.ArrayEntry("AF")[0])
+	require.NoError(t, err)
+	spec.Delete("AFRelationship")
+	ef, _, err := ctx.DereferenceStreamDict(spec.DictEntry("EF")["F"])
+	require.NoError(t, err)
+	ef.Update("Subtype", types.Name("application/octet-stream"))
+	catalog.Delete("AF")
+
+	var broken bytes.Buffer
+	require.NoError(t, api.Write(ctx, &broken, model.NewDefaultConfiguration()))
+
+	report, err := Inspect(bytes.NewReader(broken.Bytes()), nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ProblemCode{ProblemNotAssociated, ProblemNoAFRelationship, ProblemWrongSubtype, ProblemNoOutputIntent},
+		problemCodes(report))
+
+	repaired, fixes, err := Repair(bytes.NewReader(broken.Bytes()), nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ProblemCode{ProblemNotAssociated, ProblemNoAFRelationship, ProblemWrongSubtype}, fixCodes(fixes))
+
+	report, err = Inspect(bytes.NewReader(repaired), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ProblemCode{ProblemNoOutputIntent}, problemCodes(report))
+	assert.Equal(t, "Alternative", report.InvoiceFile().AFRelationship)
+
+	extracted, infos, err := ExtractWithConfig(bytes.NewReader(repaired), &ExtractConfig{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted)
+	assert.Equal(t, string(ConformanceBasic), infos.ConformanceLevel)
+}
+
+func TestRepair_NoXMP(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	var attached bytes.Buffer
+	require.NoError(t, api.AddAttachments(bytes.NewReader(pdf), &attached, []string{"testdata/factur-x.xml"}, false, nil))
+
+	repaired, fixes, err := Repair(bytes.NewReader(attached.Bytes()), nil)
+	require.NoError(t, err)
+	assert.Contains(t, fixCodes(fixes), ProblemNoXMP)
+	assert.Contains(t, fixCodes(fixes), ProblemNotAssociated)
+
+	report, err := Inspect(bytes.NewReader(repaired), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ProblemCode{ProblemNoOutputIntent}, problemCodes(report))
+
+	// The conformance level is derived from the guideline ID of the XML.
+	extracted, infos, err := ExtractWithConfig(bytes.NewReader(repaired), &ExtractConfig{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted)
+	assert.Equal(t, string(ConformanceBasic), infos.ConformanceLevel)
+	assert.Equal(t, FileTypeFacturX, infos.FileType)
+}
+
+func TestRepair_UnparseableXMP(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	hybrid, err := AttachFacturX(bytes.NewReader(xml), bytes.NewReader(pdf), &AttachConfig{ConformanceLevel: ConformanceBasic})
+	require.NoError(t, err)
+
+	ctx, err := api.ReadContext(bytes.NewReader(hybrid), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	sd, err := ctx.NewStreamDictForBuf([]byte("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\"><rdf:RDF"))
+	require.NoError(t, err)
+	sd.InsertName("Type", "Metadata")
+	sd.InsertName("Subtype", "XML")
+	require.NoError(t, sd.Encode())
+	ref, err := ctx.IndRefForNewObject(*sd)
+	require.NoError(t, err)
+	catalog, err := ctx.Catalog()
+	require.NoError(t, err)
+	catalog["Metadata"] = *ref
+
+	var broken bytes.Buffer
+	require.NoError(t, api.Write(ctx, &broken, model.NewDefaultConfiguration()))
+
+	report, err := Inspect(bytes.NewReader(broken.Bytes()), nil)
+	require.NoError(t, err)
+	assert.False(t, report.HasXMP)
+	assert.NotEmpty(t, report.XMPError)
+	assert.True(t, report.HasProblem(ProblemNoXMP))
+
+	repaired, fixes, err := Repair(bytes.NewReader(broken.Bytes()), nil)
+	require.NoError(t, err)
+	assert.Contains(t, fixCodes(fixes), ProblemNoXMP)
+
+	extracted, _, err := ExtractWithConfig(bytes.NewReader(repaired), &ExtractConfig{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted)
+}
+
+func TestRepair_Compliant(t *testing.T) {
+	file, err := os.Open("testdata/EN16931/EN16931_Elektron.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, fixes, err := Repair(file, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ProblemCode{ProblemVersionBelow17}, fixCodes(fixes))
+}
+
+func TestRepair_NoInvoice(t *testing.T) {
+	file, err := os.Open("testdata/invoice.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, _, err = Repair(file, nil)
+	assert.ErrorContains(t, err, "no embedded XML invoice found")
+}
+
+func fixCodes(fixes []Fix) []ProblemCode {
+	var codes []ProblemCode
+	for _, f := range fixes {
+		codes = append(codes, f.Problem)
+	}
+	return codes
+}
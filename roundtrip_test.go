@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripCases is the number of random configurations attached and extracted again.
+const roundTripCases = 120
+
+// roundTrip is a random but valid input of AttachFacturX or AttachZUGFeRD.
+type roundTrip struct {
+	FileType string
+	Sample   string
+	Config   AttachConfig
+	XML      []byte
+}
+
+func (r roundTrip) String() string {
+	return fmt.Sprintf("%s on %s: document type %q, file name %q, version %q, level %q, creator %q, AF %q, incremental %t, %d bytes of XML",
+		r.FileType, r.Sample, r.Config.DocumentType, r.Config.FileName, r.Config.Version, r.Config.ConformanceLevel,
+		r.Config.Creator, r.Config.AFRelationship, r.Config.Incremental, len(r.XML))
+}
+
+// want returns the XMLInfo Extract has to report for the attached XML.
+func (r roundTrip) want() XMLInfo {
+	info := XMLInfo{
+		FileType:         r.FileType,
+		DocumentType:     string(r.Config.DocumentType),
+		FileName:         r.Config.FileName,
+		Version:          r.Config.Version,
+		ConformanceLevel: string(r.Config.ConformanceLevel),
+	}
+
+	if info.DocumentType == "" {
+		info.DocumentType = string(DocumentTypeInvoice)
+	}
+	if info.FileName == "" {
+		info.FileName = "factur-x.xml"
+	}
+	if info.Version == "" {
+		info.Version = "1.0"
+		if r.FileType == FileTypeZugferd {
+			info.Version = "2p0"
+		}
+	}
+
+	return info
+}
+
+func TestAttach_RoundTrip(t *testing.T) {
+	samples, err := filepath.Glob("testdata/*/*.pdf")
+	require.NoError(t, err)
+	samples = append(samples, "testdata/invoice.pdf")
+
+	pdfs := make(map[string][]byte, len(samples))
+	for _, sample := range samples {
+		pdfs[sample], err = os.ReadFile(sample)
+		require.NoError(t, err)
+	}
+
+	for i := range roundTripCases {
+		r := rand.New(rand.NewPCG(uint64(i), 0x5eed))
+		c := randomRoundTrip(r, samples)
+
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			attach := AttachFacturX
+			if c.FileType == FileTypeZugferd {
+				attach = AttachZUGFeRD
+			}
+
+			out, err := attach(bytes.NewReader(c.XML), bytes.NewReader(pdfs[c.Sample]), &c.Config)
+			require.NoError(t, err, c)
+
+			extracted, info, err := Extract(bytes.NewReader(out))
+			require.NoError(t, err, c)
+			assert.True(t, bytes.Equal(c.XML, extracted), "extracted XML differs: %s", c)
+			assert.Equal(t, c.want(), *info, c)
+		})
+	}
+}
+
+// randomRoundTrip draws the file type, a sample PDF, a config valid for the file type and a CII
+// XML consistent with the config, so Extract reports no warnings.
+func randomRoundTrip(r *rand.Rand, samples []string) roundTrip {
+	c := roundTrip{
+		FileType: FileTypeFacturX,
+		Sample:   samples[r.IntN(len(samples))],
+	}
+
+	levels, versions := facturXConformanceLevels, facturXVersions
+	if r.IntN(2) == 0 {
+		c.FileType = FileTypeZugferd
+		levels, versions = zugferdConformanceLevels, zugferdVersions
+	}
+
+	c.Config.ConformanceLevel = pick(r, levels)
+	if c.Config.ConformanceLevel == ConformanceXRechnung {
+		// The version is part of the guideline ID, so it cannot be left to the default.
+		c.Config.Version = fmt.Sprintf("%d.%d", 1+r.IntN(3), r.IntN(4))
+		if r.IntN(2) == 0 {
+			c.Config.Version += fmt.Sprintf(".%d", r.IntN(3))
+		}
+	} else if r.IntN(2) == 0 {
+		c.Config.Version = pick(r, versions)
+	}
+
+	if r.IntN(3) > 0 {
+		c.Config.DocumentType = pick(r, documentTypes)
+	}
+
+	// MINIMUM and BASIC WL only allow Data, their default.
+	if r.IntN(2) == 0 {
+		switch c.Config.ConformanceLevel {
+		case ConformanceMinimum, ConformanceBasicWL:
+			c.Config.AFRelationship = AFData
+		default:
+			c.Config.AFRelationship = pick(r, []AF{AFAlternative, AFSource})
+		}
+	}
+
+	if r.IntN(4) > 0 {
+		c.Config.FileName = randomText(r, 1+r.IntN(24)) + ".xml"
+	}
+	if r.IntN(2) == 0 {
+		c.Config.Creator = randomText(r, 1+r.IntN(40))
+	}
+	c.Config.Incremental = r.IntN(3) == 0
+
+	c.XML = randomInvoice(r, c)
+
+	return c
+}
+
+var guidelineIDs = map[string]string{
+	ConformanceMinimum:   "urn:factur-x.eu:1p0:minimum",
+	ConformanceBasicWL:   "urn:factur-x.eu:1p0:basicwl",
+	ConformanceBasic:     "urn:cen.eu:en16931:2017#compliant#urn:factur-x.eu:1p0:basic",
+	ConformanceEN16931:   "urn:cen.eu:en16931:2017",
+	ConformanceExtended:  "urn:cen.eu:en16931:2017#conformant#urn:factur-x.eu:1p0:extended",
+	ConformanceXRechnung: "urn:cen.eu:en16931:2017#compliant#urn:xeinkauf.de:kosit:xrechnung_",
+}
+
+var typeCodes = map[string]string{
+	DocumentTypeOrder:         "220",
+	DocumentTypeOrderChange:   "230",
+	DocumentTypeOrderResponse: "231",
+}
+
+// randomInvoice writes a CII document with the guideline ID and type code matching the config of c
+// and notes of random text. Every tenth document is between one and four megabytes large.
+func randomInvoice(r *rand.Rand, c roundTrip) []byte {
+	guideline := guidelineIDs[c.Config.ConformanceLevel]
+	if c.Config.ConformanceLevel == ConformanceXRechnung {
+		guideline += c.Config.Version
+	}
+
+	typeCode, ok := typeCodes[c.Config.DocumentType]
+	if !ok {
+		typeCode = pick(r, []string{"380", "381", "384", "389"})
+	}
+
+	size := r.IntN(4 << 10)
+	if r.IntN(10) == 0 {
+		size = 1<<20 + r.IntN(3<<20)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<rsm:CrossIndustryInvoice xmlns:rsm="urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100" xmlns:ram="urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100">` + "\n")
+	fmt.Fprintf(&buf, "<rsm:ExchangedDocumentContext><ram:GuidelineSpecifiedDocumentContextParameter><ram:ID>%s</ram:ID></ram:GuidelineSpecifiedDocumentContextParameter></rsm:ExchangedDocumentContext>\n", guideline)
+	fmt.Fprintf(&buf, "<rsm:ExchangedDocument><ram:ID>%d</ram:ID><ram:TypeCode>%s</ram:TypeCode>", r.Uint32(), typeCode)
+	for buf.Len() < size {
+		buf.WriteString("<ram:IncludedNote><ram:Content>")
+		_ = xml.EscapeText(&buf, []byte(randomText(r, 1+r.IntN(200))))
+		buf.WriteString("</ram:Content></ram:IncludedNote>\n")
+	}
+	buf.WriteString("</rsm:ExchangedDocument>\n</rsm:CrossIndustryInvoice>\n")
+
+	return buf.Bytes()
+}
+
+// textRunes mixes ASCII with Latin-1, characters outside of PDFDocEncoding and an emoji outside of
+// the Basic Multilingual Plane.
+var textRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -_.()&<>äöüßÄÖÜéèçñø€łŠŽ漢字請求書фактура😀")
+
+func randomText(r *rand.Rand, n int) string {
+	var b strings.Builder
+	for range n {
+		b.WriteRune(textRunes[r.IntN(len(textRunes))])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func pick[T any](r *rand.Rand, values []T) T {
+	return values[r.IntN(len(values))]
+}
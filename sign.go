@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"crypto"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/recovery"
+	"github.com/MarlinKuhn/gopdfattach/internal/sign"
+)
+
+// TimestampClient points at an RFC 3161 time-stamping authority, e.g. a local TSA.
+type TimestampClient struct {
+	URL        string
+	HTTPClient *http.Client // defaults to a client with a 30 second timeout
+	Username   string       // optional HTTP basic auth
+	Password   string
+}
+
+type SignConfig struct {
+	Signer       crypto.Signer       // RSA or ECDSA private key
+	Certificates []*x509.Certificate // signer certificate first, followed by the chain
+	Hash         crypto.Hash         // defaults to crypto.SHA256
+	Name         string              // optional name of the signer
+	Reason       string              // optional reason for signing
+	Location     string              // optional location of signing
+	ContactInfo  string              // optional contact information of the signer
+	SigningTime  time.Time           // defaults to the current time
+	FieldName    string              // defaults to "Signature1"
+	Timestamp    *TimestampClient    // if set, the signature is time-stamped (PAdES B-T)
+}
+
+func (s *SignConfig) toConfig() sign.Config {
+	c := sign.Config{
+		Signer:       s.Signer,
+		Certificates: s.Certificates,
+		Hash:         s.Hash,
+		Name:         s.Name,
+		Reason:       s.Reason,
+		Location:     s.Location,
+		ContactInfo:  s.ContactInfo,
+		SigningTime:  s.SigningTime,
+		FieldName:    s.FieldName,
+	}
+
+	if s.Timestamp != nil {
+		c.Timestamp = &sign.TimestampClient{
+			URL:        s.Timestamp.URL,
+			HTTPClient: s.Timestamp.HTTPClient,
+			Username:   s.Timestamp.Username,
+			Password:   s.Timestamp.Password,
+		}
+	}
+
+	return c
+}
+
+// Sign adds an invisible PAdES signature to a PDF, typically the output of AttachFacturX or AttachZUGFeRD.
+// The signature is appended as an incremental update, so the signed bytes and the PDF/A-3 conformance of
+// the input are preserved. The signature level is PAdES B-B, or B-T if a TimestampClient is configured.
+func Sign(pdf io.ReadSeeker, config SignConfig) (_ []byte, err error) {
+	defer recovery.Recover("sign", &err)
+
+	return sign.Sign(pdf, config.toConfig())
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MarlinKuhn/gopdfattach/internal/sign"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCertificate(t *testing.T, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "gopdfattach test signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func attachedTestPDF(t *testing.T) []byte {
+	t.Helper()
+
+	pdfFile, _ := os.Open("testdata/invoice.pdf")
+	defer pdfFile.Close()
+	xmlFile, _ := os.Open("testdata/factur-x.xml")
+	defer xmlFile.Close()
+
+	pdfData, err := AttachFacturX(xmlFile, pdfFile, nil)
+	require.NoError(t, err)
+	return pdfData
+}
+
+// verifySignature checks the ByteRange and CMS signature of the last signature in data.
+func verifySignature(t *testing.T, data []byte) *sign.SignedData {
+	t.Helper()
+
+	byteRanges := regexp.MustCompile(`/ByteRange \[(\d+) (\d+) (\d+) (\d+)\s*\]`).FindAllSubmatch(data, -1)
+	require.NotEmpty(t, byteRanges)
+
+	var r [4]int
+	for i := range r {
+		r[i], _ = strconv.Atoi(string(byteRanges[len(byteRanges)-1][i+1]))
+	}
+
+	assert.Equal(t, 0, r[0])
+	assert.Equal(t, len(data), r[2]+r[3], "byte range must cover the whole file")
+	assert.Equal(t, byte('<'), data[r[1]])
+	assert.Equal(t, byte('>'), data[r[2]-1])
+
+	der, err := hex.DecodeString(string(data[r[1]+1 : r[2]-1]))
+	require.NoError(t, err)
+
+	sd, err := sign.ParseSignedData(der)
+	require.NoError(t, err)
+
+	signed := append(append([]byte{}, data[r[0]:r[0]+r[1]]...), data[r[2]:r[2]+r[3]]...)
+	assert.NoError(t, sd.Verify(signed))
+	return sd
+}
+
+func TestSign_RSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cert := newTestCertificate(t, key)
+
+	pdfData := attachedTestPDF(t)
+
+	signed, err := Sign(bytes.NewReader(pdfData), SignConfig{
+		Signer:       key,
+		Certificates: []*x509.Certificate{cert},
+		Reason:       "Rechnungsstellung",
+		Location:     "München",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(signed, pdfData), "signing must not modify the original bytes")
+
+	sd := verifySignature(t, signed)
+	assert.Equal(t, cert.Raw, sd.Signer.Raw)
+	assert.Nil(t, sd.TimeStampToken())
+
+	ctx, err := api.ReadContext(bytes.NewReader(signed), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	assert.NoError(t, validate.XRefTable(ctx))
+
+	xml, infos, err := Extract(bytes.NewReader(signed))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, xml)
+	assert.Equal(t, FileTypeFacturX, infos.FileType)
+}
+
+func TestSign_ECDSATwice(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := newTestCertificate(t, key)
+
+	config := SignConfig{Signer: key, Certificates: []*x509.Certificate{cert}}
+
+	first, err := Sign(bytes.NewReader(attachedTestPDF(t)), config)
+	require.NoError(t, err)
+
+	second, err := Sign(bytes.NewReader(first), config)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(second, first), "second signature must be an incremental update")
+	assert.Contains(t, string(second[len(first):]), "Signature2")
+	verifySignature(t, first)
+	verifySignature(t, second)
+}
+
+func TestSign_MissingSigner(t *testing.T) {
+	signed, err := Sign(bytes.NewReader(attachedTestPDF(t)), SignConfig{})
+	assert.Error(t, err)
+	assert.Nil(t, signed)
+}
+
+func TestExtract_SignedInvoice(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cert := newTestCertificate(t, key)
+
+	config := SignConfig{Signer: key, Certificates: []*x509.Certificate{cert}, Reason: "Invoice"}
+	first, err := Sign(bytes.NewReader(attachedTestPDF(t)), config)
+	require.NoError(t, err)
+
+	_, infos, err := Extract(bytes.NewReader(first))
+	require.NoError(t, err)
+	require.Len(t, infos.Signatures, 1)
+
+	sig := infos.Signatures[0]
+	assert.True(t, sig.Valid, sig.Error)
+	assert.Equal(t, "Signature1", sig.FieldName)
+	assert.Equal(t, "ETSI.CAdES.detached", sig.SubFilter)
+	assert.Equal(t, "Invoice", sig.Reason)
+	assert.Equal(t, cert.Raw, sig.Certificate.Raw)
+	assert.False(t, sig.SigningTime.IsZero())
+	assert.True(t, sig.CoversWholeDocument)
+	assert.True(t, sig.CoversAttachment)
+	assert.True(t, infos.AttachmentSigned())
+
+	second, err := Sign(bytes.NewReader(first), config)
+	require.NoError(t, err)
+
+	_, infos, err = Extract(bytes.NewReader(second))
+	require.NoError(t, err)
+	require.Len(t, infos.Signatures, 2)
+	assert.True(t, infos.Signatures[0].Valid)
+	assert.False(t, infos.Signatures[0].CoversWholeDocument)
+	assert.True(t, infos.Signatures[0].CoversAttachment)
+	assert.True(t, infos.Signatures[1].CoversWholeDocument)
+}
+
+func TestExtract_TamperedSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := newTestCertificate(t, key)
+
+	signed, err := Sign(bytes.NewReader(attachedTestPDF(t)), SignConfig{Signer: key, Certificates: []*x509.Certificate{cert}})
+	require.NoError(t, err)
+
+	// Change the binary marker comment after the header, which lies inside the signed range.
+	require.True(t, bytes.HasPrefix(signed, []byte("%PDF-1.7\n%")))
+	signed[len("%PDF-1.7\n%")] ^= 0x01
+
+	_, infos, err := Extract(bytes.NewReader(signed))
+	require.NoError(t, err)
+	require.Len(t, infos.Signatures, 1)
+	assert.False(t, infos.Signatures[0].Valid)
+	assert.NotEmpty(t, infos.Signatures[0].Error)
+	assert.False(t, infos.AttachmentSigned())
+}
+
+func TestExtract_UnsignedInvoice(t *testing.T) {
+	_, infos, err := Extract(bytes.NewReader(attachedTestPDF(t)))
+	require.NoError(t, err)
+	assert.Empty(t, infos.Signatures)
+	assert.False(t, infos.AttachmentSigned())
+}
+
+func TestExtract_AttachmentAppendedAfterSigning(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cert := newTestCertificate(t, key)
+
+	original, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+
+	signed, err := Sign(bytes.NewReader(original), SignConfig{Signer: key, Certificates: []*x509.Certificate{cert}})
+	require.NoError(t, err)
+
+	xmlFile, _ := os.Open("testdata/factur-x.xml")
+	defer xmlFile.Close()
+
+	pdfData, err := AttachFacturX(xmlFile, bytes.NewReader(signed), &AttachConfig{Incremental: true})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdfData, signed))
+
+	_, infos, err := Extract(bytes.NewReader(pdfData))
+	require.NoError(t, err)
+	require.Len(t, infos.Signatures, 1)
+	assert.True(t, infos.Signatures[0].Valid, infos.Signatures[0].Error)
+	assert.False(t, infos.Signatures[0].CoversWholeDocument)
+	assert.False(t, infos.Signatures[0].CoversAttachment)
+	assert.False(t, infos.AttachmentSigned())
+}
+
+// compressFileSpec appends an incremental update that moves the invoice's file specification into an
+// object stream, which pdfcpu never does when it writes a file specification itself.
+func compressFileSpec(t *testing.T, pdf []byte) []byte {
+	t.Helper()
+
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	require.NoError(t, api.ValidateContext(ctx))
+
+	require.NoError(t, ctx.XRefTable.LocateNameTree("EmbeddedFiles", false))
+	var ref types.IndirectRef
+	require.NoError(t, ctx.XRefTable.Names["EmbeddedFiles"].Process(ctx.XRefTable, func(_ *model.XRefTable, name string, spec *types.Object) error {
+		if name == "factur-x.xml" {
+			ref = (*spec).(types.IndirectRef)
+		}
+		return nil
+	}))
+	spec, err := ctx.XRefTable.DereferenceDict(ref)
+	require.NoError(t, err)
+
+	prev := regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`).FindSubmatch(pdf)
+	require.NotNil(t, prev)
+
+	specNr, objStmNr, xrefNr := ref.ObjectNumber.Value(), *ctx.XRefTable.Size, *ctx.XRefTable.Size+1
+	header := fmt.Sprintf("%d 0 ", specNr)
+	content := header + spec.PDFString()
+
+	out := bytes.NewBuffer(append([]byte{}, pdf...))
+	out.WriteString("\n")
+	objStmOffset := out.Len()
+	fmt.Fprintf(out, "%d 0 obj\n<</Type/ObjStm/N 1/First %d/Length %d>>\nstream\n%s\nendstream\nendobj\n",
+		objStmNr, len(header), len(content), content)
+
+	xrefOffset := out.Len()
+	var entries []byte
+	for _, e := range [][3]int{{2, objStmNr, 0}, {1, objStmOffset, 0}, {1, xrefOffset, 0}} {
+		entries = append(entries, byte(e[0]), byte(e[1]>>24), byte(e[1]>>16), byte(e[1]>>8), byte(e[1]), byte(e[2]>>8), byte(e[2]))
+	}
+	fmt.Fprintf(out, "%d 0 obj\n<</Type/XRef/Size %d/Root %s/Prev %s/W[1 4 2]/Index[%d 1 %d 2]/Length %d>>\nstream\n",
+		xrefNr, xrefNr+1, ctx.XRefTable.Root.PDFString(), prev[1], specNr, objStmNr, len(entries))
+	out.Write(entries)
+	fmt.Fprintf(out, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	return out.Bytes()
+}
+
+func TestExtract_SignedFileSpecInObjectStream(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	compressed := compressFileSpec(t, attachedTestPDF(t))
+	signed, err := Sign(bytes.NewReader(compressed), SignConfig{Signer: key, Certificates: []*x509.Certificate{newTestCertificate(t, key)}})
+	require.NoError(t, err)
+
+	_, infos, err := Extract(bytes.NewReader(signed))
+	require.NoError(t, err)
+	require.Len(t, infos.Signatures, 1)
+	assert.True(t, infos.Signatures[0].Valid, infos.Signatures[0].Error)
+	assert.True(t, infos.Signatures[0].CoversAttachment)
+	assert.True(t, infos.AttachmentSigned())
+}
+
+func TestExtract_UnreadableFormFields(t *testing.T) {
+	ctx, err := api.ReadContext(bytes.NewReader(attachedTestPDF(t)), model.NewDefaultConfiguration())
+	require.NoError(t, err)
+	catalog, err := ctx.Catalog()
+	require.NoError(t, err)
+	catalog["AcroForm"] = types.Dict{"Fields": types.Name("Broken")}
+
+	var broken bytes.Buffer
+	require.NoError(t, api.WriteContext(ctx, &broken))
+
+	xml, infos, err := Extract(bytes.NewReader(broken.Bytes()))
+	require.NoError(t, err)
+	assert.NotEmpty(t, xml)
+	assert.Empty(t, infos.Signatures)
+	require.NotEmpty(t, infos.Warnings)
+	assert.Contains(t, infos.Warnings[len(infos.Warnings)-1], "could not read signatures: could not get form fields")
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2025. Marlin Kuhn
+ */
+
+package gopdfattach
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgrade_ZUGFeRD(t *testing.T) {
+	pdf, err := os.ReadFile("testdata/invoice.pdf")
+	require.NoError(t, err)
+	xml, err := os.ReadFile("testdata/factur-x.xml")
+	require.NoError(t, err)
+
+	hybrid, err := AttachZUGFeRD(bytes.NewReader(xml), bytes.NewReader(pdf), &AttachConfig{
+		FileName:         "zugferd-invoice.xml",
+		ConformanceLevel: ConformanceBasic,
+	})
+	require.NoError(t, err)
+	hybrid = setAFRelationship(t, hybrid, AFData)
+
+	upgraded, err := Upgrade(bytes.NewReader(hybrid), nil)
+	require.NoError(t, err)
+
+	report, err := Inspect(bytes.NewReader(upgraded), nil)
+	require.NoError(t, err)
+	require.NotNil(t, report.Invoice)
+	assert.Equal(t, "fx", report.Invoice.Namespace)
+	assert.Equal(t, "1.0", report.Invoice.Version)
+	assert.Equal(t, "factur-x.xml", report.Invoice.DocumentFileName)
+	require.Len(t, report.Extensions, 1)
+	assert.Equal(t, report.Invoice.NamespaceURI, report.Extensions[0].NamespaceURI)
+	assert.Equal(t, []ProblemCode{ProblemNoOutputIntent}, problemCodes(report))
+
+	require.Len(t, report.EmbeddedFiles, 1)
+	assert.Equal(t, "factur-x.xml", report.EmbeddedFiles[0].FileName)
+	assert.Equal(t, "factur-x.xml", report.EmbeddedFiles[0].UnicodeName)
+	assert.Equal(t, "Alternative", report.EmbeddedFiles[0].AFRelationship)
+
+	extracted, infos, err := ExtractWithConfig(bytes.NewReader(upgraded), &ExtractConfig{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted)
+	assert.Equal(t, FileTypeFacturX, infos.FileType)
+	assert.Equal(t, string(ConformanceBasic), infos.ConformanceLevel)
+}
+
+func TestUpgrade_XRechnung(t *testing.T) {
+	original, err := os.ReadFile("testdata/XRECHNUNG/XRECHNUNG_Einfach.pdf")
+	require.NoError(t, err)
+
+	xml, before, err := Extract(bytes.NewReader(original))
+	require.NoError(t, err)
+
+	upgraded, err := Upgrade(bytes.NewReader(original), nil)
+	require.NoError(t, err)
+
+	extracted, after, err := ExtractWithConfig(bytes.NewReader(upgraded), &ExtractConfig{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, xml, extracted)
+	assert.Equal(t, before.FileName, after.FileName)
+	assert.Equal(t, before.Version, after.Version)
+	assert.Equal(t, before.ConformanceLevel, after.ConformanceLevel)
+}
+
+func TestUpgrade_PlainPDF(t *testing.T) {
+	file, err := os.Open("testdata/invoice.pdf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = Upgrade(file, nil)
+	assert.ErrorContains(t, err, "no fx or zf XMP metadata found")
+}